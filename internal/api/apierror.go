@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// FieldError is one validation message about a request field, e.g.
+// title: can't be blank.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) String() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// Message returns the error message the API sent, without the field
+// errors. Bodies that are not JSON, such as HTML error pages, are
+// replaced with the status text.
+func (e *APIError) Message() string {
+	message, _ := e.decode()
+	return message
+}
+
+// FieldErrors returns the validation errors in the body, sorted by field.
+// Fizzy answers 422 with Rails-style {"title": ["can't be blank"]}
+// objects, optionally nested under "errors".
+func (e *APIError) FieldErrors() []FieldError {
+	_, fields := e.decode()
+	return fields
+}
+
+func (e *APIError) decode() (string, []FieldError) {
+	body := strings.TrimSpace(string(e.Body))
+	if body == "" {
+		return "", nil
+	}
+	var doc any
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		if strings.HasPrefix(body, "<") {
+			return http.StatusText(e.Status), nil
+		}
+		return body, nil
+	}
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return "", messages("", doc)
+	}
+
+	var message string
+	for _, key := range []string{"error", "message"} {
+		if s, ok := obj[key].(string); ok {
+			message = s
+			delete(obj, key)
+			break
+		}
+	}
+	if nested, ok := obj["errors"]; ok {
+		delete(obj, "errors")
+		if m, ok := nested.(map[string]any); ok {
+			for k, v := range m {
+				obj[k] = v
+			}
+		} else {
+			obj[""] = nested
+		}
+	}
+
+	var fields []FieldError
+	for key, value := range obj {
+		fields = append(fields, messages(key, value)...)
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return message, fields
+}
+
+// messages flattens a field's value, a string or a list of strings, into
+// field errors.
+func messages(field string, value any) []FieldError {
+	switch v := value.(type) {
+	case string:
+		return []FieldError{{Field: field, Message: v}}
+	case []any:
+		var out []FieldError
+		for _, item := range v {
+			out = append(out, messages(field, item)...)
+		}
+		return out
+	case map[string]any:
+		var out []FieldError
+		for key, item := range v {
+			name := key
+			if field != "" {
+				name = field + "." + key
+			}
+			out = append(out, messages(name, item)...)
+		}
+		return out
+	case nil:
+		return nil
+	default:
+		return []FieldError{{Field: field, Message: fmt.Sprint(v)}}
+	}
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// A cassette is a directory of recorded HTTP exchanges, one JSON file per
+// request, numbered in the order they were made. Recorder writes them and
+// Replayer serves them back instead of the network.
+
+const redacted = "[REDACTED]"
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"headers,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	// Body64 holds bodies that aren't UTF-8 text, such as uploads.
+	Body64 string `json:"body_base64,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"headers,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	Body64 string      `json:"body_base64,omitempty"`
+}
+
+// secretHeaders are replaced with a placeholder when recording.
+var secretHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// secretFields matches JSON string fields holding session tokens.
+var secretFields = regexp.MustCompile(`("(?:session_token|pending_authentication_token)"\s*:\s*)"[^"]*"`)
+
+// Recorder is an http.RoundTripper that sends requests with Next (or
+// http.DefaultTransport) and saves each exchange to Dir, with credentials
+// scrubbed. Recording into a directory that already holds a cassette
+// appends to it.
+type Recorder struct {
+	Dir  string
+	Next http.RoundTripper
+
+	mu    sync.Mutex
+	count int
+}
+
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	existing, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{Dir: dir, Next: next, count: len(existing)}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	in := Interaction{
+		Request:  RecordedRequest{Method: req.Method, URL: req.URL.RequestURI(), Header: scrubHeader(req.Header)},
+		Response: RecordedResponse{Status: resp.StatusCode, Header: scrubHeader(resp.Header)},
+	}
+	in.Request.Body, in.Request.Body64 = encodeBody(reqBody)
+	in.Response.Body, in.Response.Body64 = encodeBody(respBody)
+	data, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count++
+	name := fmt.Sprintf("%04d-%s-%s.json", r.count, strings.ToLower(req.Method), slug(req.URL.Path))
+	if err := os.WriteFile(filepath.Join(r.Dir, name), append(data, '\n'), 0o600); err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+	return resp, nil
+}
+
+// ReplayMissError reports a request that has no recorded interaction left
+// to answer it.
+type ReplayMissError struct {
+	Dir    string
+	Method string
+	URL    string
+}
+
+func (e *ReplayMissError) Error() string {
+	return fmt.Sprintf("replay: no recorded response for %s %s in %s", e.Method, e.URL, e.Dir)
+}
+
+// Replayer is an http.RoundTripper that answers requests from a cassette
+// without touching the network. Each request gets the first unused
+// interaction with the same method, path and query, and for JSON bodies
+// the same body. Requests with no match fail with a *ReplayMissError.
+type Replayer struct {
+	Dir string
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("replay: no recorded interactions in %s", dir)
+	}
+	r := &Replayer{Dir: dir}
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var in Interaction
+		if err := json.Unmarshal(data, &in); err != nil {
+			return nil, fmt.Errorf("replay: %s: %w", name, err)
+		}
+		r.interactions = append(r.interactions, in)
+	}
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	url := req.URL.RequestURI()
+	jsonBody := strings.HasPrefix(req.Header.Get("Content-Type"), "application/json")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || in.Request.Method != req.Method || in.Request.URL != url {
+			continue
+		}
+		if jsonBody && !sameJSON(body, []byte(in.Request.Body)) {
+			continue
+		}
+		r.used[i] = true
+		respBody, err := decodeBody(in.Response.Body, in.Response.Body64)
+		if err != nil {
+			return nil, err
+		}
+		header := in.Response.Header
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(respBody)),
+			ContentLength: int64(len(respBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, &ReplayMissError{Dir: r.Dir, Method: req.Method, URL: url}
+}
+
+func cassetteFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// readBody reads *body and replaces it with a fresh reader over the same
+// bytes.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+func scrubHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range secretHeaders {
+		if len(out.Values(name)) > 0 {
+			out.Set(name, redacted)
+		}
+	}
+	return out
+}
+
+func encodeBody(data []byte) (string, string) {
+	if len(data) == 0 {
+		return "", ""
+	}
+	if utf8.Valid(data) {
+		return secretFields.ReplaceAllString(string(data), `$1"`+redacted+`"`), ""
+	}
+	return "", base64.StdEncoding.EncodeToString(data)
+}
+
+func decodeBody(text, b64 string) ([]byte, error) {
+	if b64 != "" {
+		return base64.StdEncoding.DecodeString(b64)
+	}
+	return []byte(text), nil
+}
+
+func sameJSON(a, b []byte) bool {
+	var x, y any
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b))
+	}
+	xs, _ := json.Marshal(x)
+	ys, _ := json.Marshal(y)
+	return bytes.Equal(xs, ys)
+}
+
+var slugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(path string) string {
+	s := strings.Trim(slugChars.ReplaceAllString(strings.ToLower(path), "-"), "-")
+	if len(s) > 60 {
+		s = s[:60]
+	}
+	if s == "" {
+		s = "root"
+	}
+	return s
+}
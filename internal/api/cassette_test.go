@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/session":
+			http.SetCookie(w, &http.Cookie{Name: "session_token", Value: "cookie-secret"})
+			w.Write([]byte(`{"session_token": "body-secret", "pending_authentication_token": "pending-secret"}`))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G', 0xff, 0x00})
+		default:
+			w.Header().Set("Location", "/cards/7")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"number": 7}`))
+		}
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	rec, err := NewRecorder(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := testClient(srv.URL)
+	client.Token = "token-secret"
+	client.HTTP.Transport = rec
+	ctx := context.Background()
+	requests := func(c *Client, cardBody string) []*Response {
+		t.Helper()
+		var out []*Response
+		for _, r := range []struct {
+			method, path, body string
+			headers            map[string]string
+		}{
+			{http.MethodPost, "/session", `{"email": "jane@example.com"}`, map[string]string{"Cookie": "session_token=header-secret"}},
+			{http.MethodGet, "/image", "", nil},
+			{http.MethodPost, "/cards", cardBody, nil},
+		} {
+			var body io.Reader
+			contentType := ""
+			if r.body != "" {
+				body, contentType = strings.NewReader(r.body), "application/json"
+			}
+			resp, err := c.Do(ctx, r.method, r.path, nil, body, contentType, r.headers)
+			if err != nil {
+				t.Fatalf("%s %s: %v", r.method, r.path, err)
+			}
+			out = append(out, resp)
+		}
+		return out
+	}
+	recorded := requests(client, `{"title": "A", "status": "drafted"}`)
+
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(files, " "); got != "0001-post-session.json 0002-get-image.json 0003-post-cards.json" {
+		t.Errorf("cassette files = %s", got)
+	}
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "secret") {
+			t.Errorf("%s holds a credential:\n%s", name, data)
+		}
+	}
+	session, _ := os.ReadFile(filepath.Join(dir, files[0]))
+	for _, want := range []string{`"Authorization": [`, `"Cookie": [`, `"Set-Cookie": [`, `\"session_token\": \"[REDACTED]\"`} {
+		if !strings.Contains(string(session), want) {
+			t.Errorf("%s does not contain %s:\n%s", files[0], want, session)
+		}
+	}
+
+	// Replay with no server, and the JSON body's keys in another order.
+	srv.Close()
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.HTTP.Transport = replayer
+	replayed := requests(client, `{"status": "drafted", "title": "A"}`)
+	// The session token in the body was scrubbed.
+	recorded[0].Body = []byte(`{"session_token": "[REDACTED]", "pending_authentication_token": "[REDACTED]"}`)
+	for i := range recorded {
+		if replayed[i].Status != recorded[i].Status || string(replayed[i].Body) != string(recorded[i].Body) {
+			t.Errorf("request %d: replayed %d %q, recorded %d %q", i+1, replayed[i].Status, replayed[i].Body, recorded[i].Status, recorded[i].Body)
+		}
+	}
+	if got := replayed[2].Headers.Get("Location"); got != "/cards/7" {
+		t.Errorf("replayed Location = %q", got)
+	}
+
+	// Every interaction is used up now.
+	_, err = client.Do(ctx, http.MethodGet, "/image", nil, nil, "", nil)
+	var miss *ReplayMissError
+	if !errors.As(err, &miss) {
+		t.Fatalf("got %v, want a *ReplayMissError", err)
+	}
+	if want := "replay: no recorded response for GET /image in " + dir; !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q does not contain %q", err, want)
+	}
+}
+
+func TestReplayMissesOnDifferentBody(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"request": {"method": "POST", "url": "/cards", "body": "{\"title\": \"A\"}"}, "response": {"status": 201}}`
+	if err := os.WriteFile(filepath.Join(dir, "0001-post-cards.json"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := testClient("http://fizzy.invalid")
+	client.HTTP.Transport = replayer
+	_, err = client.Do(context.Background(), http.MethodPost, "/cards", nil, strings.NewReader(`{"title": "B"}`), "application/json", nil)
+	var miss *ReplayMissError
+	if !errors.As(err, &miss) || miss.Method != http.MethodPost || miss.URL != "/cards" {
+		t.Errorf("got %v, want a replay miss for POST /cards", err)
+	}
+	if _, err := NewReplayer(t.TempDir()); err == nil || !strings.Contains(err.Error(), "no recorded interactions") {
+		t.Errorf("empty cassette: got %v", err)
+	}
+}
@@ -3,20 +3,41 @@ package api
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
 type Client struct {
-	BaseURL      string
+	BaseURL string
+	// Token and SessionToken must not be assigned once requests are in
+	// flight; SetSessionToken replaces the session safely.
 	Token        string
 	SessionToken string
 	Agent        string
 	HTTP         *http.Client
+	Retry        RetryPolicy
+
+	// Debug, when set, receives a trace of every request and response.
+	Debug io.Writer
+	// Curl, when set, receives each request as an equivalent curl command.
+	Curl io.Writer
+	// DryRun sends only requests that read data: GET, HEAD and OPTIONS
+	// still reach the server. Others fail with a *DryRunError instead.
+	DryRun bool
+	// ShowSecrets leaves credentials unmasked in traces and curl commands.
+	ShowSecrets bool
+	// Unauthorized, when set, is called when a request fails with 401. It
+	// can renew the client's credentials and return true to send the
+	// request once more, or return the error to report instead.
+	Unauthorized func(ctx context.Context, err *APIError) (bool, error)
+
+	credMu sync.RWMutex
 }
 
 type Response struct {
@@ -26,16 +47,24 @@ type Response struct {
 }
 
 type APIError struct {
-	Status int
-	Body   []byte
+	Status  int
+	Headers http.Header
+	Body    []byte
 }
 
 func (e *APIError) Error() string {
-	msg := strings.TrimSpace(string(e.Body))
-	if msg == "" {
+	message, fields := e.decode()
+	var parts []string
+	if message != "" {
+		parts = append(parts, message)
+	}
+	for _, f := range fields {
+		parts = append(parts, f.String())
+	}
+	if len(parts) == 0 {
 		return fmt.Sprintf("api error: status %d", e.Status)
 	}
-	return fmt.Sprintf("api error: status %d: %s", e.Status, msg)
+	return fmt.Sprintf("api error: status %d: %s", e.Status, strings.Join(parts, "; "))
 }
 
 func NewClient(baseURL, token, sessionToken, agent string) *Client {
@@ -45,51 +74,133 @@ func NewClient(baseURL, token, sessionToken, agent string) *Client {
 		SessionToken: sessionToken,
 		Agent:        agent,
 		HTTP:         &http.Client{Timeout: 30 * time.Second},
+		Retry:        DefaultRetryPolicy(),
 	}
 }
 
+// Credentials returns the token and session token requests are sent with.
+func (c *Client) Credentials() (token, sessionToken string) {
+	c.credMu.RLock()
+	defer c.credMu.RUnlock()
+	return c.Token, c.SessionToken
+}
+
+// SetSessionToken replaces the session token, for example after a new
+// login, while other requests may be in flight.
+func (c *Client) SetSessionToken(token string) {
+	c.credMu.Lock()
+	defer c.credMu.Unlock()
+	c.SessionToken = token
+}
+
 func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string, headers map[string]string) (*Response, error) {
 	urlStr, err := buildURL(c.BaseURL, path, query)
 	if err != nil {
 		return nil, err
 	}
 
-	var bodyReader io.Reader
+	var payload []byte
 	if body != nil {
 		buf := &bytes.Buffer{}
 		if _, err := io.Copy(buf, body); err != nil {
 			return nil, err
 		}
-		bodyReader = buf
+		payload = buf.Bytes()
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, urlStr, bodyReader)
-	if err != nil {
-		return nil, err
+	if c.Curl != nil || c.DryRun {
+		req, err := c.newRequest(ctx, method, urlStr, payload, body != nil, contentType, headers)
+		if err != nil {
+			return nil, err
+		}
+		if c.Curl != nil {
+			c.writeCurl(req, payload)
+		}
+		if c.DryRun && !safeMethod(method) {
+			return nil, &DryRunError{Method: method, URL: urlStr}
+		}
 	}
 
-	req.Header.Set("Accept", "application/json")
-	if c.Agent != "" {
-		req.Header.Set("User-Agent", c.Agent)
+	resp, err := c.sendWithRetry(ctx, method, urlStr, payload, body != nil, contentType, headers)
+	var apiErr *APIError
+	if c.Unauthorized != nil && errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized {
+		retry, hookErr := c.Unauthorized(ctx, apiErr)
+		if retry {
+			return c.sendWithRetry(ctx, method, urlStr, payload, body != nil, contentType, headers)
+		}
+		if hookErr != nil {
+			return nil, hookErr
+		}
 	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else if c.SessionToken != "" {
-		if headers == nil {
-			req.Header.Set("Cookie", "session_token="+c.SessionToken)
-		} else if _, ok := headers["Cookie"]; !ok {
-			req.Header.Set("Cookie", "session_token="+c.SessionToken)
+	return resp, err
+}
+
+func (c *Client) sendWithRetry(ctx context.Context, method, urlStr string, payload []byte, hasBody bool, contentType string, headers map[string]string) (*Response, error) {
+	policy := c.Retry
+	attempts := policy.attempts()
+	if !isIdempotent(ctx, method) {
+		attempts = 1
+	}
+	var deadline time.Time
+	if policy.Timeout > 0 {
+		deadline = time.Now().Add(policy.Timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, method, urlStr, payload, hasBody, contentType, headers)
+		if attempt >= attempts {
+			return resp, err
+		}
+
+		var delay time.Duration
+		var apiErr *APIError
+		switch {
+		case errors.As(err, &apiErr):
+			if !retryableStatus(apiErr.Status) {
+				return nil, err
+			}
+			delay = policy.backoff(attempt)
+			if apiErr.Status == http.StatusTooManyRequests || apiErr.Status == http.StatusServiceUnavailable {
+				if after, ok := retryAfter(apiErr.Headers, time.Now()); ok {
+					delay = after
+				}
+			}
+		case err != nil:
+			if !retryableError(ctx, err) {
+				return nil, err
+			}
+			delay = policy.backoff(attempt)
+		default:
+			return resp, nil
+		}
+
+		if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+			return nil, err
+		}
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return nil, err
 		}
 	}
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
+}
+
+func (c *Client) send(ctx context.Context, method, urlStr string, payload []byte, hasBody bool, contentType string, headers map[string]string) (*Response, error) {
+	req, err := c.newRequest(ctx, method, urlStr, payload, hasBody, contentType, headers)
+	if err != nil {
+		return nil, err
 	}
-	for k, v := range headers {
-		req.Header.Set(k, v)
+	if c.Debug != nil {
+		c.traceRequest(req, payload)
 	}
+	start := time.Now()
 
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
+		if c.Debug != nil {
+			c.traceError(err, time.Since(start))
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
@@ -98,9 +209,12 @@ func (c *Client) Do(ctx context.Context, method, path string, query url.Values,
 	if err != nil {
 		return nil, err
 	}
+	if c.Debug != nil {
+		c.traceResponse(resp, respBody, time.Since(start))
+	}
 
 	if resp.StatusCode >= 400 {
-		return nil, &APIError{Status: resp.StatusCode, Body: respBody}
+		return nil, &APIError{Status: resp.StatusCode, Headers: resp.Header, Body: respBody}
 	}
 
 	return &Response{
@@ -110,6 +224,40 @@ func (c *Client) Do(ctx context.Context, method, path string, query url.Values,
 	}, nil
 }
 
+func (c *Client) newRequest(ctx context.Context, method, urlStr string, payload []byte, hasBody bool, contentType string, headers map[string]string) (*http.Request, error) {
+	var bodyReader io.Reader
+	if hasBody {
+		bodyReader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if c.Agent != "" {
+		req.Header.Set("User-Agent", c.Agent)
+	}
+	token, sessionToken := c.Credentials()
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if sessionToken != "" {
+		if headers == nil {
+			req.Header.Set("Cookie", "session_token="+sessionToken)
+		} else if _, ok := headers["Cookie"]; !ok {
+			req.Header.Set("Cookie", "session_token="+sessionToken)
+		}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
 func buildURL(baseURL, path string, query url.Values) (string, error) {
 	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
 		u, err := url.Parse(path)
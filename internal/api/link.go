@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// NextLink returns the URL of the rel="next" entry of a Link header.
+func NextLink(headers http.Header) string {
+	linkHeader := ""
+	for k, v := range headers {
+		if strings.EqualFold(k, "Link") && len(v) > 0 {
+			linkHeader = v[0]
+			break
+		}
+	}
+	if linkHeader == "" {
+		return ""
+	}
+	parts := strings.Split(linkHeader, ",")
+	for _, part := range parts {
+		sections := strings.Split(strings.TrimSpace(part), ";")
+		if len(sections) < 2 {
+			continue
+		}
+		urlPart := strings.TrimSpace(sections[0])
+		relPart := strings.TrimSpace(sections[1])
+		if strings.Contains(relPart, "rel=\"next\"") {
+			return strings.Trim(urlPart, "<>")
+		}
+	}
+	return ""
+}
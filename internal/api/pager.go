@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"net/url"
+)
+
+// Pager walks a paginated collection by following rel="next" Link headers.
+type Pager struct {
+	client *Client
+	path   string
+	query  url.Values
+	done   bool
+}
+
+func (c *Client) Pager(path string, query url.Values) *Pager {
+	return &Pager{client: c, path: path, query: query}
+}
+
+// Next fetches the next page. It returns a nil Response once the last page
+// has been read.
+func (p *Pager) Next(ctx context.Context) (*Response, error) {
+	if p.done {
+		return nil, nil
+	}
+	resp, err := p.client.Do(ctx, "GET", p.path, p.query, nil, "", nil)
+	if err != nil {
+		p.done = true
+		return nil, err
+	}
+	next := NextLink(resp.Headers)
+	if next == "" {
+		p.done = true
+	} else {
+		p.path = next
+		p.query = nil
+	}
+	return resp, nil
+}
+
+func (p *Pager) Done() bool {
+	return p.done
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingServer answers every request with the given status until it has
+// failed fails times, then with 200.
+func failingServer(t *testing.T, status, fails int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(calls.Add(1)) <= fails {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func testClient(baseURL string) *Client {
+	c := NewClient(baseURL, "token", "", "test")
+	c.Retry = RetryPolicy{MaxAttempts: 3, Timeout: 5 * time.Second, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	return c
+}
+
+func statusOf(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status
+	}
+	return 0
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	srv, calls := failingServer(t, http.StatusBadGateway, 2, nil)
+	resp, err := testClient(srv.URL).Do(context.Background(), http.MethodGet, "/boards", nil, nil, "", nil)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.Status != http.StatusOK || calls.Load() != 3 {
+		t.Errorf("status %d after %d calls, want 200 after 3", resp.Status, calls.Load())
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	srv, calls := failingServer(t, http.StatusInternalServerError, 10, nil)
+	_, err := testClient(srv.URL).Do(context.Background(), http.MethodGet, "/boards", nil, nil, "", nil)
+	if statusOf(err) != http.StatusInternalServerError || calls.Load() != 3 {
+		t.Errorf("got %v after %d calls, want a 500 after 3", err, calls.Load())
+	}
+}
+
+func TestNoRetryOnClientErrors(t *testing.T) {
+	srv, calls := failingServer(t, http.StatusNotFound, 10, nil)
+	_, err := testClient(srv.URL).Do(context.Background(), http.MethodGet, "/boards", nil, nil, "", nil)
+	if statusOf(err) != http.StatusNotFound || calls.Load() != 1 {
+		t.Errorf("got %v after %d calls, want a 404 after 1", err, calls.Load())
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		srv, calls := failingServer(t, status, 1, http.Header{"Retry-After": {"1"}})
+		start := time.Now()
+		_, err := testClient(srv.URL).Do(context.Background(), http.MethodGet, "/boards", nil, nil, "", nil)
+		if err != nil {
+			t.Fatalf("%d: Do: %v", status, err)
+		}
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("%d: retried after %v, want Retry-After's 1s", status, elapsed)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("%d: %d calls, want 2", status, calls.Load())
+		}
+	}
+}
+
+func TestRetryAfterDate(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"3", 3 * time.Second, true},
+		{"-1", 0, true},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(http.Header{"Retry-After": {tt.value}}, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryAfterDateHonored(t *testing.T) {
+	// HTTP dates have whole seconds, so the wait is between 1s and 2s.
+	at := time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat)
+	srv, calls := failingServer(t, http.StatusTooManyRequests, 1, http.Header{"Retry-After": {at}})
+	start := time.Now()
+	if _, err := testClient(srv.URL).Do(context.Background(), http.MethodGet, "/boards", nil, nil, "", nil); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second-50*time.Millisecond {
+		t.Errorf("retried after %v, want to wait until %s", elapsed, at)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("%d calls, want 2", calls.Load())
+	}
+}
+
+func TestNoRetryForPost(t *testing.T) {
+	srv, calls := failingServer(t, http.StatusServiceUnavailable, 1, nil)
+	c := testClient(srv.URL)
+	_, err := c.Do(context.Background(), http.MethodPost, "/cards", nil, nil, "", nil)
+	if statusOf(err) != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("POST: got %v after %d calls, want a 503 after 1", err, calls.Load())
+	}
+
+	calls.Store(0)
+	if _, err := c.Do(WithIdempotent(context.Background()), http.MethodPost, "/cards/1/closure", nil, nil, "", nil); err != nil {
+		t.Fatalf("idempotent POST: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("idempotent POST: %d calls, want 2", calls.Load())
+	}
+}
+
+func TestRetryStopsAtContextDeadline(t *testing.T) {
+	srv, calls := failingServer(t, http.StatusTooManyRequests, 10, http.Header{"Retry-After": {"30"}})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := testClient(srv.URL).Do(ctx, http.MethodGet, "/boards", nil, nil, "", nil)
+	if statusOf(err) != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Errorf("got %v after %d calls, want a 429 after 1", err, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gave up after %v, want at once", elapsed)
+	}
+}
+
+func TestRetryStopsAtPolicyTimeout(t *testing.T) {
+	srv, calls := failingServer(t, http.StatusServiceUnavailable, 10, http.Header{"Retry-After": {"30"}})
+	c := testClient(srv.URL)
+	c.Retry.Timeout = time.Second
+	start := time.Now()
+	_, err := c.Do(context.Background(), http.MethodGet, "/boards", nil, nil, "", nil)
+	if statusOf(err) != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("got %v after %d calls, want a 503 after 1", err, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("gave up after %v, want at once", elapsed)
+	}
+}
+
+func TestBackoffBounds(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for retry, limit := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		for i := 0; i < 100; i++ {
+			if d := p.backoff(retry); d <= 0 || d > limit {
+				t.Fatalf("backoff(%d) = %v, want within (0, %v]", retry, d, limit)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DryRunError is returned by Client.Do in dry-run mode for a request that
+// could change data and so was not sent. Requests before it that only read
+// data were sent.
+type DryRunError struct {
+	Method string
+	URL    string
+}
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("dry run: %s %s not sent", e.Method, e.URL)
+}
+
+// safeMethod reports whether a request only reads data; dry runs still send
+// those so that names can be resolved.
+func safeMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+var cookieValue = regexp.MustCompile(`=[^;]*`)
+
+// maskHeader hides credentials but keeps their shape, e.g.
+// "Bearer [REDACTED]", so a trace still shows how a request authenticated.
+func (c *Client) maskHeader(h http.Header) http.Header {
+	out := h.Clone()
+	if c.ShowSecrets {
+		return out
+	}
+	for _, name := range secretHeaders {
+		values := out.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		out.Del(name)
+		for _, v := range values {
+			switch {
+			case name == "Authorization" && strings.Contains(v, " "):
+				v = v[:strings.Index(v, " ")+1] + redacted
+			case name == "Authorization":
+				v = redacted
+			case name == "Set-Cookie":
+				pair, attrs, found := strings.Cut(v, ";")
+				v = cookieValue.ReplaceAllString(pair, "="+redacted)
+				if found {
+					v += ";" + attrs
+				}
+			default:
+				v = cookieValue.ReplaceAllString(v, "="+redacted)
+			}
+			out.Add(name, v)
+		}
+	}
+	return out
+}
+
+func (c *Client) maskBody(body []byte) string {
+	if !utf8.Valid(body) {
+		return fmt.Sprintf("[%d bytes of binary data]", len(body))
+	}
+	if c.ShowSecrets {
+		return string(body)
+	}
+	return secretFields.ReplaceAllString(string(body), `$1"`+redacted+`"`)
+}
+
+func writeHeader(w io.Writer, prefix string, h http.Header) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range h[name] {
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, name, v)
+		}
+	}
+}
+
+func (c *Client) traceRequest(req *http.Request, payload []byte) {
+	fmt.Fprintf(c.Debug, "> %s %s\n", req.Method, req.URL)
+	writeHeader(c.Debug, "> ", c.maskHeader(req.Header))
+	if len(payload) > 0 {
+		fmt.Fprintf(c.Debug, ">\n%s\n", strings.TrimRight(c.maskBody(payload), "\n"))
+	}
+}
+
+func (c *Client) traceResponse(resp *http.Response, body []byte, elapsed time.Duration) {
+	fmt.Fprintf(c.Debug, "< %s (%s)\n", resp.Status, elapsed.Round(time.Millisecond))
+	writeHeader(c.Debug, "< ", c.maskHeader(resp.Header))
+	if len(body) > 0 {
+		fmt.Fprintf(c.Debug, "<\n%s\n", strings.TrimRight(c.maskBody(body), "\n"))
+	}
+	fmt.Fprintln(c.Debug)
+}
+
+func (c *Client) traceError(err error, elapsed time.Duration) {
+	fmt.Fprintf(c.Debug, "< error after %s: %v\n\n", elapsed.Round(time.Millisecond), err)
+}
+
+// writeCurl prints req as a curl command that sends the same request.
+func (c *Client) writeCurl(req *http.Request, payload []byte) {
+	var b strings.Builder
+	b.WriteString("curl")
+	if req.Method != http.MethodGet {
+		b.WriteString(" -X " + req.Method)
+	}
+	b.WriteString(" " + shellQuote(req.URL.String()))
+	header := c.maskHeader(req.Header)
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range header[name] {
+			b.WriteString(" \\\n  -H " + shellQuote(name+": "+v))
+		}
+	}
+	if len(payload) > 0 {
+		if utf8.Valid(payload) {
+			b.WriteString(" \\\n  --data-binary " + shellQuote(c.maskBody(payload)))
+		} else {
+			fmt.Fprintf(&b, " \\\n  --data-binary @body  # %d-byte binary body not shown", len(payload))
+		}
+	}
+	fmt.Fprintln(c.Curl, b.String())
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
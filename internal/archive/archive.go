@@ -0,0 +1,220 @@
+// Package archive reads and writes board exports: one JSON document with a
+// board, its columns and its cards with their comments, either on its own
+// or in a zip file together with the card images.
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// Format identifies an export document.
+	Format = "fizzy-board-export"
+	// Version is bumped whenever a change could break readers.
+	Version = 1
+	// DocumentName is the document's name inside a zip archive.
+	DocumentName = "board.json"
+	// ImageDir is the zip directory that holds card images.
+	ImageDir = "images"
+)
+
+// Card states, from the card list each card was found in.
+const (
+	StateOpen   = "open"
+	StateNotNow = "not_now"
+	StateClosed = "closed"
+)
+
+// Archive is the export document. The board, columns, cards and comments
+// are kept as the API returned them.
+type Archive struct {
+	Format     string            `json:"format"`
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Source     Source            `json:"source"`
+	Board      json.RawMessage   `json:"board"`
+	Columns    []json.RawMessage `json:"columns"`
+	Cards      []Card            `json:"cards"`
+
+	// Images holds image bytes by their path in the zip archive.
+	Images map[string][]byte `json:"-"`
+}
+
+// Source records where the board was exported from.
+type Source struct {
+	BaseURL string `json:"base_url"`
+	Account string `json:"account"`
+}
+
+type Card struct {
+	State    string            `json:"state"`
+	Card     json.RawMessage   `json:"card"`
+	Comments []json.RawMessage `json:"comments"`
+	Image    *Image            `json:"image,omitempty"`
+}
+
+// Image describes a card image. Path is empty when the image was not
+// downloaded, as in a plain JSON export.
+type Image struct {
+	Path        string `json:"path,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	URL         string `json:"url"`
+}
+
+func New(source Source, now time.Time) *Archive {
+	return &Archive{Format: Format, Version: Version, ExportedAt: now.UTC(), Source: source, Columns: []json.RawMessage{}, Cards: []Card{}, Images: map[string][]byte{}}
+}
+
+// ImagePath returns the zip path for a card image: the card number and the
+// file name, which is sanitized.
+func ImagePath(number int, name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, path.Base(name))
+	if strings.Trim(name, "._") == "" {
+		name = "image"
+	}
+	return fmt.Sprintf("%s/%d-%s", ImageDir, number, name)
+}
+
+// IsZip reports whether file should be written as a zip archive.
+func IsZip(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), ".zip")
+}
+
+// WriteJSON writes the document alone, without images.
+func (a *Archive) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteZip writes the document and the images as a zip archive.
+func (a *Archive) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	doc, err := zw.CreateHeader(&zip.FileHeader{Name: DocumentName, Method: zip.Deflate, Modified: a.ExportedAt})
+	if err != nil {
+		return err
+	}
+	if err := a.WriteJSON(doc); err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(a.Images))
+	for p := range a.Images {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		// Images are usually compressed already.
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: p, Method: zip.Store, Modified: a.ExportedAt})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(a.Images[p]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// WriteFile writes a zip archive or, unless file ends in .zip, the JSON
+// document. The file is replaced only once it is complete.
+func (a *Archive) WriteFile(file string) error {
+	buf := &bytes.Buffer{}
+	write := a.WriteJSON
+	if IsZip(file) {
+		write = a.WriteZip
+	}
+	if err := write(buf); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Read reads a zip archive or a JSON document, telling them apart by
+// content, and checks its format and version.
+func Read(file string) (*Archive, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	a := &Archive{Images: map[string][]byte{}}
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		if err := decode(data, a); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		return a, nil
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	found := false
+	for _, f := range zr.File {
+		content, err := readZipFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", file, f.Name, err)
+		}
+		switch {
+		case f.Name == DocumentName:
+			if err := decode(content, a); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", file, f.Name, err)
+			}
+			found = true
+		case strings.HasPrefix(f.Name, ImageDir+"/"):
+			a.Images[f.Name] = content
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: no %s in the archive", file, DocumentName)
+	}
+	return a, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func decode(data []byte, a *Archive) error {
+	if err := json.Unmarshal(data, a); err != nil {
+		return err
+	}
+	if a.Format != Format {
+		return errors.New("not a fizzy board export")
+	}
+	if a.Version < 1 || a.Version > Version {
+		return fmt.Errorf("export version %d is not supported (this version of fizzy-cli reads up to %d)", a.Version, Version)
+	}
+	return nil
+}
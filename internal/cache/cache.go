@@ -0,0 +1,107 @@
+// Package cache stores small JSON documents on disk with a time-to-live.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Store struct {
+	Dir string
+	TTL time.Duration
+	Now func() time.Time
+}
+
+type entry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Data     json.RawMessage `json:"data"`
+}
+
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "fizzy"), nil
+}
+
+func New(dir string, ttl time.Duration) *Store {
+	return &Store{Dir: dir, TTL: ttl, Now: time.Now}
+}
+
+// Key derives a file-safe cache key from its parts.
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Get decodes the entry for key into v. It reports false when the entry is
+// missing, expired or unreadable, or when the store is disabled.
+func (s *Store) Get(key string, v any) bool {
+	if s == nil || s.Dir == "" || s.TTL <= 0 {
+		return false
+	}
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return false
+	}
+	if s.now().Sub(e.StoredAt) > s.TTL {
+		return false
+	}
+	return json.Unmarshal(e.Data, v) == nil
+}
+
+func (s *Store) Put(key string, v any) error {
+	if s == nil || s.Dir == "" || s.TTL <= 0 {
+		return nil
+	}
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry{StoredAt: s.now(), Data: payload})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+	path := s.path(key)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Store) Delete(key string) error {
+	if s == nil || s.Dir == "" {
+		return nil
+	}
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *Store) path(key string) string {
+	return filepath.Join(s.Dir, key+".json")
+}
+
+func (s *Store) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
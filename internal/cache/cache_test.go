@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type doc struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// clockStore returns a store whose clock the test moves by hand.
+func clockStore(t *testing.T, ttl time.Duration) (*Store, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	s := New(filepath.Join(t.TempDir(), "cache"), ttl)
+	s.Now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestPutGet(t *testing.T) {
+	s, _ := clockStore(t, time.Hour)
+	key := Key("resolve", "board")
+	var got []doc
+	if s.Get(key, &got) {
+		t.Fatal("Get found an entry before Put")
+	}
+	want := []doc{{ID: "1", Name: "Roadmap"}, {ID: "2", Name: "Operations"}}
+	if err := s.Put(key, want); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Get(key, &got) || len(got) != 2 || got[1] != want[1] {
+		t.Fatalf("Get = %v, want %v", got, want)
+	}
+	info, err := os.Stat(s.Dir)
+	if err != nil || info.Mode().Perm() != 0o700 {
+		t.Errorf("cache dir mode = %v, %v; want 0700", info.Mode().Perm(), err)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	s, now := clockStore(t, time.Hour)
+	key := Key("resolve", "tag")
+	if err := s.Put(key, doc{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	var got doc
+	*now = now.Add(time.Hour)
+	if !s.Get(key, &got) {
+		t.Error("entry expired at exactly the TTL")
+	}
+	*now = now.Add(time.Second)
+	if s.Get(key, &got) {
+		t.Error("entry still returned after the TTL")
+	}
+	// Putting it again starts a new TTL.
+	if err := s.Put(key, doc{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Get(key, &got) || got.ID != "2" {
+		t.Errorf("Get after refresh = %v", got)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	for name, s := range map[string]*Store{
+		"nil":      nil,
+		"no dir":   New("", time.Hour),
+		"zero TTL": New(dir, 0),
+	} {
+		if err := s.Put("key", doc{ID: "1"}); err != nil {
+			t.Errorf("%s: Put: %v", name, err)
+		}
+		var got doc
+		if s.Get("key", &got) {
+			t.Errorf("%s: Get found an entry", name)
+		}
+		if err := s.Delete("key"); err != nil {
+			t.Errorf("%s: Delete: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("a disabled store created %s", dir)
+	}
+}
+
+func TestDeleteAndUnreadable(t *testing.T) {
+	s, _ := clockStore(t, time.Hour)
+	if err := s.Delete("missing"); err != nil {
+		t.Errorf("Delete of a missing entry: %v", err)
+	}
+	if err := s.Put("k", doc{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("k"); err != nil {
+		t.Fatal(err)
+	}
+	var got doc
+	if s.Get("k", &got) {
+		t.Error("Get found a deleted entry")
+	}
+	if err := os.WriteFile(s.path("bad"), []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if s.Get("bad", &got) {
+		t.Error("Get decoded a corrupt entry")
+	}
+	if err := s.Put("wrong", "a string"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Get("wrong", &got) {
+		t.Error("Get decoded an entry of the wrong type")
+	}
+}
+
+func TestKey(t *testing.T) {
+	a := Key("resolve", "https://app.fizzy.do", "123", "board", "")
+	if a != Key("resolve", "https://app.fizzy.do", "123", "board", "") {
+		t.Error("Key is not stable")
+	}
+	if len(a) != 32 {
+		t.Errorf("Key length = %d, want 32 hex characters", len(a))
+	}
+	// The separator keeps parts from running together.
+	if Key("ab", "c") == Key("a", "bc") {
+		t.Error("Key(ab, c) == Key(a, bc)")
+	}
+}
@@ -0,0 +1,810 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fizzy-cli/internal/journal"
+	"fizzy-cli/pkg/fizzy"
+)
+
+// batchOp is one operation of a batch script.
+type batchOp struct {
+	Line int
+	Name string
+	Ref  string
+	Args map[string]any
+}
+
+// fingerprint identifies an operation's content, so that a checkpoint can
+// tell whether an operation that already ran was edited since.
+func (op batchOp) fingerprint() string {
+	data, _ := json.Marshal(map[string]any{"op": op.Name, "ref": op.Ref, "args": op.Args})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+type batchSpec struct {
+	required []string
+	optional []string
+	// outputs are the fields that later operations can reference as
+	// ${ref.field}.
+	outputs []string
+	run     func(ctx Context, a batchArgs) (map[string]string, error)
+	// resume, when set, finishes an operation that failed part way, given
+	// the progress it reported in a *batchPartialError.
+	resume func(ctx Context, a batchArgs, progress map[string]string) (map[string]string, error)
+}
+
+// batchPartialError reports an operation that failed after it changed
+// something. The checkpoint keeps Progress so that the next run resumes
+// the operation instead of starting it over.
+type batchPartialError struct {
+	Progress map[string]string
+	Err      error
+}
+
+func (e *batchPartialError) Error() string { return e.Err.Error() }
+func (e *batchPartialError) Unwrap() error { return e.Err }
+
+var batchOps = map[string]batchSpec{
+	"board.create": {
+		required: []string{"name"},
+		optional: []string{"all_access", "public_description"},
+		outputs:  []string{"id", "location"},
+		run: func(ctx Context, a batchArgs) (map[string]string, error) {
+			allAccess := true
+			if a.has("all_access") {
+				var err error
+				if allAccess, err = a.boolean("all_access"); err != nil {
+					return nil, err
+				}
+			}
+			req := &fizzy.BoardCreateRequest{Name: a.str("name"), AllAccess: allAccess, PublicDescription: a.str("public_description")}
+			resp, err := ctx.Client.Boards.Create(requestContext(), req)
+			if err != nil {
+				return nil, err
+			}
+			return locationOutputs(resp, "id"), nil
+		},
+	},
+	"column.create": {
+		required: []string{"board", "name"},
+		optional: []string{"color"},
+		outputs:  []string{"id", "location"},
+		run: func(ctx Context, a batchArgs) (map[string]string, error) {
+			board, err := resolveBoard(ctx, a.str("board"))
+			if err != nil {
+				return nil, err
+			}
+			resp, err := ctx.Client.Columns.Create(requestContext(), board, &fizzy.ColumnRequest{Name: a.str("name"), Color: a.str("color")})
+			if err != nil {
+				return nil, err
+			}
+			return locationOutputs(resp, "id"), nil
+		},
+	},
+	"card.create": {
+		required: []string{"board", "title"},
+		optional: []string{"description", "status", "tags", "steps"},
+		outputs:  []string{"number", "location"},
+		run: func(ctx Context, a batchArgs) (map[string]string, error) {
+			board, err := resolveBoard(ctx, a.str("board"))
+			if err != nil {
+				return nil, err
+			}
+			tags, err := resolveEach(ctx, a.list("tags"), resolveTag)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := ctx.Client.Cards.Create(requestContext(), board, cardRequest(a.str("title"), a.str("description"), a.str("status"), "", tags))
+			if err != nil {
+				return nil, err
+			}
+			out := locationOutputs(resp, "number")
+			if len(a.list("steps")) == 0 {
+				return out, nil
+			}
+			if _, ok := cardNumberFromLocation(out["location"]); !ok {
+				return out, fmt.Errorf("card created, but its number is unknown so steps were not added (Location: %q)", out["location"])
+			}
+			out["steps_added"] = "0"
+			return batchCardSteps(ctx, a, out)
+		},
+		resume: batchCardSteps,
+	},
+	"card.update": {
+		required: []string{"card"},
+		optional: []string{"title", "description", "status", "tags"},
+		run: func(ctx Context, a batchArgs) (map[string]string, error) {
+			tags, err := resolveEach(ctx, a.list("tags"), resolveTag)
+			if err != nil {
+				return nil, err
+			}
+			req := cardRequest(a.str("title"), a.str("description"), a.str("status"), "", tags)
+			if a.has("description") && req.Description == nil {
+				// An empty description clears it.
+				req.Description = new(string)
+			}
+			if req.Title == "" && req.Description == nil && req.Status == "" && len(req.TagIDs) == 0 {
+				return nil, errors.New("no fields to update")
+			}
+			return nil, batchCardChange(ctx, a, journal.Entry{Action: "card update", Fields: updatedFields(req)}, func(number int) error {
+				_, err := ctx.Client.Cards.Update(requestContext(), number, req)
+				return err
+			})
+		},
+	},
+	"card.close":    batchCardAction("card close", (*fizzy.CardsService).Close),
+	"card.reopen":   batchCardAction("card reopen", (*fizzy.CardsService).Reopen),
+	"card.not-now":  batchCardAction("", (*fizzy.CardsService).NotNow),
+	"card.untriage": batchCardAction("", (*fizzy.CardsService).Untriage),
+	"card.watch":    batchCardAction("", (*fizzy.CardsService).Watch),
+	"card.triage": {
+		required: []string{"card", "column"},
+		run: func(ctx Context, a batchArgs) (map[string]string, error) {
+			number, err := a.card()
+			if err != nil {
+				return nil, err
+			}
+			column, err := resolveCardColumn(ctx, number, a.str("column"))
+			if err != nil {
+				return nil, err
+			}
+			_, err = ctx.Client.Cards.Triage(requestContext(), number, column)
+			return nil, err
+		},
+	},
+	"card.tag": {
+		required: []string{"card", "tag"},
+		run: func(ctx Context, a batchArgs) (map[string]string, error) {
+			title := strings.TrimPrefix(a.str("tag"), "#")
+			return nil, batchCardChange(ctx, a, journal.Entry{Action: "card tag", Target: title}, func(number int) error {
+				_, err := ctx.Client.Cards.ToggleTag(requestContext(), number, title)
+				return err
+			})
+		},
+	},
+	"card.assign": {
+		required: []string{"card", "user"},
+		run: func(ctx Context, a batchArgs) (map[string]string, error) {
+			user, err := resolveUser(ctx, a.str("user"))
+			if err != nil {
+				return nil, err
+			}
+			return nil, batchCardChange(ctx, a, journal.Entry{Action: "card assign", Target: user}, func(number int) error {
+				_, err := ctx.Client.Cards.ToggleAssignment(requestContext(), number, user)
+				return err
+			})
+		},
+	},
+	"comment.create": {
+		required: []string{"card", "body"},
+		outputs:  []string{"id", "location"},
+		run: func(ctx Context, a batchArgs) (map[string]string, error) {
+			number, err := a.card()
+			if err != nil {
+				return nil, err
+			}
+			resp, err := ctx.Client.Comments.Create(requestContext(), number, &fizzy.CommentRequest{Body: a.str("body")})
+			if err != nil {
+				return nil, err
+			}
+			return locationOutputs(resp, "id"), nil
+		},
+	},
+	"step.create": {
+		required: []string{"card", "content"},
+		optional: []string{"completed"},
+		outputs:  []string{"id", "location"},
+		run: func(ctx Context, a batchArgs) (map[string]string, error) {
+			number, err := a.card()
+			if err != nil {
+				return nil, err
+			}
+			req := &fizzy.StepRequest{Content: a.str("content")}
+			if a.has("completed") {
+				done, err := a.boolean("completed")
+				if err != nil {
+					return nil, err
+				}
+				req.Completed = completedState(done)
+			}
+			resp, err := ctx.Client.Steps.Create(requestContext(), number, req)
+			if err != nil {
+				return nil, err
+			}
+			return locationOutputs(resp, "id"), nil
+		},
+	},
+}
+
+// batchCardSteps adds a created card's steps, starting after the
+// progress["steps_added"] that were added before. Once they all are, the
+// outputs are those of the card.
+func batchCardSteps(ctx Context, a batchArgs, progress map[string]string) (map[string]string, error) {
+	number, ok := cardNumberFromLocation(progress["location"])
+	if !ok {
+		return nil, fmt.Errorf("card number unknown (Location: %q)", progress["location"])
+	}
+	added, _ := strconv.Atoi(progress["steps_added"])
+	steps := a.list("steps")
+	for i := added; i < len(steps); i++ {
+		if _, err := ctx.Client.Steps.Create(requestContext(), number, &fizzy.StepRequest{Content: steps[i]}); err != nil {
+			partial := map[string]string{"number": progress["number"], "location": progress["location"], "steps_added": strconv.Itoa(i)}
+			return partial, &batchPartialError{Progress: partial, Err: fmt.Errorf("card %d created, but adding step %d of %d (%q) failed: %w", number, i+1, len(steps), steps[i], err)}
+		}
+	}
+	return map[string]string{"number": progress["number"], "location": progress["location"]}, nil
+}
+
+// batchCardAction is the spec of an operation that only takes a card. A
+// journal action name records it for undo, as the matching command does.
+func batchCardAction(action string, fn func(*fizzy.CardsService, context.Context, int) (*fizzy.Response, error)) batchSpec {
+	return batchSpec{
+		required: []string{"card"},
+		run: func(ctx Context, a batchArgs) (map[string]string, error) {
+			act := func(number int) error {
+				_, err := fn(ctx.Client.Cards, requestContext(), number)
+				return err
+			}
+			if action == "" {
+				number, err := a.card()
+				if err != nil {
+					return nil, err
+				}
+				return nil, act(number)
+			}
+			return nil, batchCardChange(ctx, a, journal.Entry{Action: action}, act)
+		},
+	}
+}
+
+// batchCardChange snapshots the card, runs act and journals the change.
+func batchCardChange(ctx Context, a batchArgs, entry journal.Entry, act func(int) error) error {
+	number, err := a.card()
+	if err != nil {
+		return err
+	}
+	before, err := snapshotCard(ctx, number)
+	if err != nil {
+		return err
+	}
+	if err := act(number); err != nil {
+		return err
+	}
+	entry.Card, entry.Before = number, before
+	journalChange(ctx, entry)
+	return nil
+}
+
+// locationOutputs returns the location of a created resource and its ID or
+// number, the last segment of the location.
+func locationOutputs(resp *fizzy.Response, key string) map[string]string {
+	location := resp.Headers.Get("Location")
+	out := map[string]string{"location": location}
+	if location != "" {
+		out[key] = strings.TrimSuffix(path.Base(strings.TrimRight(location, "/")), ".json")
+	}
+	return out
+}
+
+// batchArgs are an operation's arguments after references are filled in.
+type batchArgs map[string]any
+
+func (a batchArgs) has(key string) bool {
+	_, ok := a[key]
+	return ok
+}
+
+func (a batchArgs) str(key string) string {
+	return scalarString(a[key])
+}
+
+// list accepts a list or a single value.
+func (a batchArgs) list(key string) []string {
+	switch v := a[key].(type) {
+	case nil:
+		return nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s := strings.TrimSpace(scalarString(item)); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		if s := strings.TrimSpace(scalarString(v)); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+}
+
+func (a batchArgs) boolean(key string) (bool, error) {
+	switch v := a[key].(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("%s must be true or false", key)
+}
+
+func (a batchArgs) card() (int, error) {
+	return parseCardNumber(a.str("card"))
+}
+
+func scalarString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+var (
+	batchRefName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	batchRefUse  = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_-]*)\.([a-z_]+)\}`)
+)
+
+// readBatchOps reads a JSONL script, one operation object per line, or a
+// YAML script, a list of operation mappings. .yaml and .yml files are YAML;
+// otherwise a script starting with "- " is YAML.
+func readBatchOps(file string) ([]batchOp, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	type item struct {
+		line   int
+		fields map[string]any
+	}
+	var items []item
+	ext := strings.ToLower(filepath.Ext(file))
+	if ext == ".yaml" || ext == ".yml" || (ext != ".jsonl" && ext != ".ndjson" && yamlList(data)) {
+		list, err := parseYAMLList(data)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range list {
+			items = append(items, item{m.line, m.fields})
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			dec := json.NewDecoder(strings.NewReader(text))
+			dec.UseNumber()
+			var fields map[string]any
+			if err := dec.Decode(&fields); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			items = append(items, item{line, fields})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	ops := make([]batchOp, 0, len(items))
+	for _, it := range items {
+		op := batchOp{Line: it.line, Name: scalarString(it.fields["op"]), Ref: scalarString(it.fields["ref"]), Args: map[string]any{}}
+		for key, value := range it.fields {
+			if key != "op" && key != "ref" {
+				op.Args[key] = value
+			}
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func yamlList(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+		return line == "-" || strings.HasPrefix(line, "- ")
+	}
+	return false
+}
+
+// validateBatch checks every operation before anything runs: known ops and
+// arguments, and references only to earlier operations' outputs.
+func validateBatch(ops []batchOp) error {
+	var problems []string
+	outputs := map[string][]string{}
+	for _, op := range ops {
+		fail := func(format string, args ...any) {
+			problems = append(problems, fmt.Sprintf("line %d: ", op.Line)+fmt.Sprintf(format, args...))
+		}
+		spec, ok := batchOps[op.Name]
+		if !ok {
+			if op.Name == "" {
+				fail("op is required")
+			} else {
+				fail("unknown op %q", op.Name)
+			}
+			continue
+		}
+		for _, key := range spec.required {
+			if strings.TrimSpace(scalarString(op.Args[key])) == "" {
+				fail("%s needs %s", op.Name, key)
+			}
+		}
+		for key := range op.Args {
+			if !contains(spec.required, key) && !contains(spec.optional, key) {
+				fail("%s has no argument %q", op.Name, key)
+			}
+		}
+		for _, use := range batchRefUse.FindAllStringSubmatch(fmt.Sprint(op.Args), -1) {
+			fields, ok := outputs[use[1]]
+			switch {
+			case !ok:
+				fail("%s refers to %q, which no earlier op defines", use[0], use[1])
+			case !contains(fields, use[2]):
+				fail("%s: %s has no %s (it has %s)", use[0], use[1], use[2], strings.Join(fields, ", "))
+			}
+		}
+		if op.Ref != "" {
+			switch {
+			case !batchRefName.MatchString(op.Ref):
+				fail("invalid ref %q", op.Ref)
+			case outputs[op.Ref] != nil:
+				fail("ref %q is already used", op.Ref)
+			case len(spec.outputs) == 0:
+				fail("%s has no outputs to refer to", op.Name)
+			default:
+				outputs[op.Ref] = spec.outputs
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return UsageError{Msg: "invalid batch script:\n  " + strings.Join(problems, "\n  ")}
+	}
+	return nil
+}
+
+// errPendingRef reports a reference to an operation that has no outputs
+// because it failed or was not sent.
+type errPendingRef struct{ ref string }
+
+func (e errPendingRef) Error() string {
+	return fmt.Sprintf("needs the result of %s", e.ref)
+}
+
+// fillRefs replaces ${ref.field} in string arguments with earlier outputs.
+func fillRefs(args map[string]any, outputs map[string]map[string]string) (batchArgs, error) {
+	var missing error
+	fill := func(s string) string {
+		return batchRefUse.ReplaceAllStringFunc(s, func(use string) string {
+			m := batchRefUse.FindStringSubmatch(use)
+			value, ok := outputs[m[1]][m[2]]
+			if !ok && missing == nil {
+				missing = errPendingRef{ref: m[1]}
+			}
+			return value
+		})
+	}
+	out := batchArgs{}
+	for key, value := range args {
+		switch v := value.(type) {
+		case string:
+			out[key] = fill(v)
+		case []any:
+			list := make([]any, len(v))
+			for i, item := range v {
+				if s, ok := item.(string); ok {
+					list[i] = fill(s)
+				} else {
+					list[i] = item
+				}
+			}
+			out[key] = list
+		default:
+			out[key] = v
+		}
+	}
+	return out, missing
+}
+
+// batchCheckpoint records the operations that succeeded, by position, so
+// that a run after a failure picks up where the last one stopped.
+type batchCheckpoint struct {
+	Script string                    `json:"script"`
+	Done   map[string]batchDoneEntry `json:"done"`
+	// Partial records operations that failed after changing something,
+	// with their progress in Outputs.
+	Partial map[string]batchDoneEntry `json:"partial,omitempty"`
+}
+
+type batchDoneEntry struct {
+	Fingerprint string            `json:"fingerprint"`
+	Ref         string            `json:"ref,omitempty"`
+	Outputs     map[string]string `json:"outputs,omitempty"`
+}
+
+func loadCheckpoint(file string) (*batchCheckpoint, error) {
+	cp := &batchCheckpoint{Done: map[string]batchDoneEntry{}, Partial: map[string]batchDoneEntry{}}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", file, err)
+	}
+	if cp.Done == nil {
+		cp.Done = map[string]batchDoneEntry{}
+	}
+	if cp.Partial == nil {
+		cp.Partial = map[string]batchDoneEntry{}
+	}
+	return cp, nil
+}
+
+func saveCheckpoint(file string, cp *batchCheckpoint) error {
+	return writeJSONFile(file, cp)
+}
+
+// writeJSONFile replaces file with v as indented JSON, through a temporary
+// file so that an interrupted write leaves the old content.
+func writeJSONFile(file string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+type batchResult struct {
+	Index   int               `json:"index"`
+	Line    int               `json:"line"`
+	Op      string            `json:"op"`
+	Ref     string            `json:"ref,omitempty"`
+	Result  string            `json:"result"`
+	Outputs map[string]string `json:"outputs,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+var batchView = view[batchResult]{headers: []string{"#", "LINE", "OP", "REF", "RESULT", "DETAIL"}, row: func(r batchResult) []string {
+	detail := r.Error
+	if detail == "" {
+		keys := make([]string, 0, len(r.Outputs))
+		for key := range r.Outputs {
+			if key != "location" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			detail = strings.TrimSpace(detail + " " + key + "=" + r.Outputs[key])
+		}
+	}
+	return []string{strconv.Itoa(r.Index), strconv.Itoa(r.Line), r.Op, r.Ref, r.Result, detail}
+}}
+
+func runBatch(ctx Context, args []string) int {
+	if len(args) == 0 || args[0] != "run" {
+		fmt.Fprint(os.Stderr, helpForBatch())
+		return 2
+	}
+	if len(args) < 2 || strings.HasPrefix(args[1], "-") && args[1] != "-" {
+		return handleErr(helpForBatch(), UsageError{Msg: "script file is required"})
+	}
+	file := args[1]
+	fs := flag.NewFlagSet("batch run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	continueOnError := fs.Bool("continue-on-error", false, "Keep going after a failed operation")
+	dryRun := fs.Bool("dry-run", false, "Validate and send only requests that read data")
+	checkpoint := fs.String("checkpoint", "", "Checkpoint file")
+	report := fs.String("report", "", "Write the JSON results report to a file")
+	if err := fs.Parse(args[2:]); err != nil {
+		return usageError(helpForBatch(), err)
+	}
+	if *checkpoint == "" && file != "-" {
+		*checkpoint = file + ".checkpoint"
+	}
+	if *dryRun && !ctx.DryRun {
+		ctx.DryRun = true
+		ctx.Client = fizzy.NewClient(newTransport(ctx, ctx.Token, ctx.SessionToken), ctx.Account)
+	}
+
+	ops, err := readBatchOps(file)
+	if err != nil {
+		return handleErr(helpForBatch(), err)
+	}
+	if err := validateBatch(ops); err != nil {
+		return handleErr(helpForBatch(), err)
+	}
+	if err := ensureToken(ctx); err != nil {
+		return handleErr(helpForBatch(), err)
+	}
+	if err := ensureAccount(ctx); err != nil {
+		return handleErr(helpForBatch(), err)
+	}
+	cp := &batchCheckpoint{Script: file, Done: map[string]batchDoneEntry{}, Partial: map[string]batchDoneEntry{}}
+	if *checkpoint != "" {
+		if cp, err = loadCheckpoint(*checkpoint); err != nil {
+			return handleErr(helpForBatch(), err)
+		}
+		cp.Script = file
+	}
+	outputs := map[string]map[string]string{}
+	for i, op := range ops {
+		key := strconv.Itoa(i + 1)
+		if partial, ok := cp.Partial[key]; ok && partial.Fingerprint != op.fingerprint() {
+			return handleErr(helpForBatch(), fmt.Errorf("operation %d (line %d) changed after it partly ran; undo the edit, or remove %s to run every operation again", i+1, op.Line, *checkpoint))
+		}
+		done, ok := cp.Done[key]
+		if !ok {
+			continue
+		}
+		if done.Fingerprint != op.fingerprint() {
+			return handleErr(helpForBatch(), fmt.Errorf("operation %d (line %d) changed after it ran; undo the edit, or remove %s to run every operation again", i+1, op.Line, *checkpoint))
+		}
+		if op.Ref != "" {
+			outputs[op.Ref] = done.Outputs
+		}
+	}
+	save := func() {
+		if *checkpoint != "" && !ctx.DryRun {
+			if err := saveCheckpoint(*checkpoint, cp); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not write checkpoint %s: %v\n", *checkpoint, err)
+			}
+		}
+	}
+
+	results := make([]batchResult, len(ops))
+	var firstErr error
+	resumed, ran, failed, notSent := 0, 0, 0, 0
+	for i, op := range ops {
+		r := batchResult{Index: i + 1, Line: op.Line, Op: op.Name, Ref: op.Ref}
+		if done, ok := cp.Done[strconv.Itoa(i+1)]; ok {
+			r.Result, r.Outputs = "done earlier", done.Outputs
+			results[i] = r
+			resumed++
+			continue
+		}
+		if firstErr != nil && !*continueOnError {
+			r.Result = "not run"
+			results[i] = r
+			continue
+		}
+		key := strconv.Itoa(i + 1)
+		spec := batchOps[op.Name]
+		args, err := fillRefs(op.Args, outputs)
+		var out map[string]string
+		if err == nil {
+			if partial, ok := cp.Partial[key]; ok && spec.resume != nil {
+				out, err = spec.resume(ctx, args, partial.Outputs)
+			} else {
+				out, err = spec.run(ctx, args)
+			}
+		}
+		var dryRunErr *fizzy.DryRunError
+		var pending errPendingRef
+		var partialErr *batchPartialError
+		switch {
+		case err == nil:
+			r.Result, r.Outputs = "ok", out
+			ran++
+			if op.Ref != "" {
+				outputs[op.Ref] = out
+			}
+			cp.Done[key] = batchDoneEntry{Fingerprint: op.fingerprint(), Ref: op.Ref, Outputs: out}
+			delete(cp.Partial, key)
+			save()
+		case errors.As(err, &dryRunErr), ctx.DryRun && errors.As(err, &pending):
+			r.Result, r.Error = "dry run", err.Error()
+			notSent++
+		default:
+			r.Result, r.Error = "failed", err.Error()
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			if errors.As(err, &partialErr) {
+				r.Outputs = out
+				cp.Partial[key] = batchDoneEntry{Fingerprint: op.fingerprint(), Ref: op.Ref, Outputs: partialErr.Progress}
+				save()
+			}
+		}
+		results[i] = r
+	}
+
+	if *report != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err == nil {
+			err = os.WriteFile(*report, append(data, '\n'), 0o644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not write report %s: %v\n", *report, err)
+		}
+	}
+	raws := make([]json.RawMessage, 0, len(results))
+	for _, r := range results {
+		raw, err := json.Marshal(r)
+		if err != nil {
+			return handleErr(helpForBatch(), err)
+		}
+		raws = append(raws, raw)
+	}
+	if code := outputList(ctx, helpForBatch(), results, raws, batchView); code != 0 {
+		return code
+	}
+
+	notRun := len(ops) - resumed - ran - failed - notSent
+	finished := firstErr == nil && notSent == 0
+	if finished && *checkpoint != "" && !ctx.DryRun {
+		if err := os.Remove(*checkpoint); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "warning: could not remove checkpoint %s: %v\n", *checkpoint, err)
+		}
+	}
+	if !ctx.Output.Structured() {
+		summary := fmt.Sprintf("Ran %d of %s.", ran, plural(len(ops), "operation"))
+		if resumed > 0 {
+			summary += fmt.Sprintf(" %d done earlier.", resumed)
+		}
+		if notSent > 0 {
+			summary += fmt.Sprintf(" %d not sent (dry run).", notSent)
+		}
+		if failed > 0 {
+			summary += fmt.Sprintf(" %d failed.", failed)
+		}
+		if notRun > 0 {
+			summary += fmt.Sprintf(" %d not run.", notRun)
+		}
+		fmt.Fprintln(os.Stderr, summary)
+		if firstErr != nil && *checkpoint != "" && !ctx.DryRun {
+			fmt.Fprintf(os.Stderr, "Progress is saved in %s; run the same command again to resume.\n", *checkpoint)
+		}
+	}
+	if firstErr != nil {
+		return exitCode(firstErr)
+	}
+	return 0
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"fizzy-cli/internal/tui"
+	"fizzy-cli/pkg/fizzy"
+)
+
+// boardViewLimit caps how many cards each lane query loads.
+const boardViewLimit = 500
+
+func runBoardView(ctx Context, args []string) int {
+	if len(args) < 2 {
+		return handleErr(helpForBoard(), UsageError{Msg: "board id is required"})
+	}
+	fs := flag.NewFlagSet("board view", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	refresh := fs.Duration("refresh", 30*time.Second, "Reload interval, 0 to disable")
+	if err := fs.Parse(args[2:]); err != nil {
+		return usageError(helpForBoard(), err)
+	}
+	if *refresh < 0 {
+		return handleErr(helpForBoard(), UsageError{Msg: "--refresh must not be negative"})
+	}
+	boardID, err := resolveBoard(ctx, args[1])
+	if err != nil {
+		return handleErr(helpForBoard(), err)
+	}
+	if !isTTY(os.Stdin) || !isTTY(os.Stdout) {
+		return handleErr(helpForBoard(), tui.ErrNotTerminal)
+	}
+	backend := &boardBackend{client: ctx.Client, boardID: boardID}
+	if err := tui.Run(requestContext(), tui.NewTTY(), backend, tui.Options{Refresh: *refresh}); err != nil {
+		return handleErr(helpForBoard(), err)
+	}
+	return 0
+}
+
+func runBoardRender(ctx Context, args []string) int {
+	if len(args) < 2 {
+		return handleErr(helpForBoard(), UsageError{Msg: "board id is required"})
+	}
+	fs := flag.NewFlagSet("board render", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	markdown := fs.Bool("markdown", false, "Render as Markdown")
+	width := fs.Int("width", 0, "Line width")
+	all := fs.Bool("all-lanes", false, "Include the Not Now and Done lanes")
+	if err := fs.Parse(args[2:]); err != nil {
+		return usageError(helpForBoard(), err)
+	}
+	if *width < 0 {
+		return handleErr(helpForBoard(), UsageError{Msg: "--width must not be negative"})
+	}
+	boardID, err := resolveBoard(ctx, args[1])
+	if err != nil {
+		return handleErr(helpForBoard(), err)
+	}
+	backend := &boardBackend{client: ctx.Client, boardID: boardID, skipInactive: !*all}
+	board, err := backend.Load(requestContext())
+	if err != nil {
+		return handleErr(helpForBoard(), err)
+	}
+	if *markdown || ctx.Output.Format == FormatMarkdown {
+		fmt.Print(tui.Markdown(board))
+		return 0
+	}
+	if *width == 0 {
+		*width = outputWidth()
+	}
+	fmt.Print(tui.Text(board, *width, ctx.Output.Color))
+	return 0
+}
+
+// outputWidth is the terminal's width, or $COLUMNS, or 80.
+func outputWidth() int {
+	if w, _, err := tui.NewTTY().Size(); err == nil && w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 80
+}
+
+// boardBackend loads a board's lanes and changes cards through the API.
+type boardBackend struct {
+	client  *fizzy.Client
+	boardID string
+	// skipInactive leaves out the Not Now and Done lanes.
+	skipInactive bool
+}
+
+func (b *boardBackend) Load(ctx context.Context) (*tui.Board, error) {
+	board, _, err := b.client.Boards.Get(ctx, b.boardID)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := b.client.Columns.Iter(b.boardID, nil).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var notNow, closed []fizzy.Card
+	if !b.skipInactive {
+		if notNow, err = b.cards(ctx, "not_now"); err != nil {
+			return nil, err
+		}
+		if closed, err = b.cards(ctx, "closed"); err != nil {
+			return nil, err
+		}
+	}
+	open, err := b.cards(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	notNowLane := tui.Lane{Kind: tui.LaneNotNow, Name: "Not Now", Color: sgrGray, Cards: notNow}
+	triage := tui.Lane{Kind: tui.LaneTriage, Name: "Maybe?", Color: sgrPurple}
+	lanes := make([]tui.Lane, len(columns))
+	index := map[string]int{}
+	for i, c := range columns {
+		lanes[i] = tui.Lane{Kind: tui.LaneColumn, ColumnID: c.ID, Name: c.Name, Color: columnColor(c.Color)}
+		index[c.ID] = i
+	}
+	// The unfiltered listing can overlap the not-now and closed ones; those
+	// lanes win.
+	seen := map[int]bool{}
+	for _, c := range append(notNow, closed...) {
+		seen[c.Number] = true
+	}
+	for _, c := range open {
+		if seen[c.Number] {
+			continue
+		}
+		if c.Column != nil {
+			if i, ok := index[c.Column.ID]; ok {
+				lanes[i].Cards = append(lanes[i].Cards, c)
+				continue
+			}
+		}
+		triage.Cards = append(triage.Cards, c)
+	}
+	if b.skipInactive {
+		return &tui.Board{Name: board.Name, Lanes: append([]tui.Lane{triage}, lanes...)}, nil
+	}
+	all := append([]tui.Lane{notNowLane, triage}, lanes...)
+	all = append(all, tui.Lane{Kind: tui.LaneDone, Name: "Done", Color: sgrGreen, Cards: closed})
+	return &tui.Board{Name: board.Name, Lanes: all}, nil
+}
+
+func (b *boardBackend) cards(ctx context.Context, indexedBy string) ([]fizzy.Card, error) {
+	opts := &fizzy.CardListOptions{BoardIDs: []string{b.boardID}, IndexedBy: indexedBy}
+	opts.Limit = boardViewLimit
+	return b.client.Cards.Iter(opts).All(ctx)
+}
+
+func (b *boardBackend) Detail(ctx context.Context, number int) (*tui.Detail, error) {
+	card, _, err := b.client.Cards.Get(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := b.client.Comments.Iter(number, nil).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tui.Detail{Card: card, Comments: comments}, nil
+}
+
+func (b *boardBackend) Triage(ctx context.Context, number int, columnID string) error {
+	_, err := b.client.Cards.Triage(ctx, number, columnID)
+	return err
+}
+
+func (b *boardBackend) Untriage(ctx context.Context, number int) error {
+	_, err := b.client.Cards.Untriage(ctx, number)
+	return err
+}
+
+func (b *boardBackend) Close(ctx context.Context, number int) error {
+	_, err := b.client.Cards.Close(ctx, number)
+	return err
+}
+
+func (b *boardBackend) Reopen(ctx context.Context, number int) error {
+	_, err := b.client.Cards.Reopen(ctx, number)
+	return err
+}
+
+func (b *boardBackend) NotNow(ctx context.Context, number int) error {
+	_, err := b.client.Cards.NotNow(ctx, number)
+	return err
+}
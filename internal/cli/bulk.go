@@ -0,0 +1,396 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fizzy-cli/internal/journal"
+	"fizzy-cli/pkg/fizzy"
+)
+
+const (
+	bulkConcurrency    = 4
+	maxBulkConcurrency = 16
+	bulkPreviewCards   = 20
+)
+
+// bulkActions maps each card bulk action to the verb of the closing
+// summary.
+var bulkActions = map[string]string{
+	"close":   "Closed",
+	"reopen":  "Reopened",
+	"not-now": "Moved to Not Now",
+	"triage":  "Moved",
+	"tag":     "Tagged",
+	"assign":  "Assigned",
+	"watch":   "Subscribed to",
+	"delete":  "Deleted",
+}
+
+type bulkResult struct {
+	Card   int    `json:"card"`
+	Title  string `json:"title"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+
+	before   json.RawMessage
+	comments json.RawMessage
+}
+
+var bulkView = view[bulkResult]{headers: []string{"CARD", "TITLE", "RESULT", "ERROR"}, row: func(r bulkResult) []string {
+	return []string{"#" + strconv.Itoa(r.Card), r.Title, r.Result, r.Error}
+}}
+
+func runCardBulk(ctx Context, args []string) int {
+	if len(args) == 0 {
+		return handleErr(helpForCard(), UsageError{Msg: "bulk action is required"})
+	}
+	action := args[0]
+	if _, ok := bulkActions[action]; !ok {
+		return handleErr(helpForCard(), UsageError{Msg: fmt.Sprintf("unknown bulk action %q", action)})
+	}
+	args = args[1:]
+	var target string
+	switch action {
+	case "triage", "tag", "assign":
+		if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+			what := map[string]string{"triage": "column", "tag": "tag title", "assign": "user"}[action]
+			return handleErr(helpForCard(), UsageError{Msg: fmt.Sprintf("card bulk %s needs a %s", action, what)})
+		}
+		target, args = args[0], args[1:]
+	}
+	if action == "tag" {
+		if target = tagTitle(target); target == "" {
+			return handleErr(helpForCard(), UsageError{Msg: "card bulk tag needs a tag title"})
+		}
+	}
+
+	fs := flag.NewFlagSet("card bulk", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	filters := addCardFilters(fs)
+	limit := fs.Int("limit", 0, "Stop after N cards")
+	concurrency := fs.Int("concurrency", bulkConcurrency, "Requests in flight")
+	yes := fs.Bool("yes", false, "Skip the confirmation prompt")
+	if err := fs.Parse(args); err != nil {
+		return usageError(helpForCard(), err)
+	}
+	if *limit < 0 {
+		return handleErr(helpForCard(), UsageError{Msg: "--limit must not be negative"})
+	}
+	if *concurrency < 1 || *concurrency > maxBulkConcurrency {
+		return handleErr(helpForCard(), UsageError{Msg: fmt.Sprintf("--concurrency must be between 1 and %d", maxBulkConcurrency)})
+	}
+
+	cards, err := bulkCards(ctx, filters, *limit, *concurrency)
+	if err != nil {
+		return handleErr(helpForCard(), err)
+	}
+	if len(cards) == 0 {
+		if ctx.Output.Structured() {
+			return outputList(ctx, helpForCard(), []bulkResult{}, nil, bulkView)
+		}
+		fmt.Fprintln(os.Stdout, "No cards match.")
+		return 0
+	}
+
+	// Resolve the argument once: a user, or a column on each board.
+	label := target
+	columns := map[string]string{}
+	switch action {
+	case "triage":
+		for _, card := range cards {
+			if _, ok := columns[card.Board.ID]; ok {
+				continue
+			}
+			column, err := resolveColumn(ctx, card.Board.ID, target)
+			if err != nil {
+				return handleErr(helpForCard(), fmt.Errorf("board %s: %w", card.Board.Name, err))
+			}
+			columns[card.Board.ID] = column
+		}
+	case "assign":
+		if target, err = resolveUser(ctx, target); err != nil {
+			return handleErr(helpForCard(), err)
+		}
+	}
+
+	ctx.AssumeYes = ctx.AssumeYes || *yes
+	preview := bulkPreview(action, label, cards)
+	if ctx.DryRun && !ctx.Output.Structured() {
+		fmt.Fprintln(os.Stderr, preview)
+	}
+	if err := confirmDestroy(ctx, "card bulk "+action, func(_ context.Context) (string, string, error) {
+		return preview, strconv.Itoa(len(cards)), nil
+	}); err != nil {
+		return handleErr(helpForCard(), err)
+	}
+
+	results := make([]bulkResult, len(cards))
+	parallel(len(cards), *concurrency, func(i int) {
+		results[i] = bulkApply(ctx, action, target, columns, cards[i])
+	})
+
+	failed, skipped, notSent, done := 0, 0, 0, 0
+	raws := make([]json.RawMessage, 0, len(results))
+	for _, r := range results {
+		switch r.Result {
+		case "failed":
+			failed++
+		case "skipped":
+			skipped++
+		case "dry run":
+			notSent++
+		case "ok":
+			done++
+			if entry, ok := bulkJournalEntry(action, target, r); ok {
+				journalChange(ctx, entry)
+			}
+		}
+		raw, err := json.Marshal(r)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		raws = append(raws, raw)
+	}
+	if code := outputList(ctx, helpForCard(), results, raws, bulkView); code != 0 {
+		return code
+	}
+	if !ctx.Output.Structured() {
+		summary := fmt.Sprintf("%s %d of %s.", bulkActions[action], done, plural(len(results), "card"))
+		if skipped > 0 {
+			summary += fmt.Sprintf(" %d skipped.", skipped)
+		}
+		if notSent > 0 {
+			summary += fmt.Sprintf(" %d not sent (dry run).", notSent)
+		}
+		if failed > 0 {
+			summary += fmt.Sprintf(" %d failed.", failed)
+		}
+		fmt.Fprintln(os.Stderr, summary)
+	}
+	if failed > 0 {
+		return exitError
+	}
+	return 0
+}
+
+// bulkCards returns the cards matching the filters or, without filters,
+// the card numbers piped on stdin.
+func bulkCards(ctx Context, filters *cardFilters, limit, concurrency int) ([]fizzy.Card, error) {
+	if filters.set() {
+		opts, err := filters.options(ctx, fizzy.ListOptions{Limit: limit})
+		if err != nil {
+			return nil, err
+		}
+		return ctx.Client.Cards.Iter(opts).All(requestContext())
+	}
+	if isTTY(os.Stdin) {
+		return nil, UsageError{Msg: "give card list filters or pipe card numbers on stdin"}
+	}
+	numbers, err := readCardNumbers(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(numbers) > limit {
+		numbers = numbers[:limit]
+	}
+	cards := make([]fizzy.Card, len(numbers))
+	errs := make([]error, len(numbers))
+	parallel(len(numbers), concurrency, func(i int) {
+		card, _, err := ctx.Client.Cards.Get(requestContext(), numbers[i])
+		if err != nil {
+			errs[i] = fmt.Errorf("card #%d: %w", numbers[i], err)
+			return
+		}
+		cards[i] = *card
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// readCardNumbers reads one card number per line, ignoring blank lines and
+// anything after the first field, so card list --plain output can be piped
+// in. Duplicates are dropped.
+func readCardNumbers(r io.Reader) ([]int, error) {
+	var numbers []int
+	seen := map[int]bool{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		number, err := parseCardNumber(fields[0])
+		if err != nil {
+			return nil, UsageError{Msg: fmt.Sprintf("stdin line %d: invalid card number %q", line, fields[0])}
+		}
+		if !seen[number] {
+			seen[number] = true
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers, scanner.Err()
+}
+
+func bulkPreview(action, target string, cards []fizzy.Card) string {
+	var b strings.Builder
+	count := plural(len(cards), "card")
+	switch action {
+	case "close":
+		fmt.Fprintf(&b, "This closes %s:", count)
+	case "reopen":
+		fmt.Fprintf(&b, "This reopens %s:", count)
+	case "not-now":
+		fmt.Fprintf(&b, "This moves %s to Not Now:", count)
+	case "triage":
+		fmt.Fprintf(&b, "This moves %s into column %q:", count, target)
+	case "tag":
+		fmt.Fprintf(&b, "This tags %s with %q:", count, target)
+	case "assign":
+		fmt.Fprintf(&b, "This assigns %s to %s:", count, target)
+	case "watch":
+		fmt.Fprintf(&b, "This subscribes you to %s:", count)
+	case "delete":
+		fmt.Fprintf(&b, "This permanently deletes %s:", count)
+	}
+	for i, card := range cards {
+		if i == bulkPreviewCards {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(cards)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  #%d %s (%s)", card.Number, card.Title, card.Board.Name)
+	}
+	return b.String()
+}
+
+// bulkApply runs action on one card. Tagging and assigning toggle, so cards
+// that already have the tag or assignee are skipped rather than undone.
+func bulkApply(ctx Context, action, target string, columns map[string]string, card fizzy.Card) bulkResult {
+	r := bulkResult{Card: card.Number, Title: card.Title, Result: "ok"}
+	fail := func(err error) bulkResult {
+		var dryRun *fizzy.DryRunError
+		if errors.As(err, &dryRun) {
+			r.Result = "dry run"
+		} else {
+			r.Result = "failed"
+		}
+		r.Error = err.Error()
+		return r
+	}
+	reqCtx := requestContext()
+	var current fizzy.Card
+	switch action {
+	case "close", "reopen", "tag", "assign", "delete":
+		before, err := snapshotCard(ctx, card.Number)
+		if err != nil {
+			return fail(err)
+		}
+		if err := json.Unmarshal(before, &current); err != nil {
+			return fail(err)
+		}
+		r.before = before
+	}
+
+	var err error
+	switch action {
+	case "close":
+		_, err = ctx.Client.Cards.Close(reqCtx, card.Number)
+	case "reopen":
+		_, err = ctx.Client.Cards.Reopen(reqCtx, card.Number)
+	case "not-now":
+		_, err = ctx.Client.Cards.NotNow(reqCtx, card.Number)
+	case "triage":
+		_, err = ctx.Client.Cards.Triage(reqCtx, card.Number, columns[card.Board.ID])
+	case "tag":
+		if hasTag(current.Tags, target) {
+			r.Result, r.Error = "skipped", "already tagged"
+			return r
+		}
+		_, err = ctx.Client.Cards.ToggleTag(reqCtx, card.Number, target)
+	case "assign":
+		for _, user := range current.Assignees {
+			if user.ID == target {
+				r.Result, r.Error = "skipped", "already assigned"
+				return r
+			}
+		}
+		_, err = ctx.Client.Cards.ToggleAssignment(reqCtx, card.Number, target)
+	case "watch":
+		_, err = ctx.Client.Cards.Watch(reqCtx, card.Number)
+	case "delete":
+		comments, cerr := ctx.Client.Comments.Iter(card.Number, nil).All(reqCtx)
+		if cerr != nil {
+			return fail(cerr)
+		}
+		if r.comments, cerr = json.Marshal(comments); cerr != nil {
+			return fail(cerr)
+		}
+		_, err = ctx.Client.Cards.Delete(reqCtx, card.Number)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return r
+}
+
+// bulkJournalEntry returns the undo journal entry for a change, for the
+// actions that card close, reopen, tag, assign and delete journal too.
+func bulkJournalEntry(action, target string, r bulkResult) (journal.Entry, bool) {
+	if r.before == nil {
+		return journal.Entry{}, false
+	}
+	entry := journal.Entry{Action: "card " + action, Card: r.Card, Before: r.before}
+	switch action {
+	case "tag", "assign":
+		entry.Target = target
+	case "delete":
+		entry.Comments = r.comments
+	}
+	return entry, true
+}
+
+// tagTitle normalizes a tag title the way the API does when tagging: "#"
+// and surrounding space are dropped.
+func tagTitle(title string) string {
+	return strings.TrimPrefix(strings.TrimSpace(title), "#")
+}
+
+func hasTag(tags []string, title string) bool {
+	for _, t := range tags {
+		if tagTitle(t) == tagTitle(title) {
+			return true
+		}
+	}
+	return false
+}
+
+// parallel calls fn for 0..n-1 with at most workers calls at a time.
+func parallel(n, workers int, fn func(int)) {
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
@@ -5,29 +5,49 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"fizzy-cli/internal/api"
 	"fizzy-cli/internal/config"
+	"fizzy-cli/pkg/fizzy"
 )
 
 const (
-	defaultBaseURL = "https://app.fizzy.do"
+	defaultBaseURL  = "https://app.fizzy.do"
+	defaultCacheTTL = 5 * time.Minute
 )
 
 type Context struct {
 	Config       config.Config
 	ConfigPath   string
+	ProfileName  string
+	Profile      config.Profile
 	Account      string
 	BaseURL      string
 	Token        string
 	SessionToken string
 	Output       OutputMode
-	Client       *api.Client
-	Version      string
-	Commit       string
-	BuildDate    string
+	Retry        api.RetryPolicy
+	CacheTTL     time.Duration
+	// Cassette records or replays HTTP exchanges (--record, --replay).
+	Cassette http.RoundTripper
+	// Verbose traces requests to stderr; DryRun skips writes; PrintCurl
+	// prints each request as a curl command (--verbose, --dry-run,
+	// --print-curl, --show-secrets).
+	Verbose     bool
+	DryRun      bool
+	PrintCurl   bool
+	ShowSecrets bool
+	// AssumeYes skips confirmation prompts (--yes, FIZZY_ASSUME_YES).
+	AssumeYes bool
+	Client    *fizzy.Client
+	Version   string
+	Commit    string
+	BuildDate string
 }
 
 type UsageError struct {
@@ -51,10 +71,17 @@ func Run(version, commit, buildDate string, args []string) int {
 		return 0
 	}
 
+	jsonErrors = ctx.Output.Format == FormatJSON || ctx.Output.Format == FormatNDJSON
 	ctx.Version = version
 	ctx.Commit = commit
 	ctx.BuildDate = buildDate
-	ctx.Client = api.NewClient(ctx.BaseURL, ctx.Token, ctx.SessionToken, fmt.Sprintf("fizzy-cli/%s", version))
+	if needsCredentials(rest) {
+		if err := loadCredentials(&ctx); err != nil {
+			printErr(err)
+			return exitCode(err)
+		}
+	}
+	ctx.Client = fizzy.NewClient(newTransport(ctx, ctx.Token, ctx.SessionToken), ctx.Account)
 
 	switch rest[0] {
 	case "help":
@@ -70,12 +97,20 @@ func Run(version, commit, buildDate string, args []string) int {
 		return runAccount(ctx, rest[1:])
 	case "config":
 		return runConfig(ctx, rest[1:])
+	case "profile":
+		return runProfile(ctx, rest[1:])
+	case "completion":
+		return runCompletion(ctx, rest[1:])
+	case "__complete":
+		return runComplete(rest[1:])
 	case "board":
 		return runBoard(ctx, rest[1:])
 	case "card":
 		return runCard(ctx, rest[1:])
 	case "comment":
 		return runComment(ctx, rest[1:])
+	case "step":
+		return runStep(ctx, rest[1:])
 	case "tag":
 		return runTag(ctx, rest[1:])
 	case "column":
@@ -84,6 +119,14 @@ func Run(version, commit, buildDate string, args []string) int {
 		return runUser(ctx, rest[1:])
 	case "notification":
 		return runNotification(ctx, rest[1:])
+	case "dev":
+		return runDev(ctx, rest[1:])
+	case "batch":
+		return runBatch(ctx, rest[1:])
+	case "history":
+		return runHistory(ctx, rest[1:])
+	case "undo":
+		return runUndo(ctx, rest[1:])
 	default:
 		printErr(UsageError{Msg: fmt.Sprintf("unknown command %q", rest[0])})
 		fmt.Fprint(os.Stderr, "\n")
@@ -115,20 +158,53 @@ func parseGlobal(args []string) (Context, []string, bool, bool, error) {
 		flagToken   string
 		flagAccount string
 		flagConfig  string
+		flagProfile string
 		flagJSON    bool
 		flagPlain   bool
 		flagNoColor bool
 		flagHelp    bool
 		flagVersion bool
+
+		flagMaxAttempts  int
+		flagRetryTimeout time.Duration
+
+		flagOutput string
+		flagFormat string
+		flagFields string
+		flagJQ     string
+
+		flagRecord string
+		flagReplay string
+
+		flagVerbose     bool
+		flagDryRun      bool
+		flagPrintCurl   bool
+		flagShowSecrets bool
+		flagYes         bool
 	)
 
 	fs.StringVar(&flagBaseURL, "base-url", "", "API base URL")
 	fs.StringVar(&flagToken, "token", "", "Personal access token")
 	fs.StringVar(&flagAccount, "account", "", "Account slug")
 	fs.StringVar(&flagConfig, "config", defaultConfigPath, "Config file path")
+	fs.StringVar(&flagProfile, "profile", "", "Config profile")
 	fs.BoolVar(&flagJSON, "json", false, "JSON output")
 	fs.BoolVar(&flagPlain, "plain", false, "Plain output")
+	fs.StringVar(&flagOutput, "output", "", "Output format")
+	fs.StringVar(&flagOutput, "o", "", "Output format")
 	fs.BoolVar(&flagNoColor, "no-color", false, "Disable color")
+	fs.StringVar(&flagFormat, "format", "", "Go template for each item")
+	fs.StringVar(&flagFields, "fields", "", "Comma-separated fields to show")
+	fs.StringVar(&flagJQ, "jq", "", "Path expression applied to JSON output")
+	fs.IntVar(&flagMaxAttempts, "max-attempts", 0, "Maximum attempts per request")
+	fs.DurationVar(&flagRetryTimeout, "retry-timeout", 0, "Total time budget for retries")
+	fs.StringVar(&flagRecord, "record", "", "Record HTTP exchanges to a directory")
+	fs.StringVar(&flagReplay, "replay", "", "Answer requests from a recorded directory")
+	fs.BoolVar(&flagVerbose, "verbose", false, "Trace HTTP requests to stderr")
+	fs.BoolVar(&flagDryRun, "dry-run", false, "Send only requests that read data")
+	fs.BoolVar(&flagPrintCurl, "print-curl", false, "Print requests as curl commands")
+	fs.BoolVar(&flagShowSecrets, "show-secrets", false, "Do not mask credentials in traces")
+	fs.BoolVar(&flagYes, "yes", false, "Skip confirmation prompts")
 	fs.BoolVar(&flagHelp, "help", false, "Show help")
 	fs.BoolVar(&flagHelp, "h", false, "Show help")
 	fs.BoolVar(&flagVersion, "version", false, "Print version")
@@ -147,50 +223,238 @@ func parseGlobal(args []string) (Context, []string, bool, bool, error) {
 
 	ctx.ConfigPath = flagConfig
 	ctx.Config = cfg
-	ctx.Output = OutputMode{JSON: flagJSON, Plain: flagPlain}
-	_ = flagNoColor
 
-	ctx.BaseURL = firstNonEmpty(flagBaseURL, os.Getenv("FIZZY_BASE_URL"), cfg.BaseURL, defaultBaseURL)
-	ctx.Token = firstNonEmpty(flagToken, os.Getenv("FIZZY_TOKEN"), cfg.Token)
-	ctx.SessionToken = cfg.SessionToken
-	ctx.Account = normalizeAccount(firstNonEmpty(flagAccount, os.Getenv("FIZZY_ACCOUNT"), cfg.Account))
+	ctx.ProfileName = firstNonEmpty(flagProfile, os.Getenv("FIZZY_PROFILE"), cfg.Current())
+	profile, ok := cfg.Profile(ctx.ProfileName)
+	rest := fs.Args()
+	if !ok && ctx.ProfileName != config.DefaultProfile && (len(rest) == 0 || rest[0] != "profile") {
+		return ctx, nil, false, false, UsageError{Msg: fmt.Sprintf("unknown profile %q; create it with 'fizzy-cli profile add %s'", ctx.ProfileName, ctx.ProfileName)}
+	}
+	ctx.Profile = profile
+
+	ctx.BaseURL = firstNonEmpty(flagBaseURL, os.Getenv("FIZZY_BASE_URL"), profile.BaseURL, defaultBaseURL)
+	ctx.Token = firstNonEmpty(flagToken, os.Getenv("FIZZY_TOKEN"), profile.Token)
+	ctx.SessionToken = profile.SessionToken
+	ctx.Account = normalizeAccount(firstNonEmpty(flagAccount, os.Getenv("FIZZY_ACCOUNT"), profile.Account))
+
+	retry, err := retryPolicy(flagMaxAttempts, flagRetryTimeout, profile)
+	if err != nil {
+		return ctx, nil, false, false, err
+	}
+	ctx.Retry = retry
+
+	cacheTTL, err := durationSetting("cache TTL", defaultCacheTTL, os.Getenv("FIZZY_CACHE_TTL"), profile.CacheTTL)
+	if err != nil {
+		return ctx, nil, false, false, err
+	}
+	ctx.CacheTTL = cacheTTL
+
+	if err := setupCassette(&ctx, firstNonEmpty(flagRecord, os.Getenv("FIZZY_RECORD")), firstNonEmpty(flagReplay, os.Getenv("FIZZY_REPLAY"))); err != nil {
+		return ctx, nil, false, false, err
+	}
+
+	ctx.Verbose = flagVerbose || envEnabled(os.Getenv("FIZZY_DEBUG"))
+	ctx.DryRun = flagDryRun
+	ctx.PrintCurl = flagPrintCurl
+	ctx.ShowSecrets = flagShowSecrets
+	ctx.AssumeYes = flagYes || envEnabled(os.Getenv("FIZZY_ASSUME_YES"))
 
-	if ctx.Output.JSON && ctx.Output.Plain {
-		return ctx, nil, false, false, UsageError{Msg: "--json and --plain cannot be used together"}
+	format, err := outputFormat(flagOutput, flagJSON, flagPlain)
+	if err != nil {
+		return ctx, nil, false, false, err
+	}
+	ctx.Output = OutputMode{Format: format, Color: format == FormatTable && colorEnabled(flagNoColor)}
+	if err := parseOutputFlags(&ctx.Output, flagFormat, flagFields, flagJQ); err != nil {
+		return ctx, nil, false, false, err
 	}
 
-	return ctx, fs.Args(), flagHelp, flagVersion, nil
+	return ctx, rest, flagHelp, flagVersion, nil
 }
 
-func firstNonEmpty(values ...string) string {
-	for _, v := range values {
-		if strings.TrimSpace(v) != "" {
-			return strings.TrimSpace(v)
+// outputFormat resolves --output together with its --json and --plain
+// aliases.
+func outputFormat(output string, jsonFlag, plainFlag bool) (OutputFormat, error) {
+	if jsonFlag && plainFlag {
+		return "", UsageError{Msg: "--json and --plain cannot be used together"}
+	}
+	var alias OutputFormat
+	switch {
+	case jsonFlag:
+		alias = FormatJSON
+	case plainFlag:
+		alias = FormatPlain
+	}
+	if output == "" {
+		if alias == "" {
+			return FormatTable, nil
 		}
+		return alias, nil
 	}
-	return ""
+	format, err := parseOutputFormat(output)
+	if err != nil {
+		return "", UsageError{Msg: err.Error()}
+	}
+	if alias != "" && alias != format {
+		return "", UsageError{Msg: fmt.Sprintf("--%s cannot be used with --output %s", alias, format)}
+	}
+	return format, nil
 }
 
-func normalizeAccount(value string) string {
-	return strings.Trim(value, "/")
+func parseOutputFlags(mode *OutputMode, format, fields, jq string) error {
+	set := 0
+	for _, v := range []string{format, fields, jq} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return UsageError{Msg: "--format, --fields and --jq cannot be used together"}
+	}
+	if format != "" {
+		if !mode.Tabular() {
+			return UsageError{Msg: fmt.Sprintf("--format cannot be used with --output %s", mode.Format)}
+		}
+		tmpl, err := parseOutputTemplate(format)
+		if err != nil {
+			return UsageError{Msg: err.Error()}
+		}
+		mode.Template = tmpl
+	}
+	if fields != "" {
+		mode.Fields = parseFields(fields)
+		if len(mode.Fields) == 0 {
+			return UsageError{Msg: "--fields needs at least one field name"}
+		}
+	}
+	if jq != "" {
+		steps, err := parseJQ(jq)
+		if err != nil {
+			return UsageError{Msg: err.Error()}
+		}
+		mode.JQ = steps
+	}
+	return nil
 }
 
-func printErr(err error) {
-	if err == nil {
-		return
+func retryPolicy(flagMaxAttempts int, flagRetryTimeout time.Duration, cfg config.Profile) (api.RetryPolicy, error) {
+	policy := api.DefaultRetryPolicy()
+
+	if cfg.MaxAttempts > 0 {
+		policy.MaxAttempts = cfg.MaxAttempts
+	}
+	if env := strings.TrimSpace(os.Getenv("FIZZY_MAX_ATTEMPTS")); env != "" {
+		n, err := strconv.Atoi(env)
+		if err != nil || n < 1 {
+			return policy, UsageError{Msg: fmt.Sprintf("invalid FIZZY_MAX_ATTEMPTS %q", env)}
+		}
+		policy.MaxAttempts = n
+	}
+	if flagMaxAttempts < 0 {
+		return policy, UsageError{Msg: "--max-attempts must be at least 1"}
+	}
+	if flagMaxAttempts > 0 {
+		policy.MaxAttempts = flagMaxAttempts
+	}
+
+	timeout := firstNonEmpty(os.Getenv("FIZZY_RETRY_TIMEOUT"), cfg.RetryTimeout)
+	if timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d < 0 {
+			return policy, UsageError{Msg: fmt.Sprintf("invalid retry timeout %q", timeout)}
+		}
+		policy.Timeout = d
 	}
-	fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+	if flagRetryTimeout < 0 {
+		return policy, UsageError{Msg: "--retry-timeout must not be negative"}
+	}
+	if flagRetryTimeout > 0 {
+		policy.Timeout = flagRetryTimeout
+	}
+	return policy, nil
 }
 
-func exitCode(err error) int {
-	if err == nil {
-		return 0
+func durationSetting(name string, fallback time.Duration, values ...string) (time.Duration, error) {
+	value := firstNonEmpty(values...)
+	if value == "" {
+		return fallback, nil
 	}
-	var usage UsageError
-	if errors.As(err, &usage) {
-		return 2
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return fallback, UsageError{Msg: fmt.Sprintf("invalid %s %q", name, value)}
+	}
+	return d, nil
+}
+
+func newTransport(ctx Context, token, sessionToken string) *fizzy.Transport {
+	transport := fizzy.NewTransport(ctx.BaseURL, token, sessionToken, fmt.Sprintf("fizzy-cli/%s", ctx.Version))
+	transport.Retry = ctx.Retry
+	if ctx.Cassette != nil {
+		transport.HTTP.Transport = ctx.Cassette
+	}
+	if ctx.Verbose {
+		transport.Debug = os.Stderr
+	}
+	if ctx.PrintCurl {
+		transport.Curl = os.Stderr
 	}
-	return 1
+	transport.DryRun = ctx.DryRun
+	transport.ShowSecrets = ctx.ShowSecrets
+	if token != "" || sessionToken != "" {
+		transport.Unauthorized = unauthorizedHandler(transport, reloginPrompt(ctx))
+	}
+	return transport
+}
+
+// envEnabled reports whether a boolean environment variable like
+// FIZZY_DEBUG is switched on.
+func envEnabled(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "0", "false", "no", "off":
+		return false
+	}
+	return true
+}
+
+// setupCassette installs a recorder or replayer. Both turn off the lookup
+// cache so that every request is recorded and a replay makes the same
+// requests as the recording. Replays need no credentials.
+func setupCassette(ctx *Context, record, replay string) error {
+	switch {
+	case record != "" && replay != "":
+		return UsageError{Msg: "--record and --replay cannot be used together"}
+	case record != "":
+		recorder, err := fizzy.NewRecorder(record, nil)
+		if err != nil {
+			return err
+		}
+		ctx.Cassette = recorder
+	case replay != "":
+		replayer, err := fizzy.NewReplayer(replay)
+		if err != nil {
+			return err
+		}
+		ctx.Cassette = replayer
+		if ctx.Token == "" && ctx.SessionToken == "" {
+			ctx.Token = "replay"
+		}
+	default:
+		return nil
+	}
+	ctx.CacheTTL = 0
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func normalizeAccount(value string) string {
+	return strings.Trim(value, "/")
 }
 
 func ensureAccount(ctx Context) error {
@@ -207,10 +471,6 @@ func ensureToken(ctx Context) error {
 	return nil
 }
 
-func withAccount(ctx Context, path string) string {
-	return "/" + ctx.Account + path
-}
-
 func requestContext() context.Context {
 	return context.Background()
 }
@@ -0,0 +1,709 @@
+package cli_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"fizzy-cli/internal/cli"
+	"fizzy-cli/pkg/fizzy/fizzytest"
+)
+
+// These tests run the CLI end to end against the in-memory fake API. They
+// swap os.Stdin, os.Stdout and os.Stderr, so they must not run in parallel.
+
+type result struct {
+	stdout string
+	stderr string
+	code   int
+}
+
+// startFake serves a seeded fake and points the CLI at it, with config,
+// cache and journal in a temporary directory.
+func startFake(t *testing.T) *fizzytest.Server {
+	t.Helper()
+	return startFakeWith(t, func(h http.Handler) http.Handler { return h })
+}
+
+// startFakeWith is startFake with the fake's handler wrapped, to inject
+// failures.
+func startFakeWith(t *testing.T, wrap func(http.Handler) http.Handler) *fizzytest.Server {
+	t.Helper()
+	fake := fizzytest.New()
+	fake.Seed()
+	srv := httptest.NewServer(wrap(fake))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("FIZZY_CONFIG", filepath.Join(dir, "config.json"))
+	t.Setenv("FIZZY_BASE_URL", srv.URL)
+	t.Setenv("FIZZY_TOKEN", "test-token")
+	t.Setenv("FIZZY_ACCOUNT", fizzytest.Account)
+	for _, name := range []string{"FIZZY_PROFILE", "FIZZY_ASSUME_YES", "FIZZY_DEBUG", "FIZZY_RECORD", "FIZZY_REPLAY", "FIZZY_CACHE_TTL", "FIZZY_MAX_ATTEMPTS", "FIZZY_RETRY_TIMEOUT", "NO_COLOR"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("NO_COLOR", "1")
+	return fake
+}
+
+// run runs the CLI with stdin as its input and captures its output.
+func run(t *testing.T, stdin string, args ...string) result {
+	t.Helper()
+	dir := t.TempDir()
+	in := writeFile(t, dir, "stdin", stdin)
+	inFile, err := os.Open(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inFile.Close()
+	outFile, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outFile.Close()
+	errFile, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer errFile.Close()
+
+	oldIn, oldOut, oldErr := os.Stdin, os.Stdout, os.Stderr
+	os.Stdin, os.Stdout, os.Stderr = inFile, outFile, errFile
+	code := cli.Run("test", "none", "unknown", append([]string{"fizzy-cli"}, args...))
+	os.Stdin, os.Stdout, os.Stderr = oldIn, oldOut, oldErr
+
+	return result{stdout: readFile(t, outFile.Name()), stderr: readFile(t, errFile.Name()), code: code}
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// step is one command of a scenario and what it must produce.
+type step struct {
+	args   []string
+	stdin  string
+	code   int
+	stdout []string
+	stderr []string
+}
+
+func runSteps(t *testing.T, steps []step) {
+	t.Helper()
+	for _, s := range steps {
+		r := run(t, s.stdin, s.args...)
+		name := strings.Join(s.args, " ")
+		if r.code != s.code {
+			t.Errorf("%s: exit %d, want %d\nstdout: %s\nstderr: %s", name, r.code, s.code, r.stdout, firstLine(r.stderr))
+			continue
+		}
+		for _, want := range s.stdout {
+			if !strings.Contains(r.stdout, want) {
+				t.Errorf("%s: stdout does not contain %q:\n%s", name, want, r.stdout)
+			}
+		}
+		for _, want := range s.stderr {
+			if !strings.Contains(r.stderr, want) {
+				t.Errorf("%s: stderr does not contain %q:\n%s", name, want, firstLine(r.stderr))
+			}
+		}
+	}
+}
+
+// firstLine keeps failure messages short when stderr ends with a usage
+// text.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// Card, comment and step IDs the fake seeds are not fixed, so scenarios
+// that need one read it from JSON output first.
+func decodeJSON[T any](t *testing.T, r result) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal([]byte(r.stdout), &v); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, r.stdout)
+	}
+	return v
+}
+
+func TestHelpAndUsage(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		{args: []string{"--version"}, stdout: []string{"fizzy-cli test"}},
+		{args: []string{"help", "card"}, stdout: []string{"fizzy-cli card list"}},
+		{args: []string{"nope"}, code: 2, stderr: []string{`unknown command "nope"`}},
+		{args: []string{"card", "get"}, code: 2, stderr: []string{"USAGE:"}},
+		{args: []string{"card", "list", "--bogus"}, code: 2},
+		{args: []string{"-o", "json", "card", "get"}, code: 2, stderr: []string{`"exit_code":2`}},
+	})
+}
+
+func TestAuth(t *testing.T) {
+	fake := startFake(t)
+	fake.Token = "test-token"
+	runSteps(t, []step{
+		{args: []string{"auth", "status"}, stdout: []string{"Jane Doe"}},
+		{args: []string{"--token", "wrong", "board", "list"}, code: 3},
+		{args: []string{"auth", "login", "--token", "test-token"}},
+		{args: []string{"auth", "logout"}},
+	})
+}
+
+func TestAccountAndConfig(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		{args: []string{"account", "list"}, stdout: []string{fizzytest.Account}},
+		{args: []string{"account", "set", "/" + fizzytest.Account}},
+		{args: []string{"config", "set", "--max-attempts", "2"}},
+		{args: []string{"config", "show"}, stdout: []string{fizzytest.Account}},
+		{args: []string{"config", "set", "--max-attempts", "zero"}, code: 2},
+		{args: []string{"profile", "add", "work", "--account", "123"}},
+		{args: []string{"profile", "list"}, stdout: []string{"work"}},
+		{args: []string{"profile", "use", "missing"}, code: 2, stderr: []string{`unknown profile "missing"`}},
+	})
+}
+
+func TestBoards(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		{args: []string{"--plain", "board", "list"}, stdout: []string{"Roadmap", "Operations"}},
+		{args: []string{"-o", "json", "board", "get", "Roadmap"}, stdout: []string{`"name": "Roadmap"`}},
+		{args: []string{"board", "create", "--name", "Launch"}, stdout: []string{"Board created"}},
+		{args: []string{"board", "update", "Launch", "--name", "Launch 2"}},
+		{args: []string{"board", "update", "Launch 2"}, code: 2, stderr: []string{"no fields to update"}},
+		{args: []string{"board", "render", "Roadmap", "--markdown"}, stdout: []string{"In Progress", "Add dark mode"}},
+		{args: []string{"board", "get", "zzzzzzzzzzzzzzzzzzzzzzzzz"}, code: 5},
+		{args: []string{"board", "delete", "Launch 2"}, code: 2, stderr: []string{"needs --yes"}},
+		{args: []string{"board", "delete", "Launch 2", "--yes"}},
+		{args: []string{"board", "get", "Nowhere"}, code: 1, stderr: []string{"no board matches"}},
+	})
+}
+
+func TestCards(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		{args: []string{"--plain", "card", "list", "--board-id", "Roadmap"}, stdout: []string{"Add dark mode", "Crash when a column is deleted"}},
+		{args: []string{"card", "get", "1"}, stdout: []string{"Add dark mode", "Pick the palette"}},
+		{args: []string{"card", "get", "999"}, code: 5},
+		{args: []string{"card", "create", "--board-id", "Roadmap", "--title", "Write tests", "--step", "Cover cards"}, stdout: []string{"Card created"}},
+		{args: []string{"card", "create", "--board-id", "Roadmap"}, code: 2, stderr: []string{"--board-id and --title are required"}},
+		{args: []string{"card", "update", "7", "--title", "Write more tests"}},
+		{args: []string{"card", "update", "7"}, code: 2},
+		{args: []string{"card", "triage", "7", "--column-id", "In Progress"}},
+		{args: []string{"card", "tag", "7", "--title", "bug"}},
+		{args: []string{"card", "assign", "7", "--assignee-id", "sam@example.com"}},
+		{args: []string{"-o", "json", "card", "get", "7"}, stdout: []string{`"Write more tests"`, `"bug"`, `"Sam Lee"`, `"In Progress"`}},
+		{args: []string{"card", "close", "7"}, stdout: []string{"Card closed"}},
+		{args: []string{"card", "reopen", "7"}, stdout: []string{"Card reopened"}},
+		{args: []string{"card", "not-now", "7"}},
+		{args: []string{"card", "watch", "7"}},
+		{args: []string{"card", "unwatch", "7"}},
+		{args: []string{"card", "delete", "7"}, code: 2, stderr: []string{"needs --yes"}},
+		{args: []string{"card", "delete", "7", "--yes"}},
+		{args: []string{"card", "get", "7"}, code: 5},
+	})
+}
+
+func TestResolveNames(t *testing.T) {
+	var boardLists atomic.Int32
+	fake := startFakeWith(t, func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/boards") {
+				boardLists.Add(1)
+			}
+			h.ServeHTTP(w, r)
+		})
+	})
+	lists := func(want int32) {
+		t.Helper()
+		if n := boardLists.Swap(0); n != want {
+			t.Errorf("listed boards %d times, want %d", n, want)
+		}
+	}
+	list := func(board string) step {
+		return step{args: []string{"--plain", "card", "list", "--board-id", board}}
+	}
+
+	runSteps(t, []step{list("Roadmap"), list("roadmap")})
+	lists(1) // the second lookup, in another case, is served from the cache
+
+	// An ID is used as is, without a lookup.
+	later := fake.AddBoard("Later")
+	fake.AddCard(later, "Someday")
+	runSteps(t, []step{{args: []string{"--plain", "card", "list", "--board-id", later}, stdout: []string{"Someday"}}})
+	lists(0)
+
+	// A board created since the cache was filled is missing from it, so the
+	// lookup lists the boards again.
+	second := fake.AddBoard("Roadmap")
+	runSteps(t, []step{{args: []string{"--plain", "card", "list", "--board-id", "Later"}, stdout: []string{"Someday"}}})
+	lists(1)
+
+	// The fresh list has two boards named Roadmap.
+	runSteps(t, []step{
+		{args: []string{"card", "list", "--board-id", "Roadmap"}, code: 1, stderr: []string{`"Roadmap" matches 2 boards; use one of these IDs:`, second + "  Roadmap"}},
+		{args: []string{"card", "list", "--board-id", "Nowhere"}, code: 1, stderr: []string{`no board matches "Nowhere"`}},
+	})
+	lists(1)
+
+	// After a rename the cached name is stale: the new name is looked up
+	// afresh, and the fresh list no longer has the old one.
+	runSteps(t, []step{
+		{args: []string{"board", "update", later, "--name", "Parked"}},
+		{args: []string{"--plain", "card", "list", "--board-id", "Parked"}, stdout: []string{"Someday"}},
+		{args: []string{"card", "list", "--board-id", "Later"}, code: 1, stderr: []string{`no board matches "Later"`}},
+	})
+	lists(2)
+
+	// With the cache expired every lookup lists the boards.
+	t.Setenv("FIZZY_CACHE_TTL", "1ns")
+	runSteps(t, []step{list("Parked"), list("Parked")})
+	lists(2)
+}
+
+func TestCardBulk(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		{args: []string{"card", "bulk", "tag", "#bug", "--yes"}, stdin: "1\n2\n", stdout: []string{"already tagged"}, stderr: []string{"Tagged 1 of 2 cards. 1 skipped."}},
+		{args: []string{"-o", "json", "card", "get", "2"}, stdout: []string{`"bug"`}},
+		{args: []string{"card", "bulk", "close", "--board-id", "Operations"}, code: 2, stderr: []string{"needs --yes"}},
+		{args: []string{"card", "bulk", "frobnicate"}, code: 2},
+	})
+}
+
+func TestComments(t *testing.T) {
+	startFake(t)
+	r := run(t, "", "-o", "json", "comment", "list", "2")
+	comments := decodeJSON[[]struct {
+		ID string `json:"id"`
+	}](t, r)
+	if len(comments) != 2 {
+		t.Fatalf("card #2 has %d comments, want 2", len(comments))
+	}
+	id := comments[0].ID
+	runSteps(t, []step{
+		{args: []string{"comment", "get", "2", id}, stdout: []string{"Reproduced on the latest build."}},
+		{args: []string{"comment", "create", "2", "--body", "Shipped"}, stdout: []string{"Comment created"}},
+		{args: []string{"comment", "create", "2", "--body-file", "-"}, stdin: "From stdin\n", stdout: []string{"Comment created"}},
+		{args: []string{"comment", "create", "2"}, code: 2},
+		{args: []string{"comment", "update", "2", id, "--body", "Reproduced twice"}},
+		{args: []string{"comment", "get", "2", id}, stdout: []string{"Reproduced twice"}},
+		{args: []string{"comment", "delete", "2", id, "--yes"}},
+		{args: []string{"comment", "get", "2", id}, code: 5},
+	})
+}
+
+func TestSteps(t *testing.T) {
+	startFake(t)
+	r := run(t, "", "-o", "json", "step", "list", "1")
+	steps := decodeJSON[[]struct {
+		ID string `json:"id"`
+	}](t, r)
+	if len(steps) != 3 {
+		t.Fatalf("card #1 has %d steps, want 3", len(steps))
+	}
+	id := steps[1].ID
+	runSteps(t, []step{
+		{args: []string{"step", "add", "1", "--content", "Ship it"}},
+		{args: []string{"step", "add", "1", "--file", "-"}, stdin: "- [ ] Announce\n- [x] Write notes\n"},
+		{args: []string{"step", "complete", "1", id}},
+		{args: []string{"step", "update", "1", id, "--content", "Theme everything"}},
+		{args: []string{"-o", "json", "card", "get", "1"}, stdout: []string{"Ship it", "Announce", "Write notes", "Theme everything"}},
+		{args: []string{"step", "delete", "1", id}},
+		{args: []string{"step", "add", "1"}, code: 2},
+	})
+}
+
+func TestColumnsTagsUsersNotifications(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		{args: []string{"--plain", "column", "list", "--board-id", "Roadmap"}, stdout: []string{"In Progress", "Review", "Blocked"}},
+		{args: []string{"column", "create", "--board-id", "Roadmap", "--name", "Done soon"}, stdout: []string{"Column created"}},
+		{args: []string{"column", "update", "Done soon", "--board-id", "Roadmap", "--name", "Shipping"}},
+		{args: []string{"column", "get", "Shipping", "--board-id", "Roadmap"}, stdout: []string{"Shipping"}},
+		{args: []string{"column", "delete", "Shipping", "--board-id", "Roadmap", "--yes"}},
+		{args: []string{"column", "list"}, code: 2},
+		{args: []string{"--plain", "tag", "list"}, stdout: []string{"bug", "feature", "design"}},
+		{args: []string{"--plain", "user", "list"}, stdout: []string{"Jane Doe", "Sam Lee"}},
+		{args: []string{"user", "get", "sam@example.com"}, stdout: []string{"Sam Lee"}},
+		{args: []string{"user", "get", "zzzzzzzzzzzzzzzzzzzzzzzzz"}, code: 5},
+		{args: []string{"--plain", "notification", "list", "--unread"}, stdout: []string{"Sam Lee commented"}},
+		{args: []string{"notification", "read-all"}},
+		{args: []string{"--plain", "notification", "list", "--unread"}},
+	})
+	if r := run(t, "", "--plain", "notification", "list", "--unread"); strings.Contains(r.stdout, "Sam Lee commented") {
+		t.Errorf("notification still unread after read-all:\n%s", r.stdout)
+	}
+}
+
+func TestClearDescription(t *testing.T) {
+	startFake(t)
+	// The editor keeps the title and deletes the description.
+	t.Setenv("VISUAL", "sed -i '/>8/{n;q}'")
+	script := writeFile(t, t.TempDir(), "ops.jsonl", `{"op": "card.update", "card": "1", "description": ""}
+`)
+	runSteps(t, []step{
+		{args: []string{"card", "update", "1", "--clear-description", "--description", "Other"}, code: 2, stderr: []string{"--clear-description cannot be used"}},
+		{args: []string{"card", "update", "1", "--clear-description"}, stdout: []string{"Card updated."}},
+		{args: []string{"-o", "json", "card", "get", "1"}, stdout: []string{`"description": ""`}},
+		{args: []string{"undo"}, stdout: []string{"Undid 1"}},
+		{args: []string{"-o", "json", "card", "get", "1"}, stdout: []string{`"description": "Follow the system setting`}},
+		{args: []string{"batch", "run", script}, stderr: []string{"Ran 1 of 1 operation."}},
+		{args: []string{"-o", "json", "card", "get", "1"}, stdout: []string{`"description": ""`}},
+		{args: []string{"undo"}},
+		{args: []string{"card", "update", "1", "--edit"}},
+		{args: []string{"-o", "json", "card", "get", "1"}, stdout: []string{`"title": "Add dark mode"`, `"description": ""`}},
+		// Undoing a description added to an empty one clears it again.
+		{args: []string{"card", "update", "3", "--description", "Press ? for help."}},
+		{args: []string{"undo"}, stdout: []string{"restored: description"}},
+		{args: []string{"-o", "json", "card", "get", "3"}, stdout: []string{`"description": ""`}},
+	})
+}
+
+func TestOutputFlags(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		{args: []string{"--jq", ".[] | .title", "card", "list", "--board-id", "Roadmap"}, stdout: []string{"Add dark mode\n"}},
+		{args: []string{"--jq", ".board.name", "card", "get", "1"}, stdout: []string{"Roadmap\n"}},
+		{args: []string{"--jq", "title", "card", "get", "1"}, code: 2, stderr: []string{"must start with '.'"}},
+		{args: []string{"--format", `#{{.Number}} {{.Title | upper}} on {{.Board.Name}}`, "card", "get", "1"}, stdout: []string{"#1 ADD DARK MODE on Roadmap\n"}},
+		{args: []string{"-o", "json", "--format", "{{.Title}}", "card", "get", "1"}, code: 2, stderr: []string{"--format cannot be used with --output json"}},
+		{args: []string{"--fields", "number,board.name", "card", "get", "1"}, stdout: []string{"NUMBER  BOARD_NAME", "1       Roadmap"}},
+		{args: []string{"-o", "json", "--fields", "number,title", "card", "get", "1"}, stdout: []string{`"number": 1,`, `"title": "Add dark mode"`}},
+		{args: []string{"--fields", "number", "--jq", ".number", "card", "get", "1"}, code: 2, stderr: []string{"cannot be used together"}},
+	})
+}
+
+func TestOutputFormats(t *testing.T) {
+	startFake(t)
+	title := `Fix "login", then | logout`
+	if r := run(t, "", "card", "create", "--board-id", "Roadmap", "--title", title); r.code != 0 {
+		t.Fatalf("card create: exit %d: %s", r.code, r.stderr)
+	}
+
+	r := run(t, "", "-o", "csv", "--fields", "number,title,tags", "card", "list", "--board-id", "Roadmap")
+	records, err := csv.NewReader(strings.NewReader(r.stdout)).ReadAll()
+	if err != nil || r.code != 0 {
+		t.Fatalf("csv: exit %d, %v:\n%s", r.code, err, r.stdout)
+	}
+	if len(records) != 5 || strings.Join(records[0], "|") != "NUMBER|TITLE|TAGS" || records[1][1] != title || strings.Join(records[3], "|") != "2|Crash when a column is deleted|bug" {
+		t.Errorf("csv records = %q", records)
+	}
+
+	runSteps(t, []step{
+		{args: []string{"-o", "tsv", "card", "list", "--board-id", "Roadmap"}, stdout: []string{
+			"#\tTITLE\tSTATUS\tBOARD\tLAST_ACTIVE\n",
+			"\n7\t\"Fix \"\"login\"\", then | logout\"\tpublished\tRoadmap\t",
+			"\n1\tAdd dark mode\tpublished\tRoadmap\t",
+		}},
+		{args: []string{"-o", "yaml", "card", "get", "7"}, stdout: []string{
+			"\nnumber: 7\n",
+			"\ntitle: Fix \"login\", then | logout\n",
+			"\ndescription: \"\"\n",
+			"\ntags: []\n",
+			"\nboard:\n  id: ",
+			"\n  name: Roadmap\n",
+		}},
+		{args: []string{"-o", "yaml", "card", "list", "--board-id", "Roadmap"}, stdout: []string{"- id: ", "\n  tags:\n    - feature\n    - design\n"}},
+		{args: []string{"-o", "markdown", "card", "list", "--board-id", "Roadmap"}, stdout: []string{
+			"| # | TITLE | STATUS | BOARD | LAST_ACTIVE |\n| --- | --- | --- | --- | --- |\n",
+			"| 7 | Fix \"login\", then \\| logout | published | Roadmap |",
+		}},
+	})
+
+	r = run(t, "", "-o", "ndjson", "card", "list", "--board-id", "Roadmap")
+	lines := strings.Split(strings.TrimSuffix(r.stdout, "\n"), "\n")
+	if r.code != 0 || len(lines) != 4 {
+		t.Fatalf("ndjson: exit %d, %d lines:\n%s", r.code, len(lines), r.stdout)
+	}
+	for i, line := range lines {
+		var card struct {
+			Number int    `json:"number"`
+			Title  string `json:"title"`
+		}
+		if err := json.Unmarshal([]byte(line), &card); err != nil {
+			t.Fatalf("ndjson line %d: %v", i+1, err)
+		}
+		if i == 0 && (card.Number != 7 || card.Title != title) {
+			t.Errorf("ndjson line 1 = %+v", card)
+		}
+	}
+}
+
+func TestHistoryAndUndo(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		{args: []string{"card", "update", "3", "--title", "Shortcuts"}},
+		{args: []string{"--plain", "history", "list"}, stdout: []string{"card update  #3"}},
+		{args: []string{"undo"}},
+		{args: []string{"card", "get", "3"}, stdout: []string{"Keyboard shortcuts for triage"}},
+		{args: []string{"undo", "999"}, code: 1},
+		{args: []string{"card", "update", "3", "--status", "drafted", "--title", "Shortcuts"}},
+	})
+
+	// A snapshot without a status cannot restore it.
+	path := filepath.Join(filepath.Dir(os.Getenv("FIZZY_CONFIG")), "journal", "000002.json")
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(readFile(t, path)), &entry); err != nil {
+		t.Fatal(err)
+	}
+	delete(entry["before"].(map[string]any), "status")
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Dir(path), filepath.Base(path), string(data))
+	r := run(t, "", "-o", "json", "undo")
+	if r.code != 0 {
+		t.Fatalf("undo: exit %d: %s", r.code, r.stderr)
+	}
+	var out struct {
+		Restored []string `json:"restored"`
+		Lost     []string `json:"not_restored"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &out); err != nil {
+		t.Fatalf("undo output: %v\n%s", err, r.stdout)
+	}
+	if strings.Join(out.Restored, ",") != "title" || strings.Join(out.Lost, ",") != "status: the previous status is not known" {
+		t.Errorf("restored %q, lost %q", out.Restored, out.Lost)
+	}
+}
+
+func TestUndoToggles(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		// Closing a closed card changes nothing.
+		{args: []string{"card", "close", "5"}},
+		{args: []string{"undo"}, stdout: []string{`Nothing to undo for 1: card close #5 "Set up the project"; the card was closed already.`}},
+		{args: []string{"-o", "json", "card", "get", "5"}, stdout: []string{`"closed": true`}},
+		// The second tag toggles the first one back.
+		{args: []string{"card", "tag", "3", "--title", "bug"}},
+		{args: []string{"card", "tag", "3", "--title", "#bug"}},
+		{args: []string{"undo", "2"}, stdout: []string{"Nothing to undo for 2", "the card does not have tag bug, as before"}},
+		{args: []string{"undo"}, stdout: []string{"Undid 3", "added tag bug back"}},
+		{args: []string{"-o", "json", "card", "get", "3"}, stdout: []string{`"bug"`}},
+		{args: []string{"card", "assign", "1", "--assignee-id", "jane@example.com"}},
+		{args: []string{"-o", "json", "undo"}, stdout: []string{`"restored": [`, `"assigned Jane Doe again"`}},
+		{args: []string{"card", "reopen", "1"}},
+		{args: []string{"-o", "json", "undo"}, stdout: []string{`"nothing_to_undo": "the card was open already"`}},
+		{args: []string{"undo"}, code: 1, stderr: []string{"nothing to undo"}},
+	})
+}
+
+func TestBatch(t *testing.T) {
+	startFake(t)
+	dir := t.TempDir()
+	script := writeFile(t, dir, "ops.jsonl", `{"op": "card.create", "ref": "c", "board": "Roadmap", "title": "From batch", "steps": ["One"]}
+{"op": "comment.create", "card": "${c.number}", "body": "Batched"}
+{"op": "card.close", "card": "${c.number}"}
+`)
+	yamlScript := writeFile(t, dir, "ops.yaml", `# The same operations as YAML.
+- op: card.create
+  ref: c
+  board: Roadmap
+  title: "From YAML: a card"
+  tags: [bug]
+  steps:
+    - One
+    - Two
+- op: comment.create
+  card: ${c.number}
+  body: |
+    Batched
+    from YAML
+`)
+	bad := writeFile(t, dir, "bad.jsonl", `{"op": "card.create", "board": "Roadmap"}
+`)
+	runSteps(t, []step{
+		{args: []string{"batch", "run", script, "--dry-run"}, stderr: []string{"not sent (dry run)"}},
+		{args: []string{"batch", "run", script}, stderr: []string{"Ran 3 of 3 operations."}},
+		{args: []string{"-o", "json", "card", "get", "7"}, stdout: []string{`"From batch"`, `"closed": true`}},
+		{args: []string{"batch", "run", yamlScript}, stderr: []string{"Ran 2 of 2 operations."}},
+		{args: []string{"-o", "json", "card", "get", "8"}, stdout: []string{`"From YAML: a card"`, `"bug"`, `"Two"`}},
+		{args: []string{"--plain", "comment", "list", "8"}, stdout: []string{"Batched"}},
+		{args: []string{"batch", "run", bad}, code: 2, stderr: []string{"title"}},
+	})
+}
+
+func TestBatchResumesCardSteps(t *testing.T) {
+	// The second step fails once, after the card and its first step exist.
+	failed := false
+	startFakeWith(t, func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/steps") && !failed {
+				body, _ := io.ReadAll(r.Body)
+				if strings.Contains(string(body), "Two") {
+					failed = true
+					http.Error(w, `{"error": "unavailable"}`, http.StatusUnprocessableEntity)
+					return
+				}
+				r.Body = io.NopCloser(strings.NewReader(string(body)))
+			}
+			h.ServeHTTP(w, r)
+		})
+	})
+	script := writeFile(t, t.TempDir(), "ops.jsonl", `{"op": "card.create", "ref": "c", "board": "Roadmap", "title": "Partly", "steps": ["One", "Two", "Three"]}
+{"op": "comment.create", "card": "${c.number}", "body": "Done"}
+`)
+	runSteps(t, []step{
+		{args: []string{"batch", "run", script}, code: 6, stdout: []string{"card 7 created, but adding step 2 of 3"}, stderr: []string{"Ran 0 of 2 operations. 1 failed. 1 not run."}},
+		{args: []string{"batch", "run", script}, stderr: []string{"Ran 2 of 2 operations."}},
+		{args: []string{"card", "get", "8"}, code: 5},
+	})
+	r := run(t, "", "-o", "json", "step", "list", "7")
+	steps := decodeJSON[[]struct {
+		Content string `json:"content"`
+	}](t, r)
+	var contents []string
+	for _, s := range steps {
+		contents = append(contents, s.Content)
+	}
+	if got := strings.Join(contents, ","); got != "One,Two,Three" {
+		t.Errorf("card #7 steps = %s, want One,Two,Three", got)
+	}
+	if r := run(t, "", "comment", "list", "7"); !strings.Contains(r.stdout, "Done") {
+		t.Errorf("comment not added to card #7:\n%s", r.stdout)
+	}
+}
+
+func TestBoardExportImport(t *testing.T) {
+	startFake(t)
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "roadmap.zip")
+	runSteps(t, []step{
+		{args: []string{"board", "export", "Roadmap", "-o", archive}, stdout: []string{`Exported board "Roadmap": 3 columns, 5 cards, 3 comments`}},
+		{args: []string{"board", "import", archive, "--new-name", "Restored", "--dry-run"}, stderr: []string{"Nothing was changed."}},
+		{args: []string{"board", "import", archive, "--new-name", "Restored"}, stderr: []string{`Imported "Roadmap": 15 created, 10 updated.`}},
+		{args: []string{"board", "import", archive}, stderr: []string{"15 exists"}},
+		{args: []string{"board", "import", archive, "--into", "Roadmap", "--new-name", "X"}, code: 2},
+	})
+	if got, want := exportedCards(t, "Restored"), exportedCards(t, "Roadmap"); got != want {
+		t.Errorf("restored cards = %s, want %s", got, want)
+	}
+}
+
+// exportedCards summarizes the cards of a board export as title:state
+// pairs, in card order.
+func exportedCards(t *testing.T, board string) string {
+	t.Helper()
+	doc := decodeJSON[struct {
+		Cards []struct {
+			State string `json:"state"`
+			Card  struct {
+				Title string `json:"title"`
+			} `json:"card"`
+		} `json:"cards"`
+	}](t, run(t, "", "board", "export", board))
+	var cards []string
+	for _, c := range doc.Cards {
+		cards = append(cards, c.Card.Title+":"+c.State)
+	}
+	return strings.Join(cards, ", ")
+}
+
+func TestDryRun(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		{args: []string{"--dry-run", "card", "get", "1"}, stdout: []string{"Add dark mode"}},
+		{args: []string{"--dry-run", "card", "close", "1"}, code: 10, stderr: []string{"dry run: POST", "/cards/1/closure not sent"}},
+		{args: []string{"--dry-run", "-o", "json", "board", "create", "--name", "X"}, code: 10, stderr: []string{`"error":"dry_run"`, `"exit_code":10`}},
+		{args: []string{"-o", "json", "card", "get", "1"}, stdout: []string{`"closed": false`}},
+		{args: []string{"--dry-run", "card", "bulk", "close", "--yes"}, stdin: "1\n", stdout: []string{"dry run"}},
+	})
+}
+
+func TestListErrorPartWay(t *testing.T) {
+	fake := startFakeWith(t, func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/cards") && r.URL.Query().Get("page") == "2" {
+				http.Error(w, `{"error": "not found"}`, http.StatusNotFound)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	})
+	fake.PageSize = 2
+
+	r := run(t, "", "-o", "json", "card", "list", "--all")
+	if r.code != 5 {
+		t.Fatalf("exit %d, want 5\nstderr: %s", r.code, r.stderr)
+	}
+	cards := decodeJSON[[]struct {
+		Number int `json:"number"`
+	}](t, r)
+	if len(cards) != 2 {
+		t.Errorf("printed %d cards before the error, want 2", len(cards))
+	}
+	if !strings.Contains(r.stderr, `"status":404`) {
+		t.Errorf("stderr does not report the error:\n%s", r.stderr)
+	}
+
+	r = run(t, "", "card", "list", "--all")
+	if r.code != 5 || strings.Count(r.stdout, "\n") != 3 {
+		t.Errorf("table: exit %d, want 5 after a header and 2 rows:\n%s", r.code, r.stdout)
+	}
+}
+
+func TestLongTableStreams(t *testing.T) {
+	fake := startFake(t)
+	board := fake.AddBoard("Backlog")
+	for i := 1; i <= 250; i++ {
+		fake.AddCard(board, fmt.Sprintf("Card %d", i))
+	}
+	r := run(t, "", "card", "list", "--board-id", "Backlog", "--all")
+	if r.code != 0 {
+		t.Fatalf("exit %d: %s", r.code, r.stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(r.stdout, "\n"), "\n")
+	if len(lines) != 251 || strings.Count(r.stdout, "TITLE") != 1 {
+		t.Fatalf("got %d lines, want one header and 250 rows:\n%s", len(lines), r.stdout)
+	}
+	// Later pages keep the columns of the first.
+	title := strings.Index(lines[0], "TITLE")
+	for _, line := range []string{lines[1], lines[150], lines[250]} {
+		if !strings.HasPrefix(line[title:], "Card ") {
+			t.Errorf("title column misaligned in %q", line)
+		}
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	startFake(t)
+	runSteps(t, []step{
+		{args: []string{"completion", "bash"}, stdout: []string{"complete"}},
+		{args: []string{"__complete", "card", "bulk", ""}, stdout: []string{"close", "tag"}},
+		{args: []string{"completion", "tcsh"}, code: 2},
+	})
+}
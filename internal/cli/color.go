@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SGR attributes used in table output.
+const (
+	sgrBold   = "1"
+	sgrDim    = "2"
+	sgrRed    = "31"
+	sgrGreen  = "32"
+	sgrYellow = "33"
+	sgrBlue   = "34"
+	sgrPurple = "35"
+	sgrCyan   = "36"
+	sgrGray   = "90"
+)
+
+// colorEnabled reports whether output to stdout should be colored: never
+// with --no-color or NO_COLOR, and only when stdout is a terminal.
+func colorEnabled(noColor bool) bool {
+	if noColor {
+		return false
+	}
+	if _, set := os.LookupEnv("NO_COLOR"); set {
+		return false
+	}
+	return isTTY(os.Stdout)
+}
+
+// paint wraps s in the given SGR attributes, e.g. paint("x", sgrBold, sgrRed).
+func paint(s string, attrs ...string) string {
+	if s == "" || len(attrs) == 0 {
+		return s
+	}
+	codes := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		if a != "" {
+			codes = append(codes, a)
+		}
+	}
+	if len(codes) == 0 {
+		return s
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + s + "\x1b[0m"
+}
+
+func cardStatusColor(status string) string {
+	switch status {
+	case "published":
+		return sgrGreen
+	case "drafted":
+		return sgrYellow
+	case "closed":
+		return sgrGray
+	}
+	return ""
+}
+
+// columnColorNames maps the names Fizzy uses for column colors, either
+// plain or as CSS variables like var(--color-card-3), to terminal colors.
+// Longer names come first, so that a value holding several, such as
+// "blue-green", always picks the same one.
+var columnColorNames = []struct{ name, sgr string }{
+	{"purple", sgrPurple},
+	{"violet", sgrPurple},
+	{"yellow", sgrYellow},
+	{"orange", sgrYellow},
+	{"green", sgrGreen},
+	{"blue", sgrBlue},
+	{"pink", sgrPurple},
+	{"cyan", sgrCyan},
+	{"teal", sgrCyan},
+	{"gray", sgrGray},
+	{"grey", sgrGray},
+	{"red", sgrRed},
+}
+
+var columnColorPalette = []string{sgrBlue, sgrGreen, sgrYellow, sgrRed, sgrPurple, sgrCyan, sgrGray}
+
+// columnColor returns the SGR attribute closest to a column's color. Hex
+// colors are rendered exactly with 24-bit color.
+func columnColor(color string) string {
+	c := strings.ToLower(strings.TrimSpace(color))
+	if strings.HasPrefix(c, "#") {
+		return hexColor(c[1:])
+	}
+	c = strings.TrimSuffix(strings.TrimPrefix(c, "var("), ")")
+	c = strings.TrimPrefix(c, "--color-")
+	for _, color := range columnColorNames {
+		if strings.Contains(c, color.name) {
+			return color.sgr
+		}
+	}
+	if i := strings.LastIndexAny(c, "-_"); i >= 0 {
+		if n, err := strconv.Atoi(c[i+1:]); err == nil && n > 0 {
+			return columnColorPalette[(n-1)%len(columnColorPalette)]
+		}
+	}
+	return ""
+}
+
+func hexColor(hex string) string {
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return ""
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("38;2;%d;%d;%d", v>>16, v>>8&0xff, v&0xff)
+}
@@ -0,0 +1,47 @@
+package cli
+
+import "testing"
+
+func TestColumnColor(t *testing.T) {
+	tests := []struct{ color, want string }{
+		{"var(--color-card-1)", sgrBlue},
+		{"var(--color-card-3)", sgrYellow},
+		{"var(--color-card-8)", sgrBlue},
+		{" VAR(--color-card-7) ", sgrGray},
+		{"var(--color-red)", sgrRed},
+		{"var(--color-light-teal)", sgrCyan},
+		{"Grey", sgrGray},
+		{"blue-green", sgrGreen},
+		{"red-violet", sgrPurple},
+		{"pinkish_red", sgrPurple},
+		{"#ff8000", "38;2;255;128;0"},
+		{"#0F0", "38;2;0;255;0"},
+		{"#12345", ""},
+		{"#zzzzzz", ""},
+		{"card_2", sgrGreen},
+		{"card-0", ""},
+		{"", ""},
+		{"mauve", ""},
+	}
+	for _, tt := range tests {
+		// Repeat to catch an answer that depends on iteration order.
+		for i := 0; i < 20; i++ {
+			if got := columnColor(tt.color); got != tt.want {
+				t.Errorf("columnColor(%q) = %q, want %q", tt.color, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestPaint(t *testing.T) {
+	if got := paint("x", sgrBold, "", sgrRed); got != "\x1b[1;31mx\x1b[0m" {
+		t.Errorf("paint = %q", got)
+	}
+	if got := paint("x", ""); got != "x" {
+		t.Errorf("paint without attributes = %q", got)
+	}
+	if got := paint("", sgrRed); got != "" {
+		t.Errorf("paint of empty text = %q", got)
+	}
+}
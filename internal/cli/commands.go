@@ -2,20 +2,20 @@ package cli
 
 import (
 	"bufio"
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"mime/multipart"
-	"net/url"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 
-	"fizzy-cli/internal/api"
 	"fizzy-cli/internal/config"
+	"fizzy-cli/internal/credstore"
+	"fizzy-cli/internal/journal"
+	"fizzy-cli/pkg/fizzy"
 )
 
 func runAuth(ctx Context, args []string) int {
@@ -37,7 +37,12 @@ func runAuth(ctx Context, args []string) int {
 			return handleErr(helpForAuth(), UsageError{Msg: "--token and --email cannot be used together"})
 		}
 		if strings.TrimSpace(*email) != "" {
-			return authMagicLink(ctx, strings.TrimSpace(*email), strings.TrimSpace(*code))
+			_, location, err := authMagicLink(ctx, strings.TrimSpace(*email), strings.TrimSpace(*code))
+			if err != nil {
+				return handleErr(helpForAuth(), err)
+			}
+			fmt.Fprintf(os.Stdout, "Session saved to %s (profile %s)\n", location, ctx.ProfileName)
+			return 0
 		}
 		val := strings.TrimSpace(*token)
 		if val == "" {
@@ -50,19 +55,14 @@ func runAuth(ctx Context, args []string) int {
 		if val == "" {
 			return handleErr(helpForAuth(), UsageError{Msg: "token is required"})
 		}
-		cfg := ctx.Config
-		cfg.Token = val
-		cfg.SessionToken = ""
-		if err := configSave(ctx.ConfigPath, cfg); err != nil {
+		location, err := saveCredentials(ctx, credstore.Credentials{Token: val})
+		if err != nil {
 			return handleErr(helpForAuth(), err)
 		}
-		fmt.Fprintf(os.Stdout, "Token saved to %s\n", ctx.ConfigPath)
+		fmt.Fprintf(os.Stdout, "Token saved to %s (profile %s)\n", location, ctx.ProfileName)
 		return 0
 	case "logout":
-		cfg := ctx.Config
-		cfg.Token = ""
-		cfg.SessionToken = ""
-		if err := configSave(ctx.ConfigPath, cfg); err != nil {
+		if _, err := saveCredentials(ctx, credstore.Credentials{}); err != nil {
 			return handleErr(helpForAuth(), err)
 		}
 		fmt.Fprintln(os.Stdout, "Credentials cleared.")
@@ -75,91 +75,87 @@ func runAuth(ctx Context, args []string) int {
 		if err := ensureToken(ctx); err != nil {
 			return handleErr(helpForAuth(), err)
 		}
-		resp, err := ctx.Client.Do(requestContext(), "GET", "/my/identity", nil, nil, "", nil)
+		// Check the credentials as they are, without offering to log in.
+		transport := newTransport(ctx, ctx.Token, ctx.SessionToken)
+		transport.Unauthorized = unauthorizedHandler(transport, nil)
+		identity, resp, err := fizzy.NewClient(transport, ctx.Account).Identity.Get(requestContext())
 		if err != nil {
 			return handleErr(helpForAuth(), err)
 		}
-		if ctx.Output.JSON {
-			return printJSONResponse(resp)
+		if ctx.Account != "" && !hasAccount(identity.Accounts, ctx.Account) {
+			fmt.Fprintf(os.Stderr, "warning: account %s is not accessible with these credentials\n", ctx.Account)
+		}
+		if identityDocument(ctx.Output) {
+			return outputResponse(ctx, resp)
 		}
 		authType := "session token"
 		if ctx.Token != "" {
 			authType = "personal access token"
 		}
-		fmt.Fprintf(os.Stdout, "Authenticated using %s. Accessible accounts:\n", authType)
-		rows, err := identityToRows(resp.Body)
-		if err != nil {
-			return handleErr(helpForAuth(), err)
+		if ctx.Output.Tabular() && ctx.Output.Template == nil {
+			fmt.Fprintf(os.Stdout, "Authenticated using %s. Accessible accounts:\n", authType)
 		}
-		printTable(os.Stdout, []string{"SLUG", "NAME", "USER"}, rows, ctx.Output.Plain)
-		return 0
+		return outputList(ctx, helpForAuth(), identity.Accounts, rawItems(resp.Body, "accounts"), accountView)
 	default:
 		fmt.Fprint(os.Stderr, helpForAuth())
 		return 2
 	}
 }
 
-type pendingAuthResponse struct {
-	PendingAuthenticationToken string `json:"pending_authentication_token"`
+func hasAccount(accounts []fizzy.Account, slug string) bool {
+	for _, a := range accounts {
+		if normalizeAccount(a.Slug) == slug {
+			return true
+		}
+	}
+	return false
 }
 
-type sessionAuthResponse struct {
-	SessionToken string `json:"session_token"`
+// identityDocument reports whether account listings print the identity
+// response as a whole. Other formats list the accounts it contains.
+func identityDocument(mode OutputMode) bool {
+	if mode.JQ != nil {
+		return true
+	}
+	return len(mode.Fields) == 0 && (mode.Format == FormatJSON || mode.Format == FormatYAML)
 }
 
-func authMagicLink(ctx Context, email, code string) int {
+// authMagicLink signs in with a magic-link code, prompting for the code
+// when it is empty, and saves the session. It returns the session token
+// and where it was saved.
+func authMagicLink(ctx Context, email, code string) (string, string, error) {
 	if strings.TrimSpace(email) == "" {
-		return handleErr(helpForAuth(), UsageError{Msg: "--email is required"})
+		return "", "", UsageError{Msg: "--email is required"}
 	}
-	client := api.NewClient(ctx.BaseURL, "", "", fmt.Sprintf("fizzy-cli/%s", ctx.Version))
-	request := map[string]any{"email_address": email}
-	resp, err := client.Do(requestContext(), "POST", "/session", nil, bytes.NewBuffer(mustJSON(request)), "application/json", nil)
+	client := fizzy.NewClient(newTransport(ctx, "", ""), "")
+	pending, _, err := client.Sessions.Create(requestContext(), email)
 	if err != nil {
-		return handleErr(helpForAuth(), err)
-	}
-	var pending pendingAuthResponse
-	if err := json.Unmarshal(resp.Body, &pending); err != nil {
-		return handleErr(helpForAuth(), err)
-	}
-	if strings.TrimSpace(pending.PendingAuthenticationToken) == "" {
-		return handleErr(helpForAuth(), errors.New("missing pending_authentication_token in response"))
+		return "", "", err
 	}
 
 	if strings.TrimSpace(code) == "" {
 		if !isTTY(os.Stdin) {
-			return handleErr(helpForAuth(), UsageError{Msg: "--code is required when not running in a TTY"})
+			return "", "", UsageError{Msg: "--code is required when not running in a TTY"}
 		}
 		readCode, err := readSecret("Magic link code")
 		if err != nil {
-			return handleErr(helpForAuth(), err)
+			return "", "", err
 		}
 		code = readCode
 	}
 	if strings.TrimSpace(code) == "" {
-		return handleErr(helpForAuth(), UsageError{Msg: "magic link code is required"})
+		return "", "", UsageError{Msg: "magic link code is required"}
 	}
 
-	verifyRequest := map[string]any{"code": strings.TrimSpace(code)}
-	headers := map[string]string{"Cookie": "pending_authentication_token=" + pending.PendingAuthenticationToken}
-	verifyResp, err := client.Do(requestContext(), "POST", "/session/magic_link", nil, bytes.NewBuffer(mustJSON(verifyRequest)), "application/json", headers)
+	session, _, err := client.Sessions.Verify(requestContext(), pending, code)
 	if err != nil {
-		return handleErr(helpForAuth(), err)
-	}
-	var session sessionAuthResponse
-	if err := json.Unmarshal(verifyResp.Body, &session); err != nil {
-		return handleErr(helpForAuth(), err)
-	}
-	if strings.TrimSpace(session.SessionToken) == "" {
-		return handleErr(helpForAuth(), errors.New("missing session_token in response"))
+		return "", "", err
 	}
-	cfg := ctx.Config
-	cfg.SessionToken = session.SessionToken
-	cfg.Token = ""
-	if err := configSave(ctx.ConfigPath, cfg); err != nil {
-		return handleErr(helpForAuth(), err)
+	location, err := saveCredentials(ctx, credstore.Credentials{SessionToken: session.Token})
+	if err != nil {
+		return "", "", err
 	}
-	fmt.Fprintf(os.Stdout, "Session saved to %s\n", ctx.ConfigPath)
-	return 0
+	return session.Token, location, nil
 }
 
 func runAccount(ctx Context, args []string) int {
@@ -172,19 +168,14 @@ func runAccount(ctx Context, args []string) int {
 		if err := ensureToken(ctx); err != nil {
 			return handleErr(helpForAccount(), err)
 		}
-		resp, err := ctx.Client.Do(requestContext(), "GET", "/my/identity", nil, nil, "", nil)
+		identity, resp, err := ctx.Client.Identity.Get(requestContext())
 		if err != nil {
 			return handleErr(helpForAccount(), err)
 		}
-		if ctx.Output.JSON {
-			return printJSONResponse(resp)
-		}
-		rows, err := identityToRows(resp.Body)
-		if err != nil {
-			return handleErr(helpForAccount(), err)
+		if identityDocument(ctx.Output) {
+			return outputResponse(ctx, resp)
 		}
-		printTable(os.Stdout, []string{"SLUG", "NAME", "USER"}, rows, ctx.Output.Plain)
-		return 0
+		return outputList(ctx, helpForAccount(), identity.Accounts, rawItems(resp.Body, "accounts"), accountView)
 	case "set":
 		if len(args) < 2 {
 			return handleErr(helpForAccount(), UsageError{Msg: "account slug is required"})
@@ -193,9 +184,9 @@ func runAccount(ctx Context, args []string) int {
 		if slug == "" {
 			return handleErr(helpForAccount(), UsageError{Msg: "account slug is required"})
 		}
-		cfg := ctx.Config
-		cfg.Account = slug
-		if err := configSave(ctx.ConfigPath, cfg); err != nil {
+		profile := ctx.Profile
+		profile.Account = slug
+		if err := saveProfile(ctx, profile); err != nil {
 			return handleErr(helpForAccount(), err)
 		}
 		fmt.Fprintf(os.Stdout, "Default account set to %s\n", slug)
@@ -213,46 +204,92 @@ func runConfig(ctx Context, args []string) int {
 	}
 	switch args[0] {
 	case "show":
-		if ctx.Output.JSON {
+		store, err := credentialStore(ctx, ctx.Profile)
+		if err != nil {
+			return handleErr(helpForConfig(), err)
+		}
+		// Credentials outside the config file are not read here so that
+		// showing the config never asks for a passphrase.
+		inFile := store.Name() == credstore.BackendFile
+		if !ctx.Output.Tabular() {
 			payload := map[string]any{
-				"base_url":          firstNonEmpty(ctx.Config.BaseURL, ctx.BaseURL),
-				"account":           ctx.Config.Account,
-				"token_set":         ctx.Config.Token != "",
-				"session_token_set": ctx.Config.SessionToken != "",
-				"config_path":       ctx.ConfigPath,
+				"profile":                   ctx.ProfileName,
+				"base_url":                  firstNonEmpty(ctx.Profile.BaseURL, ctx.BaseURL),
+				"account":                   ctx.Profile.Account,
+				"credential_store":          store.Name(),
+				"credential_store_location": store.Location(),
+				"config_path":               ctx.ConfigPath,
+				"max_attempts":              ctx.Retry.MaxAttempts,
+				"retry_timeout":             ctx.Retry.Timeout.String(),
+				"cache_ttl":                 ctx.CacheTTL.String(),
 			}
-			if err := printJSON(os.Stdout, payload); err != nil {
-				return handleErr(helpForConfig(), err)
+			if inFile {
+				payload["token_set"] = ctx.Profile.Token != ""
+				payload["session_token_set"] = ctx.Profile.SessionToken != ""
 			}
-			return 0
+			return outputPayload(ctx, payload)
 		}
 		fmt.Fprintf(os.Stdout, "Config path: %s\n", ctx.ConfigPath)
-		fmt.Fprintf(os.Stdout, "Base URL: %s\n", firstNonEmpty(ctx.Config.BaseURL, ctx.BaseURL))
-		if ctx.Config.Account != "" {
-			fmt.Fprintf(os.Stdout, "Account: %s\n", ctx.Config.Account)
-		}
-		fmt.Fprintf(os.Stdout, "Token set: %t\n", ctx.Config.Token != "")
-		fmt.Fprintf(os.Stdout, "Session token set: %t\n", ctx.Config.SessionToken != "")
+		fmt.Fprintf(os.Stdout, "Profile: %s\n", ctx.ProfileName)
+		fmt.Fprintf(os.Stdout, "Base URL: %s\n", firstNonEmpty(ctx.Profile.BaseURL, ctx.BaseURL))
+		if ctx.Profile.Account != "" {
+			fmt.Fprintf(os.Stdout, "Account: %s\n", ctx.Profile.Account)
+		}
+		fmt.Fprintf(os.Stdout, "Credential store: %s (%s)\n", store.Name(), store.Location())
+		if inFile {
+			fmt.Fprintf(os.Stdout, "Token set: %t\n", ctx.Profile.Token != "")
+			fmt.Fprintf(os.Stdout, "Session token set: %t\n", ctx.Profile.SessionToken != "")
+		}
+		fmt.Fprintf(os.Stdout, "Max attempts: %d\n", ctx.Retry.MaxAttempts)
+		fmt.Fprintf(os.Stdout, "Retry timeout: %s\n", ctx.Retry.Timeout)
+		fmt.Fprintf(os.Stdout, "Cache TTL: %s\n", ctx.CacheTTL)
 		return 0
 	case "set":
 		fs := flag.NewFlagSet("config set", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
 		baseURL := fs.String("base-url", "", "API base URL")
 		account := fs.String("account", "", "Account slug")
+		maxAttempts := fs.Int("max-attempts", 0, "Maximum attempts per request")
+		retryTimeout := fs.Duration("retry-timeout", 0, "Total time budget for retries")
+		cacheTTL := fs.String("cache-ttl", "", "How long name lookups are cached")
+		credentialSpec := fs.String("credential-store", "", "Where credentials are kept")
 		if err := fs.Parse(args[1:]); err != nil {
 			return usageError(helpForConfig(), err)
 		}
-		if strings.TrimSpace(*baseURL) == "" && strings.TrimSpace(*account) == "" {
-			return handleErr(helpForConfig(), UsageError{Msg: "at least one of --base-url or --account is required"})
+		if strings.TrimSpace(*baseURL) == "" && strings.TrimSpace(*account) == "" && *maxAttempts == 0 && *retryTimeout == 0 && strings.TrimSpace(*cacheTTL) == "" && strings.TrimSpace(*credentialSpec) == "" {
+			return handleErr(helpForConfig(), UsageError{Msg: "at least one of --base-url, --account, --max-attempts, --retry-timeout, --cache-ttl or --credential-store is required"})
+		}
+		if *maxAttempts < 0 || *retryTimeout < 0 {
+			return handleErr(helpForConfig(), UsageError{Msg: "--max-attempts and --retry-timeout must be positive"})
 		}
-		cfg := ctx.Config
+		profile := ctx.Profile
 		if strings.TrimSpace(*baseURL) != "" {
-			cfg.BaseURL = strings.TrimSpace(*baseURL)
+			profile.BaseURL = strings.TrimSpace(*baseURL)
 		}
 		if strings.TrimSpace(*account) != "" {
-			cfg.Account = normalizeAccount(*account)
+			profile.Account = normalizeAccount(*account)
+		}
+		if *maxAttempts > 0 {
+			profile.MaxAttempts = *maxAttempts
+		}
+		if *retryTimeout > 0 {
+			profile.RetryTimeout = retryTimeout.String()
+		}
+		if strings.TrimSpace(*cacheTTL) != "" {
+			ttl, err := durationSetting("cache TTL", 0, *cacheTTL)
+			if err != nil {
+				return handleErr(helpForConfig(), err)
+			}
+			profile.CacheTTL = ttl.String()
 		}
-		if err := configSave(ctx.ConfigPath, cfg); err != nil {
+		if spec := strings.TrimSpace(*credentialSpec); spec != "" {
+			if err := switchCredentialStore(ctx, profile, spec); err != nil {
+				return handleErr(helpForConfig(), err)
+			}
+			fmt.Fprintln(os.Stdout, "Config updated.")
+			return 0
+		}
+		if err := saveProfile(ctx, profile); err != nil {
 			return handleErr(helpForConfig(), err)
 		}
 		fmt.Fprintln(os.Stdout, "Config updated.")
@@ -275,21 +312,39 @@ func runBoard(ctx Context, args []string) int {
 		return handleErr(helpForBoard(), err)
 	}
 	switch args[0] {
+	case "view":
+		return runBoardView(ctx, args)
+	case "render":
+		return runBoardRender(ctx, args)
+	case "export":
+		return runBoardExport(ctx, args)
+	case "import":
+		return runBoardImport(ctx, args)
 	case "list":
-		resp, err := ctx.Client.Do(requestContext(), "GET", withAccount(ctx, "/boards"), nil, nil, "", nil)
+		fs := flag.NewFlagSet("board list", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		paging := addListFlags(fs)
+		if err := fs.Parse(args[1:]); err != nil {
+			return usageError(helpForBoard(), err)
+		}
+		opts, err := paging.options()
 		if err != nil {
 			return handleErr(helpForBoard(), err)
 		}
-		return outputListOrJSON(ctx, resp, boardListHeaders, boardListRows)
+		return outputIterator(ctx, helpForBoard(), ctx.Client.Boards.Iter(&opts), boardView)
 	case "get":
 		if len(args) < 2 {
 			return handleErr(helpForBoard(), UsageError{Msg: "board id is required"})
 		}
-		resp, err := ctx.Client.Do(requestContext(), "GET", withAccount(ctx, "/boards/"+args[1]), nil, nil, "", nil)
+		boardID, err := resolveBoard(ctx, args[1])
 		if err != nil {
 			return handleErr(helpForBoard(), err)
 		}
-		return outputJSONOrPretty(ctx, resp.Body, formatBoard)
+		board, resp, err := ctx.Client.Boards.Get(requestContext(), boardID)
+		if err != nil {
+			return handleErr(helpForBoard(), err)
+		}
+		return outputItem(ctx, resp, board, boardView)
 	case "create":
 		fs := flag.NewFlagSet("board create", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
@@ -303,19 +358,17 @@ func runBoard(ctx Context, args []string) int {
 		if strings.TrimSpace(*name) == "" {
 			return handleErr(helpForBoard(), UsageError{Msg: "--name is required"})
 		}
-		payload := map[string]any{
-			"board": map[string]any{
-				"name":       strings.TrimSpace(*name),
-				"all_access": *allAccess,
-			},
+		req := &fizzy.BoardCreateRequest{
+			Name:      strings.TrimSpace(*name),
+			AllAccess: *allAccess,
 		}
 		if *autoPostpone > 0 {
-			payload["board"].(map[string]any)["auto_postpone_period"] = *autoPostpone
+			req.AutoPostponePeriod = *autoPostpone
 		}
 		if strings.TrimSpace(*publicDesc) != "" {
-			payload["board"].(map[string]any)["public_description"] = *publicDesc
+			req.PublicDescription = *publicDesc
 		}
-		resp, err := ctx.Client.Do(requestContext(), "POST", withAccount(ctx, "/boards"), nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		resp, err := ctx.Client.Boards.Create(requestContext(), req)
 		if err != nil {
 			return handleErr(helpForBoard(), err)
 		}
@@ -339,30 +392,41 @@ func runBoard(ctx Context, args []string) int {
 		if *allAccess && *noAllAccess {
 			return handleErr(helpForBoard(), UsageError{Msg: "--all-access and --no-all-access cannot be used together"})
 		}
-		board := map[string]any{}
+		req := &fizzy.BoardUpdateRequest{}
+		changed := false
 		if strings.TrimSpace(*name) != "" {
-			board["name"] = strings.TrimSpace(*name)
+			req.Name = strings.TrimSpace(*name)
+			changed = true
 		}
-		if *allAccess {
-			board["all_access"] = true
-		}
-		if *noAllAccess {
-			board["all_access"] = false
+		if *allAccess || *noAllAccess {
+			value := *allAccess
+			req.AllAccess = &value
+			changed = true
 		}
 		if *autoPostpone > 0 {
-			board["auto_postpone_period"] = *autoPostpone
+			req.AutoPostponePeriod = *autoPostpone
+			changed = true
 		}
 		if strings.TrimSpace(*publicDesc) != "" {
-			board["public_description"] = *publicDesc
+			req.PublicDescription = *publicDesc
+			changed = true
 		}
 		if len(userIDs.values) > 0 {
-			board["user_ids"] = userIDs.values
+			ids, err := resolveEach(ctx, userIDs.Values(), resolveUser)
+			if err != nil {
+				return handleErr(helpForBoard(), err)
+			}
+			req.UserIDs = ids
+			changed = true
 		}
-		if len(board) == 0 {
+		if !changed {
 			return handleErr(helpForBoard(), UsageError{Msg: "no fields to update"})
 		}
-		payload := map[string]any{"board": board}
-		resp, err := ctx.Client.Do(requestContext(), "PUT", withAccount(ctx, "/boards/"+args[1]), nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		boardID, err := resolveBoard(ctx, args[1])
+		if err != nil {
+			return handleErr(helpForBoard(), err)
+		}
+		resp, err := ctx.Client.Boards.Update(requestContext(), boardID, req)
 		if err != nil {
 			return handleErr(helpForBoard(), err)
 		}
@@ -371,7 +435,21 @@ func runBoard(ctx Context, args []string) int {
 		if len(args) < 2 {
 			return handleErr(helpForBoard(), UsageError{Msg: "board id is required"})
 		}
-		resp, err := ctx.Client.Do(requestContext(), "DELETE", withAccount(ctx, "/boards/"+args[1]), nil, nil, "", nil)
+		fs := flag.NewFlagSet("board delete", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		yes := fs.Bool("yes", false, "Skip the confirmation prompt")
+		if err := fs.Parse(args[2:]); err != nil {
+			return usageError(helpForBoard(), err)
+		}
+		boardID, err := resolveBoard(ctx, args[1])
+		if err != nil {
+			return handleErr(helpForBoard(), err)
+		}
+		ctx.AssumeYes = ctx.AssumeYes || *yes
+		if err := confirmDestroy(ctx, "board delete", describeBoardDelete(ctx.Client, boardID)); err != nil {
+			return handleErr(helpForBoard(), err)
+		}
+		resp, err := ctx.Client.Boards.Delete(requestContext(), boardID)
 		if err != nil {
 			return handleErr(helpForBoard(), err)
 		}
@@ -397,53 +475,30 @@ func runCard(ctx Context, args []string) int {
 	case "list":
 		fs := flag.NewFlagSet("card list", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
-		boardIDs := multiString{}
-		tagIDs := multiString{}
-		assigneeIDs := multiString{}
-		creatorIDs := multiString{}
-		closerIDs := multiString{}
-		cardIDs := multiString{}
-		terms := multiString{}
-		indexedBy := fs.String("indexed-by", "", "Index filter")
-		sortedBy := fs.String("sorted-by", "", "Sort order")
-		assignmentStatus := fs.String("assignment-status", "", "Assignment status")
-		creation := fs.String("creation", "", "Creation date filter")
-		closure := fs.String("closure", "", "Closure date filter")
-		all := fs.Bool("all", false, "Fetch all pages")
-		fs.Var(&boardIDs, "board-id", "Board ID filter")
-		fs.Var(&tagIDs, "tag-id", "Tag ID filter")
-		fs.Var(&assigneeIDs, "assignee-id", "Assignee ID filter")
-		fs.Var(&creatorIDs, "creator-id", "Creator ID filter")
-		fs.Var(&closerIDs, "closer-id", "Closer ID filter")
-		fs.Var(&cardIDs, "card-id", "Card ID filter")
-		fs.Var(&terms, "term", "Search term")
+		filters := addCardFilters(fs)
+		paging := addListFlags(fs)
 		if err := fs.Parse(args[1:]); err != nil {
 			return usageError(helpForCard(), err)
 		}
-		query := url.Values{}
-		addListParam(query, "board_ids[]", boardIDs.values)
-		addListParam(query, "tag_ids[]", tagIDs.values)
-		addListParam(query, "assignee_ids[]", assigneeIDs.values)
-		addListParam(query, "creator_ids[]", creatorIDs.values)
-		addListParam(query, "closer_ids[]", closerIDs.values)
-		addListParam(query, "card_ids[]", cardIDs.values)
-		addListParam(query, "terms[]", terms.values)
-		setStringParam(query, "indexed_by", *indexedBy)
-		setStringParam(query, "sorted_by", *sortedBy)
-		setStringParam(query, "assignment_status", *assignmentStatus)
-		setStringParam(query, "creation", *creation)
-		setStringParam(query, "closure", *closure)
-
-		return listWithPagination(ctx, helpForCard(), withAccount(ctx, "/cards"), query, *all, cardListHeaders, cardListRows)
+		listOpts, err := paging.options()
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		opts, err := filters.options(ctx, listOpts)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		return outputIterator(ctx, helpForCard(), ctx.Client.Cards.Iter(opts), cardView)
 	case "get":
-		if len(args) < 2 {
-			return handleErr(helpForCard(), UsageError{Msg: "card number is required"})
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForCard(), err)
 		}
-		resp, err := ctx.Client.Do(requestContext(), "GET", withAccount(ctx, "/cards/"+args[1]), nil, nil, "", nil)
+		card, resp, err := ctx.Client.Cards.Get(requestContext(), number)
 		if err != nil {
 			return handleErr(helpForCard(), err)
 		}
-		return outputJSONOrPretty(ctx, resp.Body, formatCard)
+		return outputItem(ctx, resp, card, cardView)
 	case "create":
 		fs := flag.NewFlagSet("card create", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
@@ -454,57 +509,72 @@ func runCard(ctx Context, args []string) int {
 		imagePath := fs.String("image", "", "Image file path")
 		tagIDs := multiString{}
 		fs.Var(&tagIDs, "tag-id", "Tag ID (repeatable)")
+		stepTexts := multiString{}
+		fs.Var(&stepTexts, "step", "Step text (repeatable)")
+		stepsFile := fs.String("steps-file", "", "Markdown checklist of steps, - for stdin")
+		descriptionFile := fs.String("description-file", "", "Read the description from a file, - for stdin")
+		edit := fs.Bool("edit", false, "Write the title and description in $EDITOR")
 		if err := fs.Parse(args[1:]); err != nil {
 			return usageError(helpForCard(), err)
 		}
-		if strings.TrimSpace(*boardID) == "" || strings.TrimSpace(*title) == "" {
+		if strings.TrimSpace(*boardID) == "" || (strings.TrimSpace(*title) == "" && !*edit) {
 			return handleErr(helpForCard(), UsageError{Msg: "--board-id and --title are required"})
 		}
-		path := withAccount(ctx, "/boards/"+strings.TrimSpace(*boardID)+"/cards")
-		if strings.TrimSpace(*imagePath) != "" {
-			fields := map[string][]string{
-				"title": {strings.TrimSpace(*title)},
-			}
-			if strings.TrimSpace(*description) != "" {
-				fields["description"] = []string{*description}
-			}
-			if strings.TrimSpace(*status) != "" {
-				fields["status"] = []string{*status}
-			}
-			if len(tagIDs.values) > 0 {
-				fields["tag_ids[]"] = tagIDs.values
-			}
-			body, contentType, err := multipartBody("card", fields, "image", *imagePath)
+		if err := checkEditInput(*edit, "description", *descriptionFile); err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		desc, err := textInput("description", *description, *descriptionFile)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		if *edit {
+			text, err := editText(cardEditHeader(nil, *boardID), cardEditText(strings.TrimSpace(*title), desc))
 			if err != nil {
 				return handleErr(helpForCard(), err)
 			}
-			resp, err := ctx.Client.Do(requestContext(), "POST", path, nil, body, contentType, nil)
+			*title, desc = splitCardText(text)
+		}
+		var steps []fizzy.StepRequest
+		for _, text := range stepTexts.Values() {
+			if text = strings.TrimSpace(text); text != "" {
+				steps = append(steps, fizzy.StepRequest{Content: text})
+			}
+		}
+		if *stepsFile != "" {
+			imported, err := readChecklist(*stepsFile)
 			if err != nil {
 				return handleErr(helpForCard(), err)
 			}
-			return outputLocation(ctx, resp, "Card created")
-		}
-		card := map[string]any{
-			"title": strings.TrimSpace(*title),
+			steps = append(steps, imported...)
 		}
-		if strings.TrimSpace(*description) != "" {
-			card["description"] = *description
-		}
-		if strings.TrimSpace(*status) != "" {
-			card["status"] = *status
+		board, err := resolveBoard(ctx, *boardID)
+		if err != nil {
+			return handleErr(helpForCard(), err)
 		}
-		if len(tagIDs.values) > 0 {
-			card["tag_ids"] = tagIDs.values
+		tags, err := resolveEach(ctx, tagIDs.Values(), resolveTag)
+		if err != nil {
+			return handleErr(helpForCard(), err)
 		}
-		payload := map[string]any{"card": card}
-		resp, err := ctx.Client.Do(requestContext(), "POST", path, nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		req := cardRequest(*title, desc, *status, *imagePath, tags)
+		resp, err := ctx.Client.Cards.Create(requestContext(), board, req)
 		if err != nil {
 			return handleErr(helpForCard(), err)
 		}
+		if len(steps) > 0 {
+			location := resp.Headers.Get("Location")
+			number, ok := cardNumberFromLocation(location)
+			if !ok {
+				return handleErr(helpForCard(), fmt.Errorf("card created, but its number is unknown so steps were not added (Location: %q)", location))
+			}
+			if err := createSteps(ctx, number, steps); err != nil {
+				return handleErr(helpForCard(), fmt.Errorf("card %d created, but adding steps failed: %w", number, err))
+			}
+		}
 		return outputLocation(ctx, resp, "Card created")
 	case "update":
-		if len(args) < 2 {
-			return handleErr(helpForCard(), UsageError{Msg: "card number is required"})
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForCard(), err)
 		}
 		fs := flag.NewFlagSet("card update", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
@@ -514,81 +584,99 @@ func runCard(ctx Context, args []string) int {
 		imagePath := fs.String("image", "", "Image file path")
 		tagIDs := multiString{}
 		fs.Var(&tagIDs, "tag-id", "Tag ID (repeatable)")
+		descriptionFile := fs.String("description-file", "", "Read the description from a file, - for stdin")
+		clearDescription := fs.Bool("clear-description", false, "Remove the description")
+		edit := fs.Bool("edit", false, "Edit the title and description in $EDITOR")
 		if err := fs.Parse(args[2:]); err != nil {
 			return usageError(helpForCard(), err)
 		}
-		card := map[string]any{}
-		if strings.TrimSpace(*title) != "" {
-			card["title"] = strings.TrimSpace(*title)
+		if *clearDescription && (*description != "" || *descriptionFile != "" || *edit) {
+			return handleErr(helpForCard(), UsageError{Msg: "--clear-description cannot be used with --description, --description-file or --edit"})
 		}
-		if strings.TrimSpace(*description) != "" {
-			card["description"] = *description
+		if err := checkEditInput(*edit, "description", *descriptionFile); err != nil {
+			return handleErr(helpForCard(), err)
 		}
-		if strings.TrimSpace(*status) != "" {
-			card["status"] = *status
+		desc, err := textInput("description", *description, *descriptionFile)
+		if err != nil {
+			return handleErr(helpForCard(), err)
 		}
-		if len(tagIDs.values) > 0 {
-			card["tag_ids"] = tagIDs.values
+		tags, err := resolveEach(ctx, tagIDs.Values(), resolveTag)
+		if err != nil {
+			return handleErr(helpForCard(), err)
 		}
-		if strings.TrimSpace(*imagePath) != "" {
-			fields := map[string][]string{}
-			if titleVal, ok := card["title"].(string); ok && titleVal != "" {
-				fields["title"] = []string{titleVal}
-			}
-			if descVal, ok := card["description"].(string); ok && descVal != "" {
-				fields["description"] = []string{descVal}
-			}
-			if statusVal, ok := card["status"].(string); ok && statusVal != "" {
-				fields["status"] = []string{statusVal}
-			}
-			if tagsVal, ok := card["tag_ids"].([]string); ok && len(tagsVal) > 0 {
-				fields["tag_ids[]"] = tagsVal
-			}
-			body, contentType, err := multipartBody("card", fields, "image", *imagePath)
-			if err != nil {
-				return handleErr(helpForCard(), err)
-			}
-			resp, err := ctx.Client.Do(requestContext(), "PUT", withAccount(ctx, "/cards/"+args[1]), nil, body, contentType, nil)
-			if err != nil {
+		req := cardRequest(*title, desc, *status, *imagePath, tags)
+		if *clearDescription {
+			req.Description = new(string)
+		}
+		if *edit {
+			if err := editCardRequest(ctx, number, req); err != nil {
 				return handleErr(helpForCard(), err)
 			}
-			if ctx.Output.JSON {
-				return printJSONResponse(resp)
-			}
-			fmt.Fprintln(os.Stdout, "Card updated.")
-			return 0
 		}
-		if len(card) == 0 {
+		if req.Title == "" && req.Description == nil && req.Status == "" && len(req.TagIDs) == 0 && req.ImagePath == "" {
+			if *edit {
+				fmt.Fprintln(os.Stdout, "No changes.")
+				return 0
+			}
 			return handleErr(helpForCard(), UsageError{Msg: "no fields to update"})
 		}
-		payload := map[string]any{"card": card}
-		resp, err := ctx.Client.Do(requestContext(), "PUT", withAccount(ctx, "/cards/"+args[1]), nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		before, err := snapshotCard(ctx, number)
 		if err != nil {
 			return handleErr(helpForCard(), err)
 		}
-		if ctx.Output.JSON {
-			return printJSONResponse(resp)
+		resp, err := ctx.Client.Cards.Update(requestContext(), number, req)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		journalChange(ctx, journal.Entry{Action: "card update", Card: number, Fields: updatedFields(req), Before: before})
+		if ctx.Output.Structured() {
+			return outputResponse(ctx, resp)
 		}
 		fmt.Fprintln(os.Stdout, "Card updated.")
 		return 0
 	case "delete":
-		if len(args) < 2 {
-			return handleErr(helpForCard(), UsageError{Msg: "card number is required"})
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		fs := flag.NewFlagSet("card delete", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		yes := fs.Bool("yes", false, "Skip the confirmation prompt")
+		if err := fs.Parse(args[2:]); err != nil {
+			return usageError(helpForCard(), err)
+		}
+		ctx.AssumeYes = ctx.AssumeYes || *yes
+		if err := confirmDestroy(ctx, "card delete", describeCardDelete(ctx.Client, number)); err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		before, err := snapshotCard(ctx, number)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		comments, err := ctx.Client.Comments.Iter(number, nil).All(requestContext())
+		if err != nil {
+			return handleErr(helpForCard(), err)
 		}
-		resp, err := ctx.Client.Do(requestContext(), "DELETE", withAccount(ctx, "/cards/"+args[1]), nil, nil, "", nil)
+		commentsJSON, err := json.Marshal(comments)
 		if err != nil {
 			return handleErr(helpForCard(), err)
 		}
+		resp, err := ctx.Client.Cards.Delete(requestContext(), number)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		journalChange(ctx, journal.Entry{Action: "card delete", Card: number, Before: before, Comments: commentsJSON})
 		return outputNoContent(ctx, resp, "Card deleted")
 	case "close":
-		return simpleCardAction(ctx, helpForCard(), args, "close", "POST", "/closure", "Card closed")
+		return cardChange(ctx, args, "card close", ctx.Client.Cards.Close, "Card closed")
 	case "reopen":
-		return simpleCardAction(ctx, helpForCard(), args, "reopen", "DELETE", "/closure", "Card reopened")
+		return cardChange(ctx, args, "card reopen", ctx.Client.Cards.Reopen, "Card reopened")
 	case "not-now":
-		return simpleCardAction(ctx, helpForCard(), args, "not-now", "POST", "/not_now", "Card moved to Not Now")
+		return cardAction(ctx, args, ctx.Client.Cards.NotNow, "Card moved to Not Now")
 	case "triage":
-		if len(args) < 2 {
-			return handleErr(helpForCard(), UsageError{Msg: "card number is required"})
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForCard(), err)
 		}
 		fs := flag.NewFlagSet("card triage", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
@@ -599,17 +687,21 @@ func runCard(ctx Context, args []string) int {
 		if strings.TrimSpace(*columnID) == "" {
 			return handleErr(helpForCard(), UsageError{Msg: "--column-id is required"})
 		}
-		payload := map[string]any{"column_id": strings.TrimSpace(*columnID)}
-		resp, err := ctx.Client.Do(requestContext(), "POST", withAccount(ctx, "/cards/"+args[1]+"/triage"), nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		column, err := resolveCardColumn(ctx, number, *columnID)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		resp, err := ctx.Client.Cards.Triage(requestContext(), number, column)
 		if err != nil {
 			return handleErr(helpForCard(), err)
 		}
 		return outputNoContent(ctx, resp, "Card moved into column")
 	case "untriage":
-		return simpleCardAction(ctx, helpForCard(), args, "untriage", "DELETE", "/triage", "Card moved back to triage")
+		return cardAction(ctx, args, ctx.Client.Cards.Untriage, "Card moved back to triage")
 	case "tag":
-		if len(args) < 2 {
-			return handleErr(helpForCard(), UsageError{Msg: "card number is required"})
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForCard(), err)
 		}
 		fs := flag.NewFlagSet("card tag", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
@@ -620,15 +712,20 @@ func runCard(ctx Context, args []string) int {
 		if strings.TrimSpace(*title) == "" {
 			return handleErr(helpForCard(), UsageError{Msg: "--title is required"})
 		}
-		payload := map[string]any{"tag_title": strings.TrimPrefix(strings.TrimSpace(*title), "#")}
-		resp, err := ctx.Client.Do(requestContext(), "POST", withAccount(ctx, "/cards/"+args[1]+"/taggings"), nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		before, err := snapshotCard(ctx, number)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		resp, err := ctx.Client.Cards.ToggleTag(requestContext(), number, *title)
 		if err != nil {
 			return handleErr(helpForCard(), err)
 		}
+		journalChange(ctx, journal.Entry{Action: "card tag", Card: number, Target: *title, Before: before})
 		return outputNoContent(ctx, resp, "Tag toggled")
 	case "assign":
-		if len(args) < 2 {
-			return handleErr(helpForCard(), UsageError{Msg: "card number is required"})
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForCard(), err)
 		}
 		fs := flag.NewFlagSet("card assign", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
@@ -639,16 +736,26 @@ func runCard(ctx Context, args []string) int {
 		if strings.TrimSpace(*assignee) == "" {
 			return handleErr(helpForCard(), UsageError{Msg: "--assignee-id is required"})
 		}
-		payload := map[string]any{"assignee_id": strings.TrimSpace(*assignee)}
-		resp, err := ctx.Client.Do(requestContext(), "POST", withAccount(ctx, "/cards/"+args[1]+"/assignments"), nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		assigneeID, err := resolveUser(ctx, *assignee)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		before, err := snapshotCard(ctx, number)
+		if err != nil {
+			return handleErr(helpForCard(), err)
+		}
+		resp, err := ctx.Client.Cards.ToggleAssignment(requestContext(), number, assigneeID)
 		if err != nil {
 			return handleErr(helpForCard(), err)
 		}
+		journalChange(ctx, journal.Entry{Action: "card assign", Card: number, Target: assigneeID, Before: before})
 		return outputNoContent(ctx, resp, "Assignment toggled")
+	case "bulk":
+		return runCardBulk(ctx, args[1:])
 	case "watch":
-		return simpleCardAction(ctx, helpForCard(), args, "watch", "POST", "/watch", "Subscribed to card")
+		return cardAction(ctx, args, ctx.Client.Cards.Watch, "Subscribed to card")
 	case "unwatch":
-		return simpleCardAction(ctx, helpForCard(), args, "unwatch", "DELETE", "/watch", "Unsubscribed from card")
+		return cardAction(ctx, args, ctx.Client.Cards.Unwatch, "Unsubscribed from card")
 	default:
 		fmt.Fprint(os.Stderr, helpForCard())
 		return 2
@@ -668,41 +775,63 @@ func runComment(ctx Context, args []string) int {
 	}
 	switch args[0] {
 	case "list":
-		if len(args) < 2 {
-			return handleErr(helpForComment(), UsageError{Msg: "card number is required"})
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForComment(), err)
 		}
-		path := withAccount(ctx, "/cards/"+args[1]+"/comments")
-		resp, err := ctx.Client.Do(requestContext(), "GET", path, nil, nil, "", nil)
+		fs := flag.NewFlagSet("comment list", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		paging := addListFlags(fs)
+		if err := fs.Parse(args[2:]); err != nil {
+			return usageError(helpForComment(), err)
+		}
+		opts, err := paging.options()
 		if err != nil {
 			return handleErr(helpForComment(), err)
 		}
-		return outputListOrJSON(ctx, resp, commentListHeaders, commentListRows)
+		return outputIterator(ctx, helpForComment(), ctx.Client.Comments.Iter(number, &opts), commentView)
 	case "get":
 		if len(args) < 3 {
 			return handleErr(helpForComment(), UsageError{Msg: "card number and comment id are required"})
 		}
-		path := withAccount(ctx, "/cards/"+args[1]+"/comments/"+args[2])
-		resp, err := ctx.Client.Do(requestContext(), "GET", path, nil, nil, "", nil)
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForComment(), err)
+		}
+		comment, resp, err := ctx.Client.Comments.Get(requestContext(), number, args[2])
 		if err != nil {
 			return handleErr(helpForComment(), err)
 		}
-		return outputJSONOrPretty(ctx, resp.Body, formatComment)
+		return outputItem(ctx, resp, comment, commentView)
 	case "create":
-		if len(args) < 2 {
-			return handleErr(helpForComment(), UsageError{Msg: "card number is required"})
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForComment(), err)
 		}
 		fs := flag.NewFlagSet("comment create", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
 		body := fs.String("body", "", "Comment body")
+		bodyFile := fs.String("body-file", "", "Read the body from a file, - for stdin")
+		edit := fs.Bool("edit", false, "Write the body in $EDITOR")
 		if err := fs.Parse(args[2:]); err != nil {
 			return usageError(helpForComment(), err)
 		}
-		if strings.TrimSpace(*body) == "" {
-			return handleErr(helpForComment(), UsageError{Msg: "--body is required"})
+		if err := checkEditInput(*edit, "body", *bodyFile); err != nil {
+			return handleErr(helpForComment(), err)
+		}
+		text, err := textInput("body", *body, *bodyFile)
+		if err != nil {
+			return handleErr(helpForComment(), err)
+		}
+		if *edit {
+			if text, err = editText(commentEditHeader(number, nil), text); err != nil {
+				return handleErr(helpForComment(), err)
+			}
+		}
+		if strings.TrimSpace(text) == "" {
+			return handleErr(helpForComment(), UsageError{Msg: "--body, --body-file or --edit is required"})
 		}
-		payload := map[string]any{"comment": map[string]any{"body": *body}}
-		path := withAccount(ctx, "/cards/"+args[1]+"/comments")
-		resp, err := ctx.Client.Do(requestContext(), "POST", path, nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		resp, err := ctx.Client.Comments.Create(requestContext(), number, &fizzy.CommentRequest{Body: text})
 		if err != nil {
 			return handleErr(helpForComment(), err)
 		}
@@ -711,23 +840,54 @@ func runComment(ctx Context, args []string) int {
 		if len(args) < 3 {
 			return handleErr(helpForComment(), UsageError{Msg: "card number and comment id are required"})
 		}
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForComment(), err)
+		}
 		fs := flag.NewFlagSet("comment update", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
 		body := fs.String("body", "", "Comment body")
+		bodyFile := fs.String("body-file", "", "Read the body from a file, - for stdin")
+		edit := fs.Bool("edit", false, "Edit the body in $EDITOR")
 		if err := fs.Parse(args[3:]); err != nil {
 			return usageError(helpForComment(), err)
 		}
-		if strings.TrimSpace(*body) == "" {
-			return handleErr(helpForComment(), UsageError{Msg: "--body is required"})
+		if err := checkEditInput(*edit, "body", *bodyFile); err != nil {
+			return handleErr(helpForComment(), err)
+		}
+		text, err := textInput("body", *body, *bodyFile)
+		if err != nil {
+			return handleErr(helpForComment(), err)
+		}
+		if *edit {
+			comment, _, err := ctx.Client.Comments.Get(requestContext(), number, args[2])
+			if err != nil {
+				return handleErr(helpForComment(), err)
+			}
+			edited, err := editText(commentEditHeader(number, comment), firstNonEmpty(text, comment.Body.Plain))
+			if err != nil {
+				return handleErr(helpForComment(), err)
+			}
+			if edited == strings.TrimSpace(comment.Body.Plain) {
+				fmt.Fprintln(os.Stdout, "No changes.")
+				return 0
+			}
+			text = edited
+		}
+		if strings.TrimSpace(text) == "" {
+			return handleErr(helpForComment(), UsageError{Msg: "--body, --body-file or --edit is required"})
+		}
+		before, err := snapshotComment(ctx, number, args[2])
+		if err != nil {
+			return handleErr(helpForComment(), err)
 		}
-		payload := map[string]any{"comment": map[string]any{"body": *body}}
-		path := withAccount(ctx, "/cards/"+args[1]+"/comments/"+args[2])
-		resp, err := ctx.Client.Do(requestContext(), "PUT", path, nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		resp, err := ctx.Client.Comments.Update(requestContext(), number, args[2], &fizzy.CommentRequest{Body: text})
 		if err != nil {
 			return handleErr(helpForComment(), err)
 		}
-		if ctx.Output.JSON {
-			return printJSONResponse(resp)
+		journalChange(ctx, journal.Entry{Action: "comment update", Card: number, Target: args[2], Before: before})
+		if ctx.Output.Structured() {
+			return outputResponse(ctx, resp)
 		}
 		fmt.Fprintln(os.Stdout, "Comment updated.")
 		return 0
@@ -735,11 +895,29 @@ func runComment(ctx Context, args []string) int {
 		if len(args) < 3 {
 			return handleErr(helpForComment(), UsageError{Msg: "card number and comment id are required"})
 		}
-		path := withAccount(ctx, "/cards/"+args[1]+"/comments/"+args[2])
-		resp, err := ctx.Client.Do(requestContext(), "DELETE", path, nil, nil, "", nil)
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForComment(), err)
+		}
+		fs := flag.NewFlagSet("comment delete", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		yes := fs.Bool("yes", false, "Skip the confirmation prompt")
+		if err := fs.Parse(args[3:]); err != nil {
+			return usageError(helpForComment(), err)
+		}
+		ctx.AssumeYes = ctx.AssumeYes || *yes
+		if err := confirmDestroy(ctx, "comment delete", describeCommentDelete(ctx.Client, number, args[2])); err != nil {
+			return handleErr(helpForComment(), err)
+		}
+		before, err := snapshotComment(ctx, number, args[2])
 		if err != nil {
 			return handleErr(helpForComment(), err)
 		}
+		resp, err := ctx.Client.Comments.Delete(requestContext(), number, args[2])
+		if err != nil {
+			return handleErr(helpForComment(), err)
+		}
+		journalChange(ctx, journal.Entry{Action: "comment delete", Card: number, Target: args[2], Before: before})
 		return outputNoContent(ctx, resp, "Comment deleted")
 	default:
 		fmt.Fprint(os.Stderr, helpForComment())
@@ -758,11 +936,17 @@ func runTag(ctx Context, args []string) int {
 	if err := ensureAccount(ctx); err != nil {
 		return handleErr(helpForTag(), err)
 	}
-	resp, err := ctx.Client.Do(requestContext(), "GET", withAccount(ctx, "/tags"), nil, nil, "", nil)
+	fs := flag.NewFlagSet("tag list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	paging := addListFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return usageError(helpForTag(), err)
+	}
+	opts, err := paging.options()
 	if err != nil {
 		return handleErr(helpForTag(), err)
 	}
-	return outputListOrJSON(ctx, resp, tagListHeaders, tagListRows)
+	return outputIterator(ctx, helpForTag(), ctx.Client.Tags.Iter(&opts), tagView)
 }
 
 func runColumn(ctx Context, args []string) int {
@@ -781,18 +965,22 @@ func runColumn(ctx Context, args []string) int {
 		fs := flag.NewFlagSet("column list", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
 		boardID := fs.String("board-id", "", "Board ID")
+		paging := addListFlags(fs)
 		if err := fs.Parse(args[1:]); err != nil {
 			return usageError(helpForColumn(), err)
 		}
 		if strings.TrimSpace(*boardID) == "" {
 			return handleErr(helpForColumn(), UsageError{Msg: "--board-id is required"})
 		}
-		path := withAccount(ctx, "/boards/"+strings.TrimSpace(*boardID)+"/columns")
-		resp, err := ctx.Client.Do(requestContext(), "GET", path, nil, nil, "", nil)
+		opts, err := paging.options()
+		if err != nil {
+			return handleErr(helpForColumn(), err)
+		}
+		board, err := resolveBoard(ctx, *boardID)
 		if err != nil {
 			return handleErr(helpForColumn(), err)
 		}
-		return outputListOrJSON(ctx, resp, columnListHeaders, columnListRows)
+		return outputIterator(ctx, helpForColumn(), ctx.Client.Columns.Iter(board, &opts), columnView)
 	case "get":
 		if len(args) < 2 {
 			return handleErr(helpForColumn(), UsageError{Msg: "column id is required"})
@@ -806,12 +994,15 @@ func runColumn(ctx Context, args []string) int {
 		if strings.TrimSpace(*boardID) == "" {
 			return handleErr(helpForColumn(), UsageError{Msg: "--board-id is required"})
 		}
-		path := withAccount(ctx, "/boards/"+strings.TrimSpace(*boardID)+"/columns/"+args[1])
-		resp, err := ctx.Client.Do(requestContext(), "GET", path, nil, nil, "", nil)
+		board, columnID, err := resolveBoardColumn(ctx, *boardID, args[1])
 		if err != nil {
 			return handleErr(helpForColumn(), err)
 		}
-		return outputJSONOrPretty(ctx, resp.Body, formatColumn)
+		column, resp, err := ctx.Client.Columns.Get(requestContext(), board, columnID)
+		if err != nil {
+			return handleErr(helpForColumn(), err)
+		}
+		return outputItem(ctx, resp, column, columnView)
 	case "create":
 		fs := flag.NewFlagSet("column create", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
@@ -824,13 +1015,12 @@ func runColumn(ctx Context, args []string) int {
 		if strings.TrimSpace(*boardID) == "" || strings.TrimSpace(*name) == "" {
 			return handleErr(helpForColumn(), UsageError{Msg: "--board-id and --name are required"})
 		}
-		column := map[string]any{"name": strings.TrimSpace(*name)}
-		if strings.TrimSpace(*color) != "" {
-			column["color"] = strings.TrimSpace(*color)
+		req := &fizzy.ColumnRequest{Name: strings.TrimSpace(*name), Color: strings.TrimSpace(*color)}
+		board, err := resolveBoard(ctx, *boardID)
+		if err != nil {
+			return handleErr(helpForColumn(), err)
 		}
-		payload := map[string]any{"column": column}
-		path := withAccount(ctx, "/boards/"+strings.TrimSpace(*boardID)+"/columns")
-		resp, err := ctx.Client.Do(requestContext(), "POST", path, nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		resp, err := ctx.Client.Columns.Create(requestContext(), board, req)
 		if err != nil {
 			return handleErr(helpForColumn(), err)
 		}
@@ -850,19 +1040,15 @@ func runColumn(ctx Context, args []string) int {
 		if strings.TrimSpace(*boardID) == "" {
 			return handleErr(helpForColumn(), UsageError{Msg: "--board-id is required"})
 		}
-		column := map[string]any{}
-		if strings.TrimSpace(*name) != "" {
-			column["name"] = strings.TrimSpace(*name)
-		}
-		if strings.TrimSpace(*color) != "" {
-			column["color"] = strings.TrimSpace(*color)
-		}
-		if len(column) == 0 {
+		req := &fizzy.ColumnRequest{Name: strings.TrimSpace(*name), Color: strings.TrimSpace(*color)}
+		if req.Name == "" && req.Color == "" {
 			return handleErr(helpForColumn(), UsageError{Msg: "no fields to update"})
 		}
-		payload := map[string]any{"column": column}
-		path := withAccount(ctx, "/boards/"+strings.TrimSpace(*boardID)+"/columns/"+args[1])
-		resp, err := ctx.Client.Do(requestContext(), "PUT", path, nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		board, columnID, err := resolveBoardColumn(ctx, *boardID, args[1])
+		if err != nil {
+			return handleErr(helpForColumn(), err)
+		}
+		resp, err := ctx.Client.Columns.Update(requestContext(), board, columnID, req)
 		if err != nil {
 			return handleErr(helpForColumn(), err)
 		}
@@ -874,14 +1060,22 @@ func runColumn(ctx Context, args []string) int {
 		fs := flag.NewFlagSet("column delete", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
 		boardID := fs.String("board-id", "", "Board ID")
+		yes := fs.Bool("yes", false, "Skip the confirmation prompt")
 		if err := fs.Parse(args[2:]); err != nil {
 			return usageError(helpForColumn(), err)
 		}
 		if strings.TrimSpace(*boardID) == "" {
 			return handleErr(helpForColumn(), UsageError{Msg: "--board-id is required"})
 		}
-		path := withAccount(ctx, "/boards/"+strings.TrimSpace(*boardID)+"/columns/"+args[1])
-		resp, err := ctx.Client.Do(requestContext(), "DELETE", path, nil, nil, "", nil)
+		board, columnID, err := resolveBoardColumn(ctx, *boardID, args[1])
+		if err != nil {
+			return handleErr(helpForColumn(), err)
+		}
+		ctx.AssumeYes = ctx.AssumeYes || *yes
+		if err := confirmDestroy(ctx, "column delete", describeColumnDelete(ctx.Client, board, columnID)); err != nil {
+			return handleErr(helpForColumn(), err)
+		}
+		resp, err := ctx.Client.Columns.Delete(requestContext(), board, columnID)
 		if err != nil {
 			return handleErr(helpForColumn(), err)
 		}
@@ -905,20 +1099,30 @@ func runUser(ctx Context, args []string) int {
 	}
 	switch args[0] {
 	case "list":
-		resp, err := ctx.Client.Do(requestContext(), "GET", withAccount(ctx, "/users"), nil, nil, "", nil)
+		fs := flag.NewFlagSet("user list", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		paging := addListFlags(fs)
+		if err := fs.Parse(args[1:]); err != nil {
+			return usageError(helpForUser(), err)
+		}
+		opts, err := paging.options()
 		if err != nil {
 			return handleErr(helpForUser(), err)
 		}
-		return outputListOrJSON(ctx, resp, userListHeaders, userListRows)
+		return outputIterator(ctx, helpForUser(), ctx.Client.Users.Iter(&opts), userView)
 	case "get":
 		if len(args) < 2 {
 			return handleErr(helpForUser(), UsageError{Msg: "user id is required"})
 		}
-		resp, err := ctx.Client.Do(requestContext(), "GET", withAccount(ctx, "/users/"+args[1]), nil, nil, "", nil)
+		userID, err := resolveUser(ctx, args[1])
+		if err != nil {
+			return handleErr(helpForUser(), err)
+		}
+		user, resp, err := ctx.Client.Users.Get(requestContext(), userID)
 		if err != nil {
 			return handleErr(helpForUser(), err)
 		}
-		return outputJSONOrPretty(ctx, resp.Body, formatUser)
+		return outputItem(ctx, resp, user, userView)
 	case "update":
 		if len(args) < 2 {
 			return handleErr(helpForUser(), UsageError{Msg: "user id is required"})
@@ -933,20 +1137,12 @@ func runUser(ctx Context, args []string) int {
 		if strings.TrimSpace(*name) == "" && strings.TrimSpace(*avatar) == "" {
 			return handleErr(helpForUser(), UsageError{Msg: "--name or --avatar is required"})
 		}
-		path := withAccount(ctx, "/users/"+args[1])
-		if strings.TrimSpace(*avatar) != "" {
-			body, contentType, err := multipartBody("user", map[string][]string{"name": {strings.TrimSpace(*name)}}, "avatar", *avatar)
-			if err != nil {
-				return handleErr(helpForUser(), err)
-			}
-			resp, err := ctx.Client.Do(requestContext(), "PUT", path, nil, body, contentType, nil)
-			if err != nil {
-				return handleErr(helpForUser(), err)
-			}
-			return outputNoContent(ctx, resp, "User updated")
+		req := &fizzy.UserUpdateRequest{Name: strings.TrimSpace(*name), AvatarPath: strings.TrimSpace(*avatar)}
+		userID, err := resolveUser(ctx, args[1])
+		if err != nil {
+			return handleErr(helpForUser(), err)
 		}
-		payload := map[string]any{"user": map[string]any{"name": strings.TrimSpace(*name)}}
-		resp, err := ctx.Client.Do(requestContext(), "PUT", path, nil, bytes.NewBuffer(mustJSON(payload)), "application/json", nil)
+		resp, err := ctx.Client.Users.Update(requestContext(), userID, req)
 		if err != nil {
 			return handleErr(helpForUser(), err)
 		}
@@ -955,7 +1151,21 @@ func runUser(ctx Context, args []string) int {
 		if len(args) < 2 {
 			return handleErr(helpForUser(), UsageError{Msg: "user id is required"})
 		}
-		resp, err := ctx.Client.Do(requestContext(), "DELETE", withAccount(ctx, "/users/"+args[1]), nil, nil, "", nil)
+		fs := flag.NewFlagSet("user deactivate", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		yes := fs.Bool("yes", false, "Skip the confirmation prompt")
+		if err := fs.Parse(args[2:]); err != nil {
+			return usageError(helpForUser(), err)
+		}
+		userID, err := resolveUser(ctx, args[1])
+		if err != nil {
+			return handleErr(helpForUser(), err)
+		}
+		ctx.AssumeYes = ctx.AssumeYes || *yes
+		if err := confirmDestroy(ctx, "user deactivate", describeUserDeactivate(ctx.Client, userID)); err != nil {
+			return handleErr(helpForUser(), err)
+		}
+		resp, err := ctx.Client.Users.Deactivate(requestContext(), userID)
 		if err != nil {
 			return handleErr(helpForUser(), err)
 		}
@@ -982,24 +1192,21 @@ func runNotification(ctx Context, args []string) int {
 		fs := flag.NewFlagSet("notification list", flag.ContinueOnError)
 		fs.SetOutput(io.Discard)
 		unread := fs.Bool("unread", false, "Show only unread")
+		paging := addListFlags(fs)
 		if err := fs.Parse(args[1:]); err != nil {
 			return usageError(helpForNotification(), err)
 		}
-		query := url.Values{}
-		if *unread {
-			query.Set("unread", "true")
-		}
-		resp, err := ctx.Client.Do(requestContext(), "GET", withAccount(ctx, "/notifications"), query, nil, "", nil)
+		listOpts, err := paging.options()
 		if err != nil {
 			return handleErr(helpForNotification(), err)
 		}
-		return outputListOrJSON(ctx, resp, notificationListHeaders, notificationListRows)
+		opts := &fizzy.NotificationListOptions{ListOptions: listOpts, Unread: *unread}
+		return outputIterator(ctx, helpForNotification(), ctx.Client.Notifications.Iter(opts), notificationView)
 	case "read":
 		if len(args) < 2 {
 			return handleErr(helpForNotification(), UsageError{Msg: "notification id is required"})
 		}
-		path := withAccount(ctx, "/notifications/"+args[1]+"/reading")
-		resp, err := ctx.Client.Do(requestContext(), "POST", path, nil, nil, "", nil)
+		resp, err := ctx.Client.Notifications.Read(requestContext(), args[1])
 		if err != nil {
 			return handleErr(helpForNotification(), err)
 		}
@@ -1008,14 +1215,13 @@ func runNotification(ctx Context, args []string) int {
 		if len(args) < 2 {
 			return handleErr(helpForNotification(), UsageError{Msg: "notification id is required"})
 		}
-		path := withAccount(ctx, "/notifications/"+args[1]+"/reading")
-		resp, err := ctx.Client.Do(requestContext(), "DELETE", path, nil, nil, "", nil)
+		resp, err := ctx.Client.Notifications.Unread(requestContext(), args[1])
 		if err != nil {
 			return handleErr(helpForNotification(), err)
 		}
 		return outputNoContent(ctx, resp, "Notification marked unread")
 	case "read-all":
-		resp, err := ctx.Client.Do(requestContext(), "POST", withAccount(ctx, "/notifications/bulk_reading"), nil, nil, "", nil)
+		resp, err := ctx.Client.Notifications.ReadAll(requestContext())
 		if err != nil {
 			return handleErr(helpForNotification(), err)
 		}
@@ -1026,222 +1232,114 @@ func runNotification(ctx Context, args []string) int {
 	}
 }
 
-func listWithPagination(ctx Context, help string, path string, query url.Values, all bool, headers []string, rowFn func([]byte) ([][]string, error)) int {
-	if !all {
-		resp, err := ctx.Client.Do(requestContext(), "GET", path, query, nil, "", nil)
-		if err != nil {
-			return handleErr(help, err)
-		}
-		return outputListOrJSON(ctx, resp, headers, rowFn)
-	}
-
-	if ctx.Output.JSON {
-		combined := []json.RawMessage{}
-		nextPath := path
-		nextQuery := query
-		for {
-			resp, err := ctx.Client.Do(requestContext(), "GET", nextPath, nextQuery, nil, "", nil)
-			if err != nil {
-				return handleErr(help, err)
-			}
-			var page []json.RawMessage
-			if err := json.Unmarshal(resp.Body, &page); err != nil {
-				return handleErr(help, err)
-			}
-			combined = append(combined, page...)
-			next := nextLink(resp.Headers)
-			if next == "" {
-				break
-			}
-			nextPath = next
-			nextQuery = nil
-		}
-		if err := printJSON(os.Stdout, combined); err != nil {
-			return handleErr(help, err)
-		}
-		return 0
-	}
-
-	nextPath := path
-	nextQuery := query
-	printedHeader := false
-	for {
-		resp, err := ctx.Client.Do(requestContext(), "GET", nextPath, nextQuery, nil, "", nil)
-		if err != nil {
-			return handleErr(help, err)
-		}
-		rows, err := rowFn(resp.Body)
-		if err != nil {
-			return handleErr(help, err)
-		}
-		if !printedHeader {
-			printTable(os.Stdout, headers, rows, ctx.Output.Plain)
-			printedHeader = true
-		} else {
-			printTable(os.Stdout, nil, rows, true)
-		}
-		next := nextLink(resp.Headers)
-		if next == "" {
-			break
-		}
-		nextPath = next
-		nextQuery = nil
-	}
-	return 0
-}
-
-func simpleCardAction(ctx Context, help string, args []string, name string, method string, suffix string, message string) int {
-	if len(args) < 2 {
-		return handleErr(help, UsageError{Msg: "card number is required"})
+func cardAction(ctx Context, args []string, action func(context.Context, int) (*fizzy.Response, error), message string) int {
+	number, err := cardNumberArg(args)
+	if err != nil {
+		return handleErr(helpForCard(), err)
 	}
-	path := withAccount(ctx, "/cards/"+args[1]+suffix)
-	resp, err := ctx.Client.Do(requestContext(), method, path, nil, nil, "", nil)
+	resp, err := action(requestContext(), number)
 	if err != nil {
-		return handleErr(help, err)
+		return handleErr(helpForCard(), err)
 	}
 	return outputNoContent(ctx, resp, message)
 }
 
-func configSave(path string, cfg config.Config) error {
-	return config.Save(path, cfg)
-}
-
-func usageError(help string, err error) int {
-	if errors.Is(err, flag.ErrHelp) {
-		fmt.Fprint(os.Stdout, help)
-		return 0
+func cardNumberArg(args []string) (int, error) {
+	if len(args) < 2 {
+		return 0, UsageError{Msg: "card number is required"}
 	}
-	return handleErr(help, UsageError{Msg: err.Error()})
+	return parseCardNumber(args[1])
 }
 
-func handleErr(help string, err error) int {
-	printErr(err)
-	var usage UsageError
-	if errors.As(err, &usage) {
-		fmt.Fprint(os.Stderr, "\n")
-		fmt.Fprint(os.Stderr, help)
+func parseCardNumber(value string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(value), "#"))
+	if err != nil || number <= 0 {
+		return 0, UsageError{Msg: fmt.Sprintf("invalid card number %q", value)}
 	}
-	return exitCode(err)
-}
-
-func mustJSON(v any) []byte {
-	data, _ := json.Marshal(v)
-	return data
+	return number, nil
 }
 
-func outputLocation(ctx Context, resp *api.Response, successMessage string) int {
-	location := resp.Headers.Get("Location")
-	if ctx.Output.JSON {
-		payload := map[string]any{"status": resp.Status, "location": location}
-		if err := printJSON(os.Stdout, payload); err != nil {
-			return handleErr("", err)
-		}
-		return 0
+// editCardRequest opens the card's current title and description, or the
+// values already in req, in the editor and keeps only what changed.
+func editCardRequest(ctx Context, number int, req *fizzy.CardRequest) error {
+	card, _, err := ctx.Client.Cards.Get(requestContext(), number)
+	if err != nil {
+		return err
 	}
-	if location != "" {
-		fmt.Fprintf(os.Stdout, "%s: %s\n", successMessage, location)
-		return 0
+	description := card.Description
+	if req.Description != nil {
+		description = *req.Description
 	}
-	fmt.Fprintln(os.Stdout, successMessage+".")
-	return 0
-}
-
-func outputNoContent(ctx Context, resp *api.Response, successMessage string) int {
-	if ctx.Output.JSON {
-		payload := map[string]any{"status": resp.Status}
-		if err := printJSON(os.Stdout, payload); err != nil {
-			return handleErr("", err)
-		}
-		return 0
+	text, err := editText(cardEditHeader(card, ""), cardEditText(firstNonEmpty(req.Title, card.Title), description))
+	if err != nil {
+		return err
 	}
-	fmt.Fprintln(os.Stdout, successMessage+".")
-	return 0
-}
-
-func outputListOrJSON(ctx Context, resp *api.Response, headers []string, rowFn func([]byte) ([][]string, error)) int {
-	if ctx.Output.JSON {
-		return printJSONResponse(resp)
+	title, description := splitCardText(text)
+	if title == "" {
+		return errEmptyEdit
 	}
-	return outputListRows(ctx, resp.Body, headers, rowFn)
+	req.Title, req.Description = "", nil
+	if title != card.Title {
+		req.Title = title
+	}
+	if description != strings.TrimSpace(card.Description) {
+		req.Description = &description
+	}
+	return nil
 }
 
-func outputListRows(ctx Context, body []byte, headers []string, rowFn func([]byte) ([][]string, error)) int {
-	rows, err := rowFn(body)
-	if err != nil {
-		return handleErr("", err)
+func cardRequest(title, description, status, imagePath string, tagIDs []string) *fizzy.CardRequest {
+	return &fizzy.CardRequest{
+		Title:       strings.TrimSpace(title),
+		Description: optionalText(description),
+		Status:      strings.TrimSpace(status),
+		TagIDs:      tagIDs,
+		ImagePath:   strings.TrimSpace(imagePath),
 	}
-	printTable(os.Stdout, headers, rows, ctx.Output.Plain)
-	return 0
 }
 
-func outputJSONOrPretty(ctx Context, body []byte, formatFn func([]byte) (string, error)) int {
-	if ctx.Output.JSON {
-		if err := printJSONBytes(os.Stdout, body); err != nil {
-			return handleErr("", err)
-		}
-		return 0
+// optionalText returns nil for blank text, so a request leaves the field
+// unchanged.
+func optionalText(text string) *string {
+	if strings.TrimSpace(text) == "" {
+		return nil
 	}
-	text, err := formatFn(body)
-	if err != nil {
-		return handleErr("", err)
-	}
-	fmt.Fprintln(os.Stdout, text)
-	return 0
+	return &text
 }
 
-func printJSONResponse(resp *api.Response) int {
-	if len(resp.Body) == 0 {
-		payload := map[string]any{"status": resp.Status}
-		if err := printJSON(os.Stdout, payload); err != nil {
-			return handleErr("", err)
-		}
-		return 0
-	}
-	if err := printJSONBytes(os.Stdout, resp.Body); err != nil {
-		return handleErr("", err)
-	}
-	return 0
+func configSave(path string, cfg config.Config) error {
+	return config.Save(path, cfg)
 }
 
-func addListParam(values url.Values, key string, list []string) {
-	for _, v := range list {
-		if strings.TrimSpace(v) != "" {
-			values.Add(key, strings.TrimSpace(v))
-		}
-	}
+func saveProfile(ctx Context, profile config.Profile) error {
+	cfg := ctx.Config
+	cfg.SetProfile(ctx.ProfileName, profile)
+	return configSave(ctx.ConfigPath, cfg)
 }
 
-func setStringParam(values url.Values, key, value string) {
-	value = strings.TrimSpace(value)
-	if value != "" {
-		values.Set(key, value)
+func usageError(help string, err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stdout, help)
+		return 0
 	}
+	return handleErr(help, UsageError{Msg: err.Error()})
 }
 
-func nextLink(headers map[string][]string) string {
-	linkHeader := ""
-	for k, v := range headers {
-		if strings.EqualFold(k, "Link") && len(v) > 0 {
-			linkHeader = v[0]
-			break
-		}
-	}
-	if linkHeader == "" {
-		return ""
+func handleErr(help string, err error) int {
+	// A dry run stopping at a write is not a failure, so it isn't prefixed
+	// with "error:", but its exit code tells scripts nothing was changed.
+	var dryRun *fizzy.DryRunError
+	if errors.As(err, &dryRun) && !jsonErrors {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitDryRun
 	}
-	parts := strings.Split(linkHeader, ",")
-	for _, part := range parts {
-		sections := strings.Split(strings.TrimSpace(part), ";")
-		if len(sections) < 2 {
-			continue
-		}
-		urlPart := strings.TrimSpace(sections[0])
-		relPart := strings.TrimSpace(sections[1])
-		if strings.Contains(relPart, "rel=\"next\"") {
-			return strings.Trim(urlPart, "<>")
-		}
+	printErr(err)
+	var usage UsageError
+	if errors.As(err, &usage) && !jsonErrors {
+		fmt.Fprint(os.Stderr, "\n")
+		fmt.Fprint(os.Stderr, help)
 	}
-	return ""
+	return exitCode(err)
 }
 
 func readSecret(label string) (string, error) {
@@ -1268,50 +1366,3 @@ func isTTY(file *os.File) bool {
 	}
 	return info.Mode()&os.ModeCharDevice != 0
 }
-
-func multipartBody(rootKey string, fields map[string][]string, fileField, filePath string) (io.Reader, string, error) {
-	buf := &bytes.Buffer{}
-	writer := multipart.NewWriter(buf)
-	for key, values := range fields {
-		fieldName := buildFieldName(rootKey, key)
-		for _, value := range values {
-			if strings.TrimSpace(value) == "" {
-				continue
-			}
-			if err := writer.WriteField(fieldName, value); err != nil {
-				return nil, "", err
-			}
-		}
-	}
-	if fileField != "" && strings.TrimSpace(filePath) != "" {
-		file, err := os.Open(filePath)
-		if err != nil {
-			return nil, "", err
-		}
-		defer file.Close()
-
-		part, err := writer.CreateFormFile(fmt.Sprintf("%s[%s]", rootKey, fileField), filepath.Base(filePath))
-		if err != nil {
-			return nil, "", err
-		}
-		if _, err := io.Copy(part, file); err != nil {
-			return nil, "", err
-		}
-	}
-	if err := writer.Close(); err != nil {
-		return nil, "", err
-	}
-	return buf, writer.FormDataContentType(), nil
-}
-
-func buildFieldName(rootKey, key string) string {
-	suffix := ""
-	if strings.HasSuffix(key, "[]") {
-		key = strings.TrimSuffix(key, "[]")
-		suffix = "[]"
-	}
-	if rootKey == "" {
-		return key + suffix
-	}
-	return fmt.Sprintf("%s[%s]%s", rootKey, key, suffix)
-}
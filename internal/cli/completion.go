@@ -0,0 +1,730 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fizzy-cli/internal/credstore"
+	"fizzy-cli/pkg/fizzy"
+)
+
+const (
+	// completionFiles tells the shell scripts to fall back to file names.
+	completionFiles = ":files"
+
+	completionCacheTTL = time.Minute
+	completionTimeout  = 3 * time.Second
+)
+
+// Value kinds for flags and positional arguments. An empty kind marks a
+// boolean flag.
+const (
+	valueText         = "text"
+	valueFile         = "file"
+	valueBoard        = "board"
+	valueColumn       = "column"
+	valueCard         = "card"
+	valueComment      = "comment"
+	valueStep         = "step"
+	valueUser         = "user"
+	valueTag          = "tag"
+	valueTagTitle     = "tag-title"
+	valueNotification = "notification"
+	valueAccount      = "account"
+	valueProfile      = "profile"
+	valueCommand      = "command"
+)
+
+var completionValues = map[string][]string{
+	"status":            {"drafted", "published"},
+	"indexed-by":        {"all", "closed", "not_now", "stalled", "postponing_soon", "golden"},
+	"sorted-by":         {"latest", "newest", "oldest"},
+	"assignment-status": {"unassigned"},
+	"date":              {"today", "yesterday", "thisweek", "lastweek", "thismonth", "lastmonth", "thisyear", "lastyear"},
+	"credential-store":  {credstore.BackendFile, credstore.BackendEncrypted, credstore.BackendHelper + ":"},
+	"shell":             {"bash", "zsh", "fish"},
+	"bulk-action":       {"close", "reopen", "not-now", "triage", "tag", "assign", "watch", "delete"},
+	"output":            {"table", "plain", "json", "ndjson", "yaml", "csv", "tsv", "markdown"},
+}
+
+type completionSpec struct {
+	flags       map[string]string
+	positionals []string
+}
+
+var globalCompletionFlags = map[string]string{
+	"base-url":      valueText,
+	"token":         valueText,
+	"account":       valueAccount,
+	"config":        valueFile,
+	"profile":       valueProfile,
+	"json":          "",
+	"plain":         "",
+	"output":        "output",
+	"o":             "output",
+	"no-color":      "",
+	"max-attempts":  valueText,
+	"retry-timeout": valueText,
+	"record":        valueFile,
+	"replay":        valueFile,
+	"verbose":       "",
+	"dry-run":       "",
+	"print-curl":    "",
+	"show-secrets":  "",
+	"yes":           "",
+	"format":        valueText,
+	"fields":        valueText,
+	"jq":            valueText,
+	"help":          "",
+	"version":       "",
+}
+
+var listCompletionFlags = map[string]string{
+	"all":       "",
+	"limit":     valueText,
+	"page-size": valueText,
+}
+
+var cardFilterCompletionFlags = map[string]string{
+	"board-id":          valueBoard,
+	"tag-id":            valueTag,
+	"assignee-id":       valueUser,
+	"creator-id":        valueUser,
+	"closer-id":         valueUser,
+	"card-id":           valueText,
+	"indexed-by":        "indexed-by",
+	"sorted-by":         "sorted-by",
+	"assignment-status": "assignment-status",
+	"creation":          "date",
+	"closure":           "date",
+	"term":              valueText,
+}
+
+// completionCommands mirrors the commands documented in help.go. Commands
+// without subcommands use the empty key.
+var completionCommands = map[string]map[string]completionSpec{
+	"auth": {
+		"login":  {flags: map[string]string{"token": valueText, "email": valueText, "code": valueText}},
+		"logout": {},
+		"status": {},
+	},
+	"account": {
+		"list": {},
+		"set":  {positionals: []string{valueAccount}},
+	},
+	"config": {
+		"show": {},
+		"set": {flags: map[string]string{
+			"base-url":         valueText,
+			"account":          valueAccount,
+			"max-attempts":     valueText,
+			"retry-timeout":    valueText,
+			"cache-ttl":        valueText,
+			"credential-store": "credential-store",
+		}},
+	},
+	"profile": {
+		"list":   {},
+		"add":    {flags: map[string]string{"base-url": valueText, "account": valueText, "token": valueText, "use": ""}, positionals: []string{valueText}},
+		"use":    {positionals: []string{valueProfile}},
+		"remove": {positionals: []string{valueProfile}},
+		"rename": {positionals: []string{valueProfile, valueText}},
+	},
+	"board": {
+		"list":   {flags: listCompletionFlags},
+		"get":    {positionals: []string{valueBoard}},
+		"create": {flags: map[string]string{"name": valueText, "all-access": "", "auto-postpone-days": valueText, "public-description": valueText}},
+		"update": {
+			flags:       map[string]string{"name": valueText, "all-access": "", "no-all-access": "", "auto-postpone-days": valueText, "public-description": valueText, "user-id": valueUser},
+			positionals: []string{valueBoard},
+		},
+		"delete": {flags: map[string]string{"yes": ""}, positionals: []string{valueBoard}},
+		"view":   {flags: map[string]string{"refresh": valueText}, positionals: []string{valueBoard}},
+		"render": {flags: map[string]string{"markdown": "", "width": valueText, "all-lanes": ""}, positionals: []string{valueBoard}},
+		"export": {flags: map[string]string{"o": valueFile, "output": valueFile, "concurrency": valueText}, positionals: []string{valueBoard}},
+		"import": {flags: map[string]string{"into": valueBoard, "new-name": valueText, "dry-run": "", "mapping": valueFile}, positionals: []string{valueFile}},
+	},
+	"card": {
+		"list":     {flags: mergeCompletionFlags(cardFilterCompletionFlags, listCompletionFlags)},
+		"get":      {positionals: []string{valueCard}},
+		"create":   {flags: map[string]string{"board-id": valueBoard, "title": valueText, "description": valueText, "status": "status", "tag-id": valueTag, "image": valueFile, "step": valueText, "steps-file": valueFile, "description-file": valueFile, "edit": ""}},
+		"update":   {flags: map[string]string{"title": valueText, "description": valueText, "description-file": valueFile, "clear-description": "", "edit": "", "status": "status", "tag-id": valueTag, "image": valueFile}, positionals: []string{valueCard}},
+		"delete":   {flags: map[string]string{"yes": ""}, positionals: []string{valueCard}},
+		"close":    {positionals: []string{valueCard}},
+		"reopen":   {positionals: []string{valueCard}},
+		"not-now":  {positionals: []string{valueCard}},
+		"triage":   {flags: map[string]string{"column-id": valueColumn}, positionals: []string{valueCard}},
+		"untriage": {positionals: []string{valueCard}},
+		"tag":      {flags: map[string]string{"title": valueTagTitle}, positionals: []string{valueCard}},
+		"assign":   {flags: map[string]string{"assignee-id": valueUser}, positionals: []string{valueCard}},
+		"watch":    {positionals: []string{valueCard}},
+		"unwatch":  {positionals: []string{valueCard}},
+		"bulk":     {flags: mergeCompletionFlags(cardFilterCompletionFlags, map[string]string{"limit": valueText, "concurrency": valueText, "yes": ""}), positionals: []string{"bulk-action"}},
+	},
+	"comment": {
+		"list":   {flags: listCompletionFlags, positionals: []string{valueCard}},
+		"get":    {positionals: []string{valueCard, valueComment}},
+		"create": {flags: map[string]string{"body": valueText, "body-file": valueFile, "edit": ""}, positionals: []string{valueCard}},
+		"update": {flags: map[string]string{"body": valueText, "body-file": valueFile, "edit": ""}, positionals: []string{valueCard, valueComment}},
+		"delete": {flags: map[string]string{"yes": ""}, positionals: []string{valueCard, valueComment}},
+	},
+	"step": {
+		"list":       {positionals: []string{valueCard}},
+		"add":        {flags: map[string]string{"content": valueText, "completed": "", "file": valueFile}, positionals: []string{valueCard}},
+		"update":     {flags: map[string]string{"content": valueText}, positionals: []string{valueCard, valueStep}},
+		"complete":   {positionals: []string{valueCard, valueStep}},
+		"uncomplete": {positionals: []string{valueCard, valueStep}},
+		"delete":     {positionals: []string{valueCard, valueStep}},
+	},
+	"tag": {
+		"list": {flags: listCompletionFlags},
+	},
+	"column": {
+		"list":   {flags: mergeCompletionFlags(map[string]string{"board-id": valueBoard}, listCompletionFlags)},
+		"get":    {flags: map[string]string{"board-id": valueBoard}, positionals: []string{valueColumn}},
+		"create": {flags: map[string]string{"board-id": valueBoard, "name": valueText, "color": valueText}},
+		"update": {flags: map[string]string{"board-id": valueBoard, "name": valueText, "color": valueText}, positionals: []string{valueColumn}},
+		"delete": {flags: map[string]string{"board-id": valueBoard, "yes": ""}, positionals: []string{valueColumn}},
+	},
+	"user": {
+		"list":       {flags: listCompletionFlags},
+		"get":        {positionals: []string{valueUser}},
+		"update":     {flags: map[string]string{"name": valueText, "avatar": valueFile}, positionals: []string{valueUser}},
+		"deactivate": {flags: map[string]string{"yes": ""}, positionals: []string{valueUser}},
+	},
+	"notification": {
+		"list":     {flags: mergeCompletionFlags(map[string]string{"unread": ""}, listCompletionFlags)},
+		"read":     {positionals: []string{valueNotification}},
+		"unread":   {positionals: []string{valueNotification}},
+		"read-all": {},
+	},
+	"batch": {
+		"run": {flags: map[string]string{"continue-on-error": "", "dry-run": "", "checkpoint": valueFile, "report": valueFile}, positionals: []string{valueFile}},
+	},
+	"history": {
+		"list": {flags: map[string]string{"limit": valueText}},
+		"show": {},
+	},
+	"undo": {
+		"": {},
+	},
+	"completion": {
+		"": {positionals: []string{"shell"}},
+	},
+	"dev": {
+		"fake-server": {flags: map[string]string{"port": valueText, "host": valueText, "token": valueText, "empty": ""}},
+	},
+	"help": {
+		"": {positionals: []string{valueCommand}},
+	},
+}
+
+func mergeCompletionFlags(sets ...map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, set := range sets {
+		for name, kind := range set {
+			out[name] = kind
+		}
+	}
+	return out
+}
+
+func runCompletion(ctx Context, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, helpForCompletion())
+		return 2
+	}
+	switch args[0] {
+	case "bash":
+		fmt.Fprint(os.Stdout, bashCompletion)
+	case "zsh":
+		fmt.Fprint(os.Stdout, zshCompletion)
+	case "fish":
+		fmt.Fprint(os.Stdout, fishCompletion)
+	default:
+		return handleErr(helpForCompletion(), UsageError{Msg: fmt.Sprintf("unsupported shell %q", args[0])})
+	}
+	return 0
+}
+
+// completionState is what the words before the cursor say about the
+// command being completed.
+type completionState struct {
+	globals     []string
+	flags       map[string]string
+	positionals []string
+}
+
+// runComplete implements the hidden __complete command used by the shell
+// scripts. args are the words after the program name, the last one being
+// the word under the cursor. Suggestions are printed one per line as
+// "value<TAB>description". Errors are swallowed: completion must never
+// print noise into the user's prompt.
+func runComplete(args []string) int {
+	cur := ""
+	if len(args) > 0 {
+		cur = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+	state := completionState{flags: map[string]string{}}
+
+	i, pending := consumeCompletionFlags(args, globalCompletionFlags, state.flags, nil)
+	state.globals = args[:i]
+	if pending != "" {
+		printCompletions(completeValues(state, pending, cur, ""))
+		return 0
+	}
+	if i == len(args) {
+		if strings.HasPrefix(cur, "-") {
+			printCompletions(completeFlags(globalCompletionFlags, state, cur))
+			return 0
+		}
+		printCompletions(filterCompletions(commandCompletions(), cur))
+		return 0
+	}
+
+	subs, ok := completionCommands[args[i]]
+	if !ok {
+		return 0
+	}
+	rest := args[i+1:]
+	spec, ok := subs[""]
+	if !ok {
+		if len(rest) == 0 {
+			names := make([]string, 0, len(subs))
+			for name := range subs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			printCompletions(filterCompletions(names, cur))
+			return 0
+		}
+		if spec, ok = subs[rest[0]]; !ok {
+			return 0
+		}
+		rest = rest[1:]
+	}
+
+	state.flags = map[string]string{}
+	_, pending = consumeCompletionFlags(rest, spec.flags, state.flags, &state.positionals)
+	switch {
+	case pending != "":
+		printCompletions(completeValues(state, pending, cur, ""))
+	case strings.HasPrefix(cur, "--") && strings.Contains(cur, "="):
+		name, value, _ := strings.Cut(strings.TrimPrefix(cur, "--"), "=")
+		if kind := spec.flags[name]; kind != "" {
+			printCompletions(completeValues(state, kind, value, "--"+name+"="))
+		}
+	case strings.HasPrefix(cur, "-"):
+		printCompletions(completeFlags(spec.flags, state, cur))
+	case len(state.positionals) < len(spec.positionals):
+		printCompletions(completeValues(state, spec.positionals[len(state.positionals)], cur, ""))
+	}
+	return 0
+}
+
+// consumeCompletionFlags walks words, recording flag values in flags and,
+// when positionals is nil, stopping at the first non-flag word. It returns
+// the index where it stopped and, if the last word is a flag still waiting
+// for its value, that flag's value kind.
+func consumeCompletionFlags(words []string, spec map[string]string, flags map[string]string, positionals *[]string) (int, string) {
+	i := 0
+	for i < len(words) {
+		word := words[i]
+		if word == "--" || !strings.HasPrefix(word, "-") || word == "-" {
+			if positionals == nil {
+				return i, ""
+			}
+			if word != "--" {
+				*positionals = append(*positionals, word)
+			}
+			i++
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+		kind := spec[name]
+		if kind != "" && !hasValue {
+			if i+1 == len(words) {
+				return i, kind
+			}
+			value = words[i+1]
+			i++
+		}
+		flags[name] = value
+		i++
+	}
+	return i, ""
+}
+
+func commandCompletions() []string {
+	names := make([]string, 0, len(completionCommands))
+	for name := range completionCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func completeFlags(spec map[string]string, state completionState, cur string) []string {
+	names := make([]string, 0, len(spec))
+	for name := range spec {
+		if _, seen := state.flags[name]; seen && !repeatableCompletionFlag(name) {
+			continue
+		}
+		if len(name) == 1 {
+			names = append(names, "-"+name)
+		} else {
+			names = append(names, "--"+name)
+		}
+	}
+	sort.Strings(names)
+	return filterCompletions(names, cur)
+}
+
+func repeatableCompletionFlag(name string) bool {
+	switch name {
+	case "board-id", "tag-id", "assignee-id", "creator-id", "closer-id", "card-id", "term", "user-id", "step":
+		return true
+	}
+	return false
+}
+
+func completeValues(state completionState, kind, cur, prefix string) []string {
+	if values, ok := completionValues[kind]; ok {
+		return withCompletionPrefix(filterCompletions(values, cur), prefix)
+	}
+	switch kind {
+	case valueFile:
+		return []string{completionFiles}
+	case valueCommand:
+		return filterCompletions(commandCompletions(), cur)
+	case valueText, "":
+		return nil
+	}
+
+	ctx, _, _, _, err := parseGlobal(append([]string{"fizzy-cli"}, state.globals...))
+	if err != nil {
+		return nil
+	}
+	if kind == valueProfile {
+		return filterCompletions(ctx.Config.ProfileNames(), cur)
+	}
+	if !completionClient(&ctx) {
+		return nil
+	}
+	candidates, err := liveCandidates(ctx, kind, state)
+	if err != nil {
+		return nil
+	}
+	out := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if !strings.HasPrefix(c.ID, cur) {
+			continue
+		}
+		label := c.Label
+		if c.Detail != "" {
+			label = fmt.Sprintf("%s (%s)", label, c.Detail)
+		}
+		if label == c.ID {
+			out = append(out, prefix+c.ID)
+			continue
+		}
+		out = append(out, prefix+c.ID+"\t"+completionLabel(label))
+	}
+	return out
+}
+
+// liveCandidates fetches suggestions from the API through the lookup cache
+// shared with name resolution, using a shorter TTL so tab stays fast
+// without going stale.
+func liveCandidates(ctx Context, kind string, state completionState) ([]candidate, error) {
+	switch kind {
+	case valueAccount:
+		return cachedCandidates(ctx, valueAccount, "", func() ([]candidate, error) {
+			identity, _, err := ctx.Client.Identity.Get(requestContext())
+			if err != nil {
+				return nil, err
+			}
+			out := make([]candidate, 0, len(identity.Accounts))
+			for _, a := range identity.Accounts {
+				out = append(out, candidate{ID: normalizeAccount(a.Slug), Label: a.Name})
+			}
+			return out, nil
+		})
+	}
+	if ensureAccount(ctx) != nil {
+		return nil, nil
+	}
+
+	switch kind {
+	case valueBoard:
+		return cachedCandidates(ctx, "board", "", func() ([]candidate, error) {
+			return boardCandidates(ctx)
+		})
+	case valueUser:
+		return cachedCandidates(ctx, "user", "", func() ([]candidate, error) {
+			return userCandidates(ctx)
+		})
+	case valueTag, valueTagTitle:
+		tags, err := cachedCandidates(ctx, "tag", "", func() ([]candidate, error) {
+			return tagCandidates(ctx)
+		})
+		if kind == valueTagTitle {
+			for i := range tags {
+				tags[i].ID = tags[i].Label
+			}
+		}
+		return tags, err
+	case valueColumn:
+		boardID, err := completionBoardID(ctx, state)
+		if err != nil || boardID == "" {
+			return nil, err
+		}
+		return cachedCandidates(ctx, "column", boardID, func() ([]candidate, error) {
+			return columnCandidates(ctx, boardID)
+		})
+	case valueCard:
+		return cachedCandidates(ctx, "card", "", func() ([]candidate, error) {
+			cards, err := ctx.Client.Cards.Iter(&fizzy.CardListOptions{ListOptions: fizzy.ListOptions{MaxPages: 1}}).All(requestContext())
+			if err != nil {
+				return nil, err
+			}
+			out := make([]candidate, 0, len(cards))
+			for _, c := range cards {
+				out = append(out, candidate{ID: strconv.Itoa(c.Number), Label: c.Title, Detail: c.Board.Name})
+			}
+			return out, nil
+		})
+	case valueComment:
+		if len(state.positionals) == 0 {
+			return nil, nil
+		}
+		number, err := parseCardNumber(state.positionals[0])
+		if err != nil {
+			return nil, err
+		}
+		return cachedCandidates(ctx, "comment", strconv.Itoa(number), func() ([]candidate, error) {
+			comments, err := ctx.Client.Comments.Iter(number, &fizzy.ListOptions{MaxPages: 1}).All(requestContext())
+			if err != nil {
+				return nil, err
+			}
+			out := make([]candidate, 0, len(comments))
+			for _, c := range comments {
+				out = append(out, candidate{ID: c.ID, Label: c.Body.Plain, Detail: c.Creator.Name})
+			}
+			return out, nil
+		})
+	case valueStep:
+		if len(state.positionals) == 0 {
+			return nil, nil
+		}
+		number, err := parseCardNumber(state.positionals[0])
+		if err != nil {
+			return nil, err
+		}
+		return cachedCandidates(ctx, "step", strconv.Itoa(number), func() ([]candidate, error) {
+			steps, _, err := ctx.Client.Steps.List(requestContext(), number)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]candidate, 0, len(steps))
+			for _, s := range steps {
+				detail := "open"
+				if s.Completed {
+					detail = "done"
+				}
+				out = append(out, candidate{ID: s.ID, Label: s.Content, Detail: detail})
+			}
+			return out, nil
+		})
+	case valueNotification:
+		return cachedCandidates(ctx, "notification", "", func() ([]candidate, error) {
+			notifications, err := ctx.Client.Notifications.Iter(&fizzy.NotificationListOptions{ListOptions: fizzy.ListOptions{MaxPages: 1}}).All(requestContext())
+			if err != nil {
+				return nil, err
+			}
+			out := make([]candidate, 0, len(notifications))
+			for _, n := range notifications {
+				out = append(out, candidate{ID: n.ID, Label: n.Title, Detail: n.Card.Title})
+			}
+			return out, nil
+		})
+	}
+	return nil, nil
+}
+
+// completionBoardID finds the board whose columns to suggest: --board-id on
+// the command line, or the board of the card being triaged.
+func completionBoardID(ctx Context, state completionState) (string, error) {
+	if board := state.flags["board-id"]; board != "" {
+		return resolveBoard(ctx, board)
+	}
+	if len(state.positionals) == 0 {
+		return "", nil
+	}
+	number, err := parseCardNumber(state.positionals[0])
+	if err != nil {
+		return "", err
+	}
+	boards, err := cachedCandidates(ctx, "card-board", strconv.Itoa(number), func() ([]candidate, error) {
+		card, _, err := ctx.Client.Cards.Get(requestContext(), number)
+		if err != nil {
+			return nil, err
+		}
+		return []candidate{{ID: card.Board.ID, Label: card.Board.Name}}, nil
+	})
+	if err != nil || len(boards) == 0 {
+		return "", err
+	}
+	return boards[0].ID, nil
+}
+
+func cachedCandidates(ctx Context, kind, scope string, fetch func() ([]candidate, error)) ([]candidate, error) {
+	store := resolveCache(ctx)
+	if store != nil && (store.TTL <= 0 || store.TTL > completionCacheTTL) {
+		store.TTL = completionCacheTTL
+	}
+	key := candidateKey(ctx, kind, scope)
+	var candidates []candidate
+	if store.Get(key, &candidates) {
+		return candidates, nil
+	}
+	candidates, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	_ = store.Put(key, candidates)
+	return candidates, nil
+}
+
+// completionClient sets up a client for live suggestions. It never
+// prompts: profiles that keep credentials in the encrypted store only get
+// live suggestions when FIZZY_PASSPHRASE is set.
+func completionClient(ctx *Context) bool {
+	if strings.TrimSpace(ctx.Profile.CredentialStore) == credstore.BackendEncrypted && os.Getenv("FIZZY_PASSPHRASE") == "" {
+		return false
+	}
+	if err := loadCredentials(ctx); err != nil {
+		return false
+	}
+	ctx.Retry.MaxAttempts = 1
+	transport := newTransport(*ctx, ctx.Token, ctx.SessionToken)
+	transport.HTTP = &http.Client{Timeout: completionTimeout}
+	ctx.Client = fizzy.NewClient(transport, ctx.Account)
+	return ensureToken(*ctx) == nil
+}
+
+func filterCompletions(values []string, cur string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.HasPrefix(v, cur) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func withCompletionPrefix(values []string, prefix string) []string {
+	if prefix == "" {
+		return values
+	}
+	for i := range values {
+		values[i] = prefix + values[i]
+	}
+	return values
+}
+
+func completionLabel(label string) string {
+	label = strings.Join(strings.Fields(label), " ")
+	if len([]rune(label)) > 60 {
+		label = string([]rune(label)[:59]) + "…"
+	}
+	return label
+}
+
+func printCompletions(values []string) {
+	for _, v := range values {
+		fmt.Fprintln(os.Stdout, v)
+	}
+}
+
+const bashCompletion = `# bash completion for fizzy-cli
+# Install: fizzy-cli completion bash > /etc/bash_completion.d/fizzy-cli
+#      or: source <(fizzy-cli completion bash)
+
+_fizzy_cli() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    local line
+    COMPREPLY=()
+    for line in $(fizzy-cli __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null); do
+        if [[ "$line" == ":files" ]]; then
+            COMPREPLY+=($(compgen -f -- "$cur"))
+            continue
+        fi
+        COMPREPLY+=("${line%%$'\t'*}")
+    done
+}
+
+complete -o filenames -F _fizzy_cli fizzy-cli
+`
+
+const zshCompletion = `#compdef fizzy-cli
+# zsh completion for fizzy-cli
+# Install: fizzy-cli completion zsh > "${fpath[1]}/_fizzy-cli"
+
+_fizzy_cli() {
+    local -a candidates
+    local line value desc files=0
+    for line in "${(@f)$(fizzy-cli __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -z "$line" ]] && continue
+        if [[ "$line" == ":files" ]]; then
+            files=1
+            continue
+        fi
+        value="${line%%$'\t'*}"
+        value="${value//:/\\:}"
+        if [[ "$line" == *$'\t'* ]]; then
+            desc="${line#*$'\t'}"
+            candidates+=("${value}:${desc}")
+        else
+            candidates+=("${value}")
+        fi
+    done
+    if (( files )); then
+        _files
+    fi
+    if (( ${#candidates} )); then
+        _describe 'fizzy-cli' candidates
+    fi
+}
+
+if [[ "$funcstack[1]" == "_fizzy-cli" ]]; then
+    _fizzy_cli "$@"
+else
+    compdef _fizzy_cli fizzy-cli
+fi
+`
+
+const fishCompletion = `# fish completion for fizzy-cli
+# Install: fizzy-cli completion fish > ~/.config/fish/completions/fizzy-cli.fish
+
+function __fizzy_cli_complete
+    set -l args (commandline -opc)[2..-1]
+    set -l cur (commandline -ct)
+    for line in (fizzy-cli __complete $args $cur 2>/dev/null)
+        if test "$line" = ":files"
+            __fish_complete_path $cur
+        else
+            echo $line
+        end
+    end
+end
+
+complete -c fizzy-cli -f -a '(__fizzy_cli_complete)'
+`
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"fizzy-cli/internal/termtext"
+	"fizzy-cli/pkg/fizzy"
+)
+
+var errNotConfirmed = errors.New("aborted: confirmation did not match")
+
+// confirmDestroy asks before a destructive command runs. describe fetches
+// what is about to be destroyed and the text the user must type to go
+// ahead. --yes and --dry-run skip the prompt; without a terminal the
+// command is refused so that scripts have to opt in.
+func confirmDestroy(ctx Context, command string, describe func(context.Context) (string, string, error)) error {
+	if ctx.AssumeYes || ctx.DryRun {
+		return nil
+	}
+	if !interactive() {
+		return UsageError{Msg: fmt.Sprintf("%s needs --yes (or FIZZY_ASSUME_YES=1) when not running in a terminal", command)}
+	}
+	summary, expect, err := describe(requestContext())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, summary)
+	answer, err := readSecret(fmt.Sprintf("Type %q to confirm", expect))
+	if err != nil {
+		return err
+	}
+	if answer != expect {
+		return errNotConfirmed
+	}
+	return nil
+}
+
+// confirmCardLimit caps the cards counted for the board delete prompt, so
+// that it does not page through a large board.
+const confirmCardLimit = 100
+
+func describeBoardDelete(client *fizzy.Client, boardID string) func(context.Context) (string, string, error) {
+	return func(ctx context.Context) (string, string, error) {
+		board, _, err := client.Boards.Get(ctx, boardID)
+		if err != nil {
+			return "", "", err
+		}
+		// The lists overlap, as for an export, so count each card once.
+		seen := map[int]bool{}
+		for _, s := range exportStates {
+			it := client.Cards.Iter(&fizzy.CardListOptions{BoardIDs: []string{boardID}, IndexedBy: s.indexedBy, ListOptions: fizzy.ListOptions{Limit: confirmCardLimit}})
+			for len(seen) < confirmCardLimit && it.Next(ctx) {
+				seen[it.Item().Number] = true
+			}
+			if err := it.Err(); err != nil {
+				return "", "", err
+			}
+		}
+		cards := plural(len(seen), "card")
+		if len(seen) >= confirmCardLimit {
+			cards = fmt.Sprintf("at least %d cards", confirmCardLimit)
+		}
+		return fmt.Sprintf("This permanently deletes board %q with %s.", board.Name, cards), board.Name, nil
+	}
+}
+
+func describeCardDelete(client *fizzy.Client, number int) func(context.Context) (string, string, error) {
+	return func(ctx context.Context) (string, string, error) {
+		card, _, err := client.Cards.Get(ctx, number)
+		if err != nil {
+			return "", "", err
+		}
+		comments, err := client.Comments.Iter(number, nil).All(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		return fmt.Sprintf("This permanently deletes card #%d %q on %s, with %s and %s.",
+			card.Number, card.Title, card.Board.Name, plural(len(comments), "comment"), plural(len(card.Steps), "step")), strconv.Itoa(card.Number), nil
+	}
+}
+
+func describeColumnDelete(client *fizzy.Client, boardID, columnID string) func(context.Context) (string, string, error) {
+	return func(ctx context.Context) (string, string, error) {
+		column, _, err := client.Columns.Get(ctx, boardID, columnID)
+		if err != nil {
+			return "", "", err
+		}
+		cards, err := client.Cards.Iter(&fizzy.CardListOptions{BoardIDs: []string{boardID}}).All(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		count := 0
+		for _, card := range cards {
+			if card.Column != nil && card.Column.ID == columnID {
+				count++
+			}
+		}
+		return fmt.Sprintf("This deletes column %q, which holds %s.", column.Name, plural(count, "open card")), column.Name, nil
+	}
+}
+
+func describeCommentDelete(client *fizzy.Client, number int, commentID string) func(context.Context) (string, string, error) {
+	return func(ctx context.Context) (string, string, error) {
+		comment, _, err := client.Comments.Get(ctx, number, commentID)
+		if err != nil {
+			return "", "", err
+		}
+		body := strings.TrimRight(termtext.Fit(strings.Join(strings.Fields(comment.Body.Plain), " "), 60), " ")
+		return fmt.Sprintf("This permanently deletes %s's comment on card #%d: %q", comment.Creator.Name, number, body), "delete", nil
+	}
+}
+
+func describeUserDeactivate(client *fizzy.Client, userID string) func(context.Context) (string, string, error) {
+	return func(ctx context.Context) (string, string, error) {
+		user, _, err := client.Users.Get(ctx, userID)
+		if err != nil {
+			return "", "", err
+		}
+		expect := firstNonEmpty(user.Email, user.Name)
+		return fmt.Sprintf("This deactivates %s <%s>, who will lose access to the account.", user.Name, user.Email), expect, nil
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"fizzy-cli/pkg/fizzy"
+	"fizzy-cli/pkg/fizzy/fizzytest"
+)
+
+// overlappingServer serves the fake with every card list returning all the
+// board's cards, whatever indexed_by asks for, and counts card list pages.
+func overlappingServer(t *testing.T, fake *fizzytest.Server, pages *atomic.Int32) *fizzy.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/"+fizzytest.Account+"/cards" {
+			pages.Add(1)
+			query := r.URL.Query()
+			query.Del("indexed_by")
+			r.URL.RawQuery = query.Encode()
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return fizzy.NewClient(fizzy.NewTransport(srv.URL, "test-token", "", "test"), fizzytest.Account)
+}
+
+func TestDescribeBoardDeleteCountsEachCardOnce(t *testing.T) {
+	fake := fizzytest.New()
+	board := fake.AddBoard("Small")
+	for i := 1; i <= 3; i++ {
+		fake.AddCard(board, fmt.Sprintf("Card %d", i))
+	}
+	var pages atomic.Int32
+	summary, expect, err := describeBoardDelete(overlappingServer(t, fake, &pages), board)(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `This permanently deletes board "Small" with 3 cards.`; summary != want {
+		t.Errorf("summary = %q, want %q", summary, want)
+	}
+	if expect != "Small" {
+		t.Errorf("expect = %q, want the board name", expect)
+	}
+}
+
+func TestDescribeBoardDeleteStopsCounting(t *testing.T) {
+	fake := fizzytest.New()
+	board := fake.AddBoard("Large")
+	for i := 1; i <= 1000; i++ {
+		fake.AddCard(board, fmt.Sprintf("Card %d", i))
+	}
+	var pages atomic.Int32
+	summary, _, err := describeBoardDelete(overlappingServer(t, fake, &pages), board)(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `This permanently deletes board "Large" with at least 100 cards.`; summary != want {
+		t.Errorf("summary = %q, want %q", summary, want)
+	}
+	if n := pages.Load(); n > 4 {
+		t.Errorf("read %d pages of cards, want no more than needed for 100", n)
+	}
+}
@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"fizzy-cli/internal/config"
+	"fizzy-cli/internal/credstore"
+	"fizzy-cli/internal/tui"
+)
+
+func credentialStore(ctx Context, profile config.Profile) (credstore.Store, error) {
+	store, err := credstore.Open(profile.CredentialStore, credstore.Options{
+		ConfigPath:    ctx.ConfigPath,
+		EncryptedPath: filepath.Join(filepath.Dir(ctx.ConfigPath), "credentials.enc"),
+		Passphrase:    readPassphrase,
+	})
+	if err != nil {
+		return nil, UsageError{Msg: err.Error()}
+	}
+	return store, nil
+}
+
+func credentialKey(ctx Context, name string, profile config.Profile) credstore.Key {
+	return credstore.Key{Profile: name, BaseURL: firstNonEmpty(profile.BaseURL, ctx.BaseURL, defaultBaseURL)}
+}
+
+// loadCredentials fills in the token from the profile's credential store.
+// The plaintext file backend has already been read along with the config.
+func loadCredentials(ctx *Context) error {
+	if ctx.Token != "" || ctx.SessionToken != "" || usesFileStore(ctx.Profile) {
+		return nil
+	}
+	store, err := credentialStore(*ctx, ctx.Profile)
+	if err != nil {
+		return err
+	}
+	creds, err := store.Get(credentialKey(*ctx, ctx.ProfileName, ctx.Profile))
+	if err != nil {
+		return err
+	}
+	ctx.Token = creds.Token
+	ctx.SessionToken = creds.SessionToken
+	return nil
+}
+
+// saveCredentials stores creds for the current profile and returns where
+// they went. Empty creds erase the profile's entry.
+func saveCredentials(ctx Context, creds credstore.Credentials) (string, error) {
+	store, err := credentialStore(ctx, ctx.Profile)
+	if err != nil {
+		return "", err
+	}
+	key := credentialKey(ctx, ctx.ProfileName, ctx.Profile)
+	if creds.Empty() {
+		err = store.Erase(key)
+	} else {
+		err = store.Store(key, creds)
+	}
+	return store.Location(), err
+}
+
+// switchCredentialStore moves the current profile's credentials from its
+// current backend to spec.
+func switchCredentialStore(ctx Context, profile config.Profile, spec string) error {
+	if err := credstore.ValidateSpec(spec); err != nil {
+		return UsageError{Msg: err.Error()}
+	}
+	oldStore, err := credentialStore(ctx, ctx.Profile)
+	if err != nil {
+		return err
+	}
+	next := profile
+	next.CredentialStore = spec
+	if next.CredentialStore == credstore.BackendFile {
+		next.CredentialStore = ""
+	}
+	newStore, err := credentialStore(ctx, next)
+	if err != nil {
+		return err
+	}
+	if oldStore.Name() == newStore.Name() {
+		return saveProfile(ctx, next)
+	}
+
+	key := credentialKey(ctx, ctx.ProfileName, ctx.Profile)
+	creds, err := oldStore.Get(key)
+	if err != nil {
+		return err
+	}
+	next.Token = ""
+	next.SessionToken = ""
+	if err := saveProfile(ctx, next); err != nil {
+		return err
+	}
+	if !creds.Empty() {
+		if err := newStore.Store(key, creds); err != nil {
+			if restoreErr := saveProfile(ctx, profile); restoreErr != nil {
+				return errors.Join(err, restoreErr)
+			}
+			return err
+		}
+	}
+	if oldStore.Name() != credstore.BackendFile {
+		return oldStore.Erase(key)
+	}
+	return nil
+}
+
+// moveCredentials re-keys credentials kept outside the config file when a
+// profile is renamed.
+func moveCredentials(ctx Context, oldName, newName string, profile config.Profile) error {
+	if usesFileStore(profile) {
+		return nil
+	}
+	store, err := credentialStore(ctx, profile)
+	if err != nil {
+		return err
+	}
+	oldKey := credentialKey(ctx, oldName, profile)
+	creds, err := store.Get(oldKey)
+	if err != nil || creds.Empty() {
+		return err
+	}
+	if err := store.Store(credentialKey(ctx, newName, profile), creds); err != nil {
+		return err
+	}
+	return store.Erase(oldKey)
+}
+
+func eraseCredentials(ctx Context, name string, profile config.Profile) error {
+	if usesFileStore(profile) {
+		return nil
+	}
+	store, err := credentialStore(ctx, profile)
+	if err != nil {
+		return err
+	}
+	return store.Erase(credentialKey(ctx, name, profile))
+}
+
+func usesFileStore(profile config.Profile) bool {
+	spec := strings.TrimSpace(profile.CredentialStore)
+	return spec == "" || spec == credstore.BackendFile
+}
+
+func readPassphrase(confirm bool) (string, error) {
+	if env := os.Getenv("FIZZY_PASSPHRASE"); env != "" {
+		return env, nil
+	}
+	if !isTTY(os.Stdin) {
+		return "", UsageError{Msg: "the encrypted credential store needs a passphrase; set FIZZY_PASSPHRASE or run in a TTY"}
+	}
+	passphrase, err := readHidden("Passphrase")
+	if err != nil || !confirm {
+		return passphrase, err
+	}
+	again, err := readHidden("Repeat passphrase")
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", errors.New("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// readHidden reads a line from the terminal with echo turned off. It
+// refuses to read when echo can't be turned off rather than show the
+// secret. An interrupt restores echo before the process exits.
+func readHidden(label string) (string, error) {
+	restore, err := tui.DisableEcho(os.Stdin)
+	if err != nil {
+		return "", UsageError{Msg: "cannot hide the passphrase as it is typed; set FIZZY_PASSPHRASE instead"}
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	defer func() {
+		signal.Stop(interrupt)
+		close(done)
+		restore()
+	}()
+	go func() {
+		select {
+		case <-interrupt:
+			restore()
+			fmt.Fprintln(os.Stderr)
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	text, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(text), nil
+}
+
+// needsCredentials reports whether the command reads stored credentials.
+// Commands that only manage config or replace credentials skip the store so
+// they never prompt for a passphrase.
+func needsCredentials(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "help", "profile", "config", "completion", "__complete", "dev", "history":
+		return false
+	case "auth":
+		return len(args) > 1 && args[1] == "status"
+	case "account":
+		return len(args) > 1 && args[1] == "list"
+	}
+	return true
+}
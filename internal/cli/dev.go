@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+
+	"fizzy-cli/pkg/fizzy/fizzytest"
+)
+
+func runDev(ctx Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, helpForDev())
+		return 2
+	}
+	switch args[0] {
+	case "fake-server":
+		fs := flag.NewFlagSet("dev fake-server", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		port := fs.Int("port", 8484, "Port to listen on, 0 for any free port")
+		host := fs.String("host", "127.0.0.1", "Address to listen on")
+		token := fs.String("token", "", "Only accept this bearer token")
+		empty := fs.Bool("empty", false, "Start without demo data")
+		if err := fs.Parse(args[1:]); err != nil {
+			return usageError(helpForDev(), err)
+		}
+		if *port < 0 || *port > 65535 {
+			return handleErr(helpForDev(), UsageError{Msg: "--port must be between 0 and 65535"})
+		}
+		fake := fizzytest.New()
+		fake.Token = *token
+		if !*empty {
+			fake.Seed()
+		}
+		listener, err := net.Listen("tcp", net.JoinHostPort(*host, strconv.Itoa(*port)))
+		if err != nil {
+			return handleErr(helpForDev(), err)
+		}
+		baseURL := "http://" + listener.Addr().String()
+		tokenHint := *token
+		if tokenHint == "" {
+			tokenHint = "anything"
+		}
+		fmt.Fprintf(os.Stdout, "Fake Fizzy API listening on %s (account %s, magic-link code %s).\n", baseURL, fizzytest.Account, fizzytest.DefaultMagicCode)
+		fmt.Fprintf(os.Stdout, "Try: FIZZY_BASE_URL=%s FIZZY_TOKEN=%s FIZZY_ACCOUNT=%s fizzy-cli board list\n", baseURL, tokenHint, fizzytest.Account)
+		if err := http.Serve(listener, fake); err != nil {
+			return handleErr(helpForDev(), err)
+		}
+		return 0
+	default:
+		fmt.Fprint(os.Stderr, helpForDev())
+		return 2
+	}
+}
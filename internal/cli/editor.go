@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"fizzy-cli/pkg/fizzy"
+)
+
+// editScissors separates the commented header of an edit buffer from the
+// content. Everything up to and including it is discarded, so content may
+// itself start with Markdown headings.
+const editScissors = "# ------------------------ >8 ------------------------"
+
+var errEmptyEdit = errors.New("aborted: empty buffer")
+
+func editorCommand() string {
+	return firstNonEmpty(strings.TrimSpace(os.Getenv("VISUAL")), strings.TrimSpace(os.Getenv("EDITOR")), "vi")
+}
+
+// editText opens $VISUAL or $EDITOR on a temp file holding header as
+// comments followed by content, and returns the edited content. An empty
+// result aborts with errEmptyEdit.
+func editText(header []string, content string) (string, error) {
+	f, err := os.CreateTemp("", "fizzy-*.md")
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	buf := &strings.Builder{}
+	for _, line := range header {
+		buf.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+	}
+	buf.WriteString(editScissors + "\n")
+	buf.WriteString(content)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		buf.WriteString("\n")
+	}
+	if _, err := f.WriteString(buf.String()); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	editor := editorCommand()
+	// Run through the shell so editors configured with arguments, such as
+	// "code --wait", work as they do for git.
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "fizzy-cli", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	text := stripEditHeader(string(data))
+	if text == "" {
+		return "", errEmptyEdit
+	}
+	return text, nil
+}
+
+// stripEditHeader removes the commented header. Without the scissors line,
+// leading comment lines are dropped instead.
+func stripEditHeader(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if i := strings.Index(text, editScissors+"\n"); i >= 0 {
+		text = text[i+len(editScissors)+1:]
+	} else if strings.HasSuffix(text, editScissors) {
+		text = ""
+	} else {
+		lines := strings.Split(text, "\n")
+		for len(lines) > 0 && strings.HasPrefix(lines[0], "#") {
+			lines = lines[1:]
+		}
+		text = strings.Join(lines, "\n")
+	}
+	return strings.TrimSpace(text)
+}
+
+// splitCardText reads the title from the first line of an edited card and
+// the description from the rest.
+func splitCardText(text string) (string, string) {
+	title, description, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(title), strings.TrimSpace(description)
+}
+
+func cardEditText(title, description string) string {
+	if description == "" {
+		return title + "\n"
+	}
+	return title + "\n\n" + description + "\n"
+}
+
+func cardEditHeader(card *fizzy.Card, board string) []string {
+	header := []string{}
+	if card == nil {
+		header = append(header, fmt.Sprintf("New card on board %s.", board))
+	} else {
+		header = append(header,
+			fmt.Sprintf("Card #%d: %s", card.Number, card.Title),
+			fmt.Sprintf("Board: %s, status: %s", card.Board.Name, firstNonEmpty(card.Status, "unknown")),
+		)
+	}
+	return append(header,
+		"",
+		"The first line is the title, the lines after it the description.",
+		"Everything up to the line below is ignored; an empty file aborts.",
+	)
+}
+
+func commentEditHeader(number int, comment *fizzy.Comment) []string {
+	header := []string{fmt.Sprintf("New comment on card #%d.", number)}
+	if comment != nil {
+		header = []string{
+			fmt.Sprintf("Comment %s on card #%d", comment.ID, number),
+			fmt.Sprintf("By %s, %s", comment.Creator.Name, comment.CreatedAt),
+		}
+	}
+	return append(header,
+		"",
+		"Everything up to the line below is ignored; an empty file aborts.",
+	)
+}
+
+// textInput returns the text given by a flag or read from a file, - for
+// stdin. Setting both is an error.
+func textInput(flagName, value, path string) (string, error) {
+	if path == "" {
+		return value, nil
+	}
+	if value != "" {
+		return "", UsageError{Msg: fmt.Sprintf("--%s and --%s-file cannot be used together", flagName, flagName)}
+	}
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// checkEditInput rejects --edit combined with reading content from stdin,
+// since the editor needs the terminal.
+func checkEditInput(edit bool, flagName, path string) error {
+	if edit && path == "-" {
+		return UsageError{Msg: fmt.Sprintf("--edit cannot be used with --%s-file -", flagName)}
+	}
+	return nil
+}
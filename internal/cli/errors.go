@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"fizzy-cli/pkg/fizzy"
+)
+
+// Exit codes. Scripts can rely on these; they are listed in the help.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitAuth        = 3
+	exitForbidden   = 4
+	exitNotFound    = 5
+	exitValidation  = 6
+	exitRateLimited = 7
+	exitServer      = 8
+	exitNetwork     = 9
+	exitDryRun      = 10
+)
+
+// jsonErrors makes printErr write errors as JSON objects, for --output json
+// and ndjson.
+var jsonErrors bool
+
+// errorInfo classifies err into a kind, as used in JSON errors, and an exit
+// code.
+func errorInfo(err error) (string, int) {
+	var usage UsageError
+	if errors.As(err, &usage) {
+		return "usage", exitUsage
+	}
+	var dryRun *fizzy.DryRunError
+	if errors.As(err, &dryRun) {
+		return "dry_run", exitDryRun
+	}
+	var apiErr *fizzy.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Status == 401:
+			return "unauthorized", exitAuth
+		case apiErr.Status == 403:
+			return "forbidden", exitForbidden
+		case apiErr.Status == 404:
+			return "not_found", exitNotFound
+		case apiErr.Status == 422:
+			return "validation", exitValidation
+		case apiErr.Status == 429:
+			return "rate_limited", exitRateLimited
+		case apiErr.Status >= 500:
+			return "server", exitServer
+		}
+		return "api", exitError
+	}
+	var miss *fizzy.ReplayMissError
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && !errors.As(err, &miss) {
+		return "network", exitNetwork
+	}
+	return "error", exitError
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	_, code := errorInfo(err)
+	return code
+}
+
+type jsonError struct {
+	Error    string             `json:"error"`
+	Message  string             `json:"message"`
+	ExitCode int                `json:"exit_code"`
+	Status   int                `json:"status,omitempty"`
+	Fields   []fizzy.FieldError `json:"fields,omitempty"`
+}
+
+func printErr(err error) {
+	if err == nil {
+		return
+	}
+	if !jsonErrors {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		return
+	}
+	kind, code := errorInfo(err)
+	out := jsonError{Error: kind, Message: err.Error(), ExitCode: code}
+	var apiErr *fizzy.APIError
+	if errors.As(err, &apiErr) {
+		out.Status = apiErr.Status
+		out.Message = apiErr.Message()
+		out.Fields = apiErr.FieldErrors()
+		var creds *credentialsError
+		if out.Message == "" || errors.As(err, &creds) {
+			out.Message = err.Error()
+		}
+	}
+	enc := json.NewEncoder(os.Stderr)
+	enc.SetEscapeHTML(false)
+	enc.Encode(out)
+}
@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"fizzy-cli/internal/archive"
+	"fizzy-cli/pkg/fizzy"
+)
+
+// exportStates lists the card lists a board export reads, most specific
+// first: a card found in more than one keeps the first state.
+var exportStates = []struct{ indexedBy, state string }{
+	{"closed", archive.StateClosed},
+	{"not_now", archive.StateNotNow},
+	{"", archive.StateOpen},
+}
+
+type exportSummary struct {
+	File     string `json:"file,omitempty"`
+	Board    string `json:"board"`
+	Columns  int    `json:"columns"`
+	Cards    int    `json:"cards"`
+	Comments int    `json:"comments"`
+	Images   int    `json:"images"`
+}
+
+func runBoardExport(ctx Context, args []string) int {
+	if len(args) < 2 || strings.HasPrefix(args[1], "-") {
+		return handleErr(helpForBoard(), UsageError{Msg: "board id is required"})
+	}
+	fs := flag.NewFlagSet("board export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var file string
+	fs.StringVar(&file, "o", "", "Output file")
+	fs.StringVar(&file, "output", "", "Output file")
+	concurrency := fs.Int("concurrency", bulkConcurrency, "Requests in flight")
+	if err := fs.Parse(args[2:]); err != nil {
+		return usageError(helpForBoard(), err)
+	}
+	if *concurrency < 1 || *concurrency > maxBulkConcurrency {
+		return handleErr(helpForBoard(), UsageError{Msg: fmt.Sprintf("--concurrency must be between 1 and %d", maxBulkConcurrency)})
+	}
+	boardID, err := resolveBoard(ctx, args[1])
+	if err != nil {
+		return handleErr(helpForBoard(), err)
+	}
+
+	a, err := exportBoard(ctx, boardID, file != "" && archive.IsZip(file), *concurrency)
+	if err != nil {
+		return handleErr(helpForBoard(), err)
+	}
+	var board fizzy.Board
+	if err := json.Unmarshal(a.Board, &board); err != nil {
+		return handleErr(helpForBoard(), err)
+	}
+	summary := exportSummary{File: file, Board: board.Name, Columns: len(a.Columns), Cards: len(a.Cards), Images: len(a.Images)}
+	linked := 0
+	for _, card := range a.Cards {
+		summary.Comments += len(card.Comments)
+		if card.Image != nil && card.Image.Path == "" {
+			linked++
+		}
+	}
+	if linked > 0 {
+		fmt.Fprintf(os.Stderr, "note: %s only linked by URL; export to a .zip file to include them.\n", plural(linked, "card image"))
+	}
+	if file == "" {
+		if err := a.WriteJSON(os.Stdout); err != nil {
+			return handleErr(helpForBoard(), err)
+		}
+		fmt.Fprintln(os.Stderr, exportMessage(summary))
+		return 0
+	}
+	if err := a.WriteFile(file); err != nil {
+		return handleErr(helpForBoard(), err)
+	}
+	if ctx.Output.Structured() {
+		return outputPayload(ctx, summary)
+	}
+	fmt.Fprintln(os.Stdout, exportMessage(summary))
+	return 0
+}
+
+// exportBoard reads a board, its columns and every card with its comments.
+// Card images are downloaded only when withImages is set.
+func exportBoard(ctx Context, boardID string, withImages bool, concurrency int) (*archive.Archive, error) {
+	reqCtx := requestContext()
+	a := archive.New(archive.Source{BaseURL: ctx.BaseURL, Account: ctx.Account}, time.Now())
+	_, resp, err := ctx.Client.Boards.Get(reqCtx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	a.Board = resp.Body
+	columns := ctx.Client.Columns.Iter(boardID, nil)
+	for columns.Next(reqCtx) {
+		a.Columns = append(a.Columns, columns.Raw())
+	}
+	if err := columns.Err(); err != nil {
+		return nil, err
+	}
+
+	var numbers []int
+	states := map[int]string{}
+	for _, s := range exportStates {
+		it := ctx.Client.Cards.Iter(&fizzy.CardListOptions{BoardIDs: []string{boardID}, IndexedBy: s.indexedBy})
+		for it.Next(reqCtx) {
+			number := it.Item().Number
+			if _, ok := states[number]; !ok {
+				states[number] = s.state
+				numbers = append(numbers, number)
+			}
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Ints(numbers)
+
+	cards := make([]archive.Card, len(numbers))
+	images := make([][]byte, len(numbers))
+	errs := make([]error, len(numbers))
+	parallel(len(numbers), concurrency, func(i int) {
+		cards[i], images[i], errs[i] = exportCard(ctx, numbers[i], states[numbers[i]], withImages)
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	a.Cards = cards
+	for i, card := range cards {
+		if images[i] != nil {
+			a.Images[card.Image.Path] = images[i]
+		}
+	}
+	return a, nil
+}
+
+// exportCard reads one card with its comments and, with withImages, the
+// card image.
+func exportCard(ctx Context, number int, state string, withImages bool) (archive.Card, []byte, error) {
+	reqCtx := requestContext()
+	fail := func(err error) (archive.Card, []byte, error) {
+		return archive.Card{}, nil, fmt.Errorf("card #%d: %w", number, err)
+	}
+	card, resp, err := ctx.Client.Cards.Get(reqCtx, number)
+	if err != nil {
+		return fail(err)
+	}
+	out := archive.Card{State: state, Card: resp.Body, Comments: []json.RawMessage{}}
+	comments := ctx.Client.Comments.Iter(number, nil)
+	for comments.Next(reqCtx) {
+		out.Comments = append(out.Comments, comments.Raw())
+	}
+	if err := comments.Err(); err != nil {
+		return fail(err)
+	}
+	if card.ImageURL == "" {
+		return out, nil, nil
+	}
+	out.Image = &archive.Image{URL: card.ImageURL}
+	if !withImages {
+		return out, nil, nil
+	}
+	image, err := ctx.Client.Download(reqCtx, card.ImageURL)
+	if err != nil {
+		return fail(fmt.Errorf("image: %w", err))
+	}
+	out.Image.ContentType = image.Headers.Get("Content-Type")
+	out.Image.Path = archive.ImagePath(number, imageName(card.ImageURL, image))
+	return out, image.Body, nil
+}
+
+// imageName picks a file name for a downloaded image: the one the server
+// suggests, or the last part of the URL, with an extension matching the
+// content type when it has none.
+func imageName(rawURL string, resp *fizzy.Response) string {
+	name := ""
+	if _, params, err := mime.ParseMediaType(resp.Headers.Get("Content-Disposition")); err == nil {
+		name = params["filename"]
+	}
+	if name == "" {
+		if u, err := url.Parse(rawURL); err == nil {
+			name = path.Base(u.Path)
+		}
+	}
+	if path.Ext(name) == "" {
+		if exts, _ := mime.ExtensionsByType(resp.Headers.Get("Content-Type")); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	return name
+}
+
+func exportMessage(s exportSummary) string {
+	msg := fmt.Sprintf("Exported board %q: %s, %s, %s", s.Board, plural(s.Columns, "column"), plural(s.Cards, "card"), plural(s.Comments, "comment"))
+	if s.Images > 0 {
+		msg += ", " + plural(s.Images, "image")
+	}
+	if s.File != "" {
+		msg += " to " + s.File
+	}
+	return msg + "."
+}
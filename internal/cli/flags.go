@@ -1,6 +1,11 @@
 package cli
 
-import "strings"
+import (
+	"flag"
+	"strings"
+
+	"fizzy-cli/pkg/fizzy"
+)
 
 type multiString struct {
 	values []string
@@ -21,3 +26,91 @@ func (m *multiString) Set(value string) error {
 func (m *multiString) Values() []string {
 	return append([]string(nil), m.values...)
 }
+
+type listFlags struct {
+	all      *bool
+	limit    *int
+	pageSize *int
+}
+
+func addListFlags(fs *flag.FlagSet) *listFlags {
+	return &listFlags{
+		all:      fs.Bool("all", false, "Fetch all pages"),
+		limit:    fs.Int("limit", 0, "Stop after N items"),
+		pageSize: fs.Int("page-size", 0, "Items per page"),
+	}
+}
+
+// options returns the paging options for a list command. Without --all or
+// --limit only the first page is fetched.
+func (f *listFlags) options() (fizzy.ListOptions, error) {
+	if *f.limit < 0 {
+		return fizzy.ListOptions{}, UsageError{Msg: "--limit must not be negative"}
+	}
+	if *f.pageSize < 0 {
+		return fizzy.ListOptions{}, UsageError{Msg: "--page-size must not be negative"}
+	}
+	opts := fizzy.ListOptions{Limit: *f.limit, PageSize: *f.pageSize}
+	if !*f.all && *f.limit == 0 {
+		opts.MaxPages = 1
+	}
+	return opts, nil
+}
+
+// cardFilters are the card list filters, shared by card list and card bulk.
+type cardFilters struct {
+	boardIDs, tagIDs, assigneeIDs, creatorIDs, closerIDs, cardIDs, terms multiString
+
+	indexedBy, sortedBy, assignmentStatus, creation, closure *string
+}
+
+func addCardFilters(fs *flag.FlagSet) *cardFilters {
+	f := &cardFilters{
+		indexedBy:        fs.String("indexed-by", "", "Index filter"),
+		sortedBy:         fs.String("sorted-by", "", "Sort order"),
+		assignmentStatus: fs.String("assignment-status", "", "Assignment status"),
+		creation:         fs.String("creation", "", "Creation date filter"),
+		closure:          fs.String("closure", "", "Closure date filter"),
+	}
+	fs.Var(&f.boardIDs, "board-id", "Board ID filter")
+	fs.Var(&f.tagIDs, "tag-id", "Tag ID filter")
+	fs.Var(&f.assigneeIDs, "assignee-id", "Assignee ID filter")
+	fs.Var(&f.creatorIDs, "creator-id", "Creator ID filter")
+	fs.Var(&f.closerIDs, "closer-id", "Closer ID filter")
+	fs.Var(&f.cardIDs, "card-id", "Card ID filter")
+	fs.Var(&f.terms, "term", "Search term")
+	return f
+}
+
+// set reports whether any filter was given.
+func (f *cardFilters) set() bool {
+	for _, m := range []multiString{f.boardIDs, f.tagIDs, f.assigneeIDs, f.creatorIDs, f.closerIDs, f.cardIDs, f.terms} {
+		if len(m.values) > 0 {
+			return true
+		}
+	}
+	for _, s := range []*string{f.indexedBy, f.sortedBy, f.assignmentStatus, f.creation, f.closure} {
+		if *s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// options resolves board, tag and user names and returns the list options.
+func (f *cardFilters) options(ctx Context, list fizzy.ListOptions) (*fizzy.CardListOptions, error) {
+	opts := &fizzy.CardListOptions{
+		ListOptions:      list,
+		CardIDs:          f.cardIDs.Values(),
+		Terms:            f.terms.Values(),
+		IndexedBy:        *f.indexedBy,
+		SortedBy:         *f.sortedBy,
+		AssignmentStatus: *f.assignmentStatus,
+		Creation:         *f.creation,
+		Closure:          *f.closure,
+	}
+	if err := resolveCardFilters(ctx, opts, f.boardIDs.Values(), f.tagIDs.Values(), f.assigneeIDs.Values(), f.creatorIDs.Values(), f.closerIDs.Values()); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
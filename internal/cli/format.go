@@ -1,214 +1,88 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
+
+	"fizzy-cli/pkg/fizzy"
 )
 
 var (
-	boardListHeaders        = []string{"ID", "NAME", "ALL_ACCESS", "CREATED"}
-	cardListHeaders         = []string{"#", "TITLE", "STATUS", "BOARD", "LAST_ACTIVE"}
-	commentListHeaders      = []string{"ID", "CREATOR", "BODY", "CREATED"}
-	tagListHeaders          = []string{"ID", "TITLE"}
-	columnListHeaders       = []string{"ID", "NAME", "COLOR"}
-	userListHeaders         = []string{"ID", "NAME", "ROLE", "EMAIL"}
-	notificationListHeaders = []string{"ID", "READ", "TITLE", "CARD", "CREATED"}
+	boardView        = view[fizzy.Board]{headers: []string{"ID", "NAME", "ALL_ACCESS", "CREATED"}, row: boardListRow, detail: formatBoard}
+	cardView         = view[fizzy.Card]{headers: []string{"#", "TITLE", "STATUS", "BOARD", "LAST_ACTIVE"}, row: cardListRow, detail: formatCard, style: cardListStyle}
+	commentView      = view[fizzy.Comment]{headers: []string{"ID", "CREATOR", "BODY", "CREATED"}, row: commentListRow, detail: formatComment}
+	tagView          = view[fizzy.Tag]{headers: []string{"ID", "TITLE"}, row: tagListRow}
+	columnView       = view[fizzy.Column]{headers: []string{"ID", "NAME", "COLOR"}, row: columnListRow, detail: formatColumn, style: columnListStyle}
+	userView         = view[fizzy.User]{headers: []string{"ID", "NAME", "ROLE", "EMAIL"}, row: userListRow, detail: formatUser}
+	notificationView = view[fizzy.Notification]{headers: []string{"ID", "READ", "TITLE", "CARD", "CREATED"}, row: notificationListRow, style: notificationListStyle}
+	accountView      = view[fizzy.Account]{headers: []string{"SLUG", "NAME", "USER"}, row: accountListRow}
 )
 
-type board struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	AllAccess bool   `json:"all_access"`
-	CreatedAt string `json:"created_at"`
-	Creator   user   `json:"creator"`
-	URL       string `json:"url"`
-}
-
-type card struct {
-	ID           string   `json:"id"`
-	Number       int      `json:"number"`
-	Title        string   `json:"title"`
-	Status       string   `json:"status"`
-	Description  string   `json:"description"`
-	Tags         []string `json:"tags"`
-	Golden       bool     `json:"golden"`
-	LastActiveAt string   `json:"last_active_at"`
-	CreatedAt    string   `json:"created_at"`
-	Board        board    `json:"board"`
-	Creator      user     `json:"creator"`
-	Steps        []step   `json:"steps"`
-}
-
-type comment struct {
-	ID        string `json:"id"`
-	CreatedAt string `json:"created_at"`
-	Body      struct {
-		Plain string `json:"plain_text"`
-	} `json:"body"`
-	Creator user `json:"creator"`
+func accountListRow(a fizzy.Account) []string {
+	return []string{strings.TrimPrefix(a.Slug, "/"), a.Name, a.User.Name}
 }
 
-type tag struct {
-	ID    string `json:"id"`
-	Title string `json:"title"`
+func boardListRow(b fizzy.Board) []string {
+	return []string{b.ID, b.Name, fmt.Sprintf("%t", b.AllAccess), b.CreatedAt}
 }
 
-type column struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Color     string `json:"color"`
-	CreatedAt string `json:"created_at"`
+func cardListRow(c fizzy.Card) []string {
+	return []string{fmt.Sprintf("%d", c.Number), c.Title, c.Status, c.Board.Name, c.LastActiveAt}
 }
 
-type user struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Role  string `json:"role"`
-	Email string `json:"email_address"`
+func commentListRow(c fizzy.Comment) []string {
+	return []string{c.ID, c.Creator.Name, c.Body.Plain, c.CreatedAt}
 }
 
-type notification struct {
-	ID        string `json:"id"`
-	Read      bool   `json:"read"`
-	CreatedAt string `json:"created_at"`
-	Title     string `json:"title"`
-	Body      string `json:"body"`
-	Card      struct {
-		Title string `json:"title"`
-	} `json:"card"`
+func tagListRow(t fizzy.Tag) []string {
+	return []string{t.ID, t.Title}
 }
 
-type identity struct {
-	Accounts []struct {
-		Name string `json:"name"`
-		Slug string `json:"slug"`
-		User user   `json:"user"`
-	} `json:"accounts"`
+func columnListRow(c fizzy.Column) []string {
+	return []string{c.ID, c.Name, c.Color}
 }
 
-type step struct {
-	ID        string `json:"id"`
-	Content   string `json:"content"`
-	Completed bool   `json:"completed"`
+func userListRow(u fizzy.User) []string {
+	return []string{u.ID, u.Name, u.Role, u.Email}
 }
 
-func identityToRows(body []byte) ([][]string, error) {
-	var id identity
-	if err := json.Unmarshal(body, &id); err != nil {
-		return nil, err
+func notificationListRow(n fizzy.Notification) []string {
+	read := "no"
+	if n.Read {
+		read = "yes"
 	}
-	rows := make([][]string, 0, len(id.Accounts))
-	for _, acct := range id.Accounts {
-		rows = append(rows, []string{strings.TrimPrefix(acct.Slug, "/"), acct.Name, acct.User.Name})
-	}
-	return rows, nil
+	return []string{n.ID, read, n.Title, n.Card.Title, n.CreatedAt}
 }
 
-func boardListRows(body []byte) ([][]string, error) {
-	var boards []board
-	if err := json.Unmarshal(body, &boards); err != nil {
-		return nil, err
-	}
-	rows := make([][]string, 0, len(boards))
-	for _, b := range boards {
-		rows = append(rows, []string{b.ID, b.Name, fmt.Sprintf("%t", b.AllAccess), b.CreatedAt})
-	}
-	return rows, nil
-}
-
-func cardListRows(body []byte) ([][]string, error) {
-	var cards []card
-	if err := json.Unmarshal(body, &cards); err != nil {
-		return nil, err
-	}
-	rows := make([][]string, 0, len(cards))
-	for _, c := range cards {
-		rows = append(rows, []string{fmt.Sprintf("%d", c.Number), c.Title, c.Status, c.Board.Name, c.LastActiveAt})
-	}
-	return rows, nil
-}
-
-func commentListRows(body []byte) ([][]string, error) {
-	var comments []comment
-	if err := json.Unmarshal(body, &comments); err != nil {
-		return nil, err
-	}
-	rows := make([][]string, 0, len(comments))
-	for _, c := range comments {
-		rows = append(rows, []string{c.ID, c.Creator.Name, c.Body.Plain, c.CreatedAt})
-	}
-	return rows, nil
-}
-
-func tagListRows(body []byte) ([][]string, error) {
-	var tags []tag
-	if err := json.Unmarshal(body, &tags); err != nil {
-		return nil, err
-	}
-	rows := make([][]string, 0, len(tags))
-	for _, t := range tags {
-		rows = append(rows, []string{t.ID, t.Title})
-	}
-	return rows, nil
-}
-
-func columnListRows(body []byte) ([][]string, error) {
-	var cols []column
-	if err := json.Unmarshal(body, &cols); err != nil {
-		return nil, err
-	}
-	rows := make([][]string, 0, len(cols))
-	for _, c := range cols {
-		rows = append(rows, []string{c.ID, c.Name, c.Color})
+func cardListStyle(c fizzy.Card) []string {
+	title := ""
+	if c.Golden {
+		title = sgrBold + ";" + sgrYellow
 	}
-	return rows, nil
+	return []string{sgrBold, title, cardStatusColor(c.Status)}
 }
 
-func userListRows(body []byte) ([][]string, error) {
-	var users []user
-	if err := json.Unmarshal(body, &users); err != nil {
-		return nil, err
-	}
-	rows := make([][]string, 0, len(users))
-	for _, u := range users {
-		rows = append(rows, []string{u.ID, u.Name, u.Role, u.Email})
-	}
-	return rows, nil
+func columnListStyle(c fizzy.Column) []string {
+	return []string{"", "", columnColor(c.Color)}
 }
 
-func notificationListRows(body []byte) ([][]string, error) {
-	var notes []notification
-	if err := json.Unmarshal(body, &notes); err != nil {
-		return nil, err
-	}
-	rows := make([][]string, 0, len(notes))
-	for _, n := range notes {
-		read := "no"
-		if n.Read {
-			read = "yes"
-		}
-		rows = append(rows, []string{n.ID, read, n.Title, n.Card.Title, n.CreatedAt})
+// notificationListStyle highlights unread notifications and dims read ones.
+func notificationListStyle(n fizzy.Notification) []string {
+	attrs := sgrBold
+	if n.Read {
+		attrs = sgrDim
 	}
-	return rows, nil
+	return []string{attrs, attrs, attrs, attrs, attrs}
 }
 
-func formatBoard(body []byte) (string, error) {
-	var b board
-	if err := json.Unmarshal(body, &b); err != nil {
-		return "", err
-	}
+func formatBoard(b *fizzy.Board) string {
 	return fmt.Sprintf(
 		"ID: %s\nName: %s\nAll access: %t\nCreated: %s\nCreator: %s\nURL: %s",
 		b.ID, b.Name, b.AllAccess, b.CreatedAt, b.Creator.Name, b.URL,
-	), nil
+	)
 }
 
-func formatCard(body []byte) (string, error) {
-	var c card
-	if err := json.Unmarshal(body, &c); err != nil {
-		return "", err
-	}
+func formatCard(c *fizzy.Card) string {
 	builder := &strings.Builder{}
 	fmt.Fprintf(builder, "ID: %s\n", c.ID)
 	fmt.Fprintf(builder, "Number: %d\n", c.Number)
@@ -241,38 +115,26 @@ func formatCard(body []byte) (string, error) {
 			fmt.Fprintf(builder, "- [%s] %s\n", status, s.Content)
 		}
 	}
-	return strings.TrimSpace(builder.String()), nil
+	return strings.TrimSpace(builder.String())
 }
 
-func formatComment(body []byte) (string, error) {
-	var c comment
-	if err := json.Unmarshal(body, &c); err != nil {
-		return "", err
-	}
+func formatComment(c *fizzy.Comment) string {
 	return fmt.Sprintf(
 		"ID: %s\nCreator: %s\nCreated: %s\nBody: %s",
 		c.ID, c.Creator.Name, c.CreatedAt, c.Body.Plain,
-	), nil
+	)
 }
 
-func formatColumn(body []byte) (string, error) {
-	var c column
-	if err := json.Unmarshal(body, &c); err != nil {
-		return "", err
-	}
+func formatColumn(c *fizzy.Column) string {
 	return fmt.Sprintf(
 		"ID: %s\nName: %s\nColor: %s\nCreated: %s",
 		c.ID, c.Name, c.Color, c.CreatedAt,
-	), nil
+	)
 }
 
-func formatUser(body []byte) (string, error) {
-	var u user
-	if err := json.Unmarshal(body, &u); err != nil {
-		return "", err
-	}
+func formatUser(u *fizzy.User) string {
 	return fmt.Sprintf(
 		"ID: %s\nName: %s\nRole: %s\nEmail: %s",
 		u.ID, u.Name, u.Role, u.Email,
-	), nil
+	)
 }
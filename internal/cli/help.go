@@ -13,21 +13,30 @@ EXAMPLES:
   fizzy-cli account set 897362094
   fizzy-cli board list
   fizzy-cli card list --board-id 03f5v9zkft4hj9qq0lsn9ohcm
+  fizzy-cli card list --board-id Roadmap --assignee-id jane@example.com
   fizzy-cli card create --board-id 03f5v9zkft4hj9qq0lsn9ohcm --title "Add dark mode" --description "Switch theme"
   fizzy-cli comment list 4
+  fizzy-cli step add 4 --content "Write tests"
   fizzy-cli notification list --unread
 
 COMMANDS:
   auth              Manage authentication
   account           Account selection and identity info
   config            Manage persisted defaults
+  profile           Manage named config profiles
   board             Manage boards
   card              Manage cards
   comment           Manage card comments
+  step              Manage card steps (checklists)
   tag               List tags
   column            Manage columns
   user              Manage users
   notification      Manage notifications
+  batch             Run operations from a JSONL or YAML script
+  history           List recent changes that can be undone
+  undo              Undo the last change, or a change from history
+  completion        Generate shell completion scripts
+  dev               Developer tools (offline fake API)
   help              Show help for a command
 
 GLOBAL FLAGS:
@@ -35,11 +44,67 @@ GLOBAL FLAGS:
   --token string      Personal access token (env: FIZZY_TOKEN)
   --account string    Account slug (env: FIZZY_ACCOUNT)
   --config string     Config file path (env: FIZZY_CONFIG)
-  --json              JSON output
-  --plain             Plain, line-oriented output
-  --no-color          Disable color (respects NO_COLOR by default)
+  --profile string    Config profile (env: FIZZY_PROFILE, default: current profile)
+  -o, --output fmt    table, plain, json, ndjson, yaml, csv, tsv or markdown (default: table)
+  --json              Same as --output json
+  --plain             Same as --output plain
+  --no-color          Disable color (also off with NO_COLOR or when stdout is not a terminal)
+  --format tmpl       Go template applied to each item, e.g. '{{.Number}} {{.Title}}'
+  --fields list       Comma-separated JSON fields to show, e.g. number,title,board.name
+  --jq path           Extract values from JSON output, e.g. '.[].title'
+  --max-attempts int  Attempts per request, including retries (env: FIZZY_MAX_ATTEMPTS, default: 3)
+  --retry-timeout d   Total time budget for retries, e.g. 30s (env: FIZZY_RETRY_TIMEOUT, default: 1m)
+  --record dir        Save every API request and response to dir, credentials scrubbed (env: FIZZY_RECORD)
+  --replay dir        Answer API requests from a --record dir instead of the network (env: FIZZY_REPLAY)
+  --verbose           Trace each HTTP request and response to stderr (env: FIZZY_DEBUG=1)
+  --dry-run           Send only reads (they still reach the server); stop at the first write and exit 10
+  --print-curl        Print each request to stderr as an equivalent curl command
+  --show-secrets      Do not mask tokens and cookies in --verbose and --print-curl output
+  --yes               Skip confirmation of deletes and deactivations (env: FIZZY_ASSUME_YES=1)
   -h, --help          Show help
   --version           Print version
+
+IDS AND NAMES:
+  Every flag or argument that takes a board, column, user or tag ID also
+  accepts a name (boards, columns), a name or email (users) or a title
+  (tags). Ambiguous names fail with a list of matching IDs.
+
+OUTPUT:
+  Every list and get command supports every --output format. csv and tsv
+  quote fields as needed and always print a header row; ndjson prints one
+  compact JSON object per line.
+  --format runs a Go text/template against each item (the Go SDK types, so
+  fields are capitalized: .Number, .Title, .Board.Name). Helpers: join,
+  upper, lower, trim, truncate N, pad N, default VALUE, json, date LAYOUT.
+  --fields picks columns by their JSON names; with json, ndjson or yaml it
+  trims each object to those fields. --format needs table or plain output.
+  --jq takes jq path expressions (.a.b, .[0], .[], and pipes of them such
+  as '.[] | .title') and prints strings without quotes.
+
+LIST FLAGS:
+  --all               Follow pagination and fetch every page
+  --limit N           Stop once N items have been fetched (follows pages as needed)
+  --page-size N       Items to request per page
+  Without --all or --limit, list commands return the first page only.
+  With json, ndjson, csv, tsv or markdown, items are streamed as they are fetched;
+  tables and plain output are printed 100 rows at a time, sized by the first rows.
+  If a later page fails, the items already fetched are printed (JSON arrays are
+  closed) before the error.
+
+CONFIRMATION:
+  board delete, card delete, column delete, comment delete and user
+  deactivate show what they will destroy and ask you to type the board
+  name, card number, column name, "delete" or the user's email. Without a
+  terminal they refuse to run unless --yes or FIZZY_ASSUME_YES=1 is given.
+
+EXIT CODES:
+  0  success            4  forbidden (403)        7  rate limited (429)
+  1  other error        5  not found (404)        8  server error (5xx)
+  2  usage error        6  validation (422)       9  network error
+  3  auth failed (401)                           10 dry run, change not sent
+  With --output json or ndjson, errors are printed to stderr as a JSON
+  object: {"error":"validation","message":"...","exit_code":6,"status":422,
+  "fields":[{"field":"title","message":"can't be blank"}]}.
 `
 
 func helpForAuth() string {
@@ -53,6 +118,13 @@ FLAGS:
   --token string   Personal access token (reads from stdin or prompt if omitted)
   --email string   Email address for magic-link login
   --code string    Magic-link code (required if not running in a TTY)
+
+NOTES:
+  Credentials go to the profile's credential store (see 'fizzy-cli help config').
+  auth status checks the credentials against the API and exits 3 when they
+  are rejected, e.g. once a magic-link session has expired.
+  When a session expires mid-command on a terminal, you are offered a new
+  magic-link login and the command continues; elsewhere it fails with exit 3.
 `
 }
 
@@ -69,27 +141,107 @@ NOTES:
 func helpForConfig() string {
 	return `USAGE:
   fizzy-cli config show
-  fizzy-cli config set [--base-url URL] [--account SLUG]
+  fizzy-cli config set [--base-url URL] [--account SLUG] [--max-attempts N] [--retry-timeout DURATION] [--cache-ttl DURATION]
+                       [--credential-store file|encrypted|helper:NAME]
+
+NOTES:
+  Failed requests are retried with exponential backoff when the server
+  returns 429 or 5xx, or the connection fails. Retry-After is honored on
+  429 and 503. Only idempotent requests (GET, PUT, DELETE and state-setting
+  actions such as close or watch) are retried.
+
+  Names used in place of IDs are looked up through the list endpoints and
+  cached for --cache-ttl (env: FIZZY_CACHE_TTL, default: 5m; 0 disables).
+
+  --credential-store picks where 'auth login' keeps tokens and moves the
+  existing ones there:
+    file         plaintext in the config file (default)
+    encrypted    credentials.enc next to the config file, AES-256-GCM with a
+                 scrypt-derived key (passphrase from FIZZY_PASSPHRASE or prompt)
+    helper:NAME  the external program fizzy-cli-credential-NAME, called with
+                 get, store or erase and key=value lines on stdin/stdout
+`
+}
+
+func helpForProfile() string {
+	return `USAGE:
+  fizzy-cli profile list
+  fizzy-cli profile add <name> [--base-url URL] [--account SLUG] [--token TOKEN] [--use]
+  fizzy-cli profile use <name>
+  fizzy-cli profile remove <name>
+  fizzy-cli profile rename <old-name> <new-name>
+
+NOTES:
+  A profile holds a base URL, account, credentials and request settings.
+  Select one per command with --profile or FIZZY_PROFILE; otherwise the
+  current profile (set with 'profile use') is used. 'auth login',
+  'account set' and 'config set' write to the selected profile.
+  Precedence: flags > env > profile > defaults.
 `
 }
 
 func helpForBoard() string {
 	return `USAGE:
-  fizzy-cli board list
+  fizzy-cli board list [--all] [--limit N] [--page-size N]
   fizzy-cli board get <board-id>
   fizzy-cli board create --name <name> [--all-access] [--auto-postpone-days N] [--public-description TEXT]
   fizzy-cli board update <board-id> [--name <name>] [--all-access] [--no-all-access] [--auto-postpone-days N] [--public-description TEXT] [--user-id ID ...]
-  fizzy-cli board delete <board-id>
+  fizzy-cli board delete <board-id> [--yes]
+  fizzy-cli board view <board-id> [--refresh DURATION]
+  fizzy-cli board render <board-id> [--markdown] [--width N] [--all-lanes]
+  fizzy-cli board export <board-id> [-o FILE] [--concurrency N]
+  fizzy-cli board import <file> [--into <board-id> | --new-name NAME] [--dry-run] [--mapping PATH]
+
+VIEW:
+  Opens the board full-screen: Not Now, Maybe?, each column, then Done.
+  --refresh DURATION      reload interval (default 30s, 0 disables)
+
+  ←/→ or h/l              select lane
+  ↑/↓ or k/j              select card
+  H/L or </>              move the card to the previous/next lane
+  enter                   show the card with its steps and comments
+  x / n / o               close, not now, reopen
+  r                       refresh now
+  q                       quit (esc leaves the detail pane)
+
+RENDER:
+  Prints Maybe? and each column side by side with wrapped card titles,
+  fitted to the terminal width ($COLUMNS, or 80, when not a terminal).
+  --markdown              a heading per column with its cards as a list
+                          (also with --output markdown)
+  --width N               line width instead of the terminal's
+  --all-lanes             include the Not Now and Done lanes
+
+EXPORT:
+  Saves the board, its columns and every card (open, Not Now and closed)
+  with steps, tags, assignees and comments, as the API returns them.
+  -o, --output FILE       FILE.zip also holds the card images; any other
+                          name gets the JSON document alone (default: stdout)
+  --concurrency N         cards fetched in parallel (default 4, max 16)
+
+  The format is described under "Board Export Format" in the README.
+
+IMPORT:
+  Recreates an export: a new board (named as exported, or --new-name) or,
+  with --into, an existing one where columns of the same name are reused.
+  Cards get their column, tags, assignees (matched by email), steps,
+  comments, image (from a .zip) and closed or Not Now state back. Card
+  numbers, authors and dates are new.
+  --dry-run               list what would be created without changing anything
+  --mapping PATH          old to new IDs (default: FILE.mapping.json)
+
+  The mapping is saved after every create, so running the same command
+  again resumes after a failure and skips what is already imported.
 `
 }
 
 func helpForCard() string {
 	return `USAGE:
-  fizzy-cli card list [filters]
+  fizzy-cli card list [filters] [--all] [--limit N] [--page-size N]
   fizzy-cli card get <card-number>
-  fizzy-cli card create --board-id <board-id> --title <title> [--description TEXT] [--status drafted|published] [--tag-id ID ...] [--image PATH]
-  fizzy-cli card update <card-number> [--title TEXT] [--description TEXT] [--status drafted|published] [--tag-id ID ...] [--image PATH]
-  fizzy-cli card delete <card-number>
+  fizzy-cli card create --board-id <board-id> --title <title> [--description TEXT | --description-file PATH] [--edit] [--status drafted|published] [--tag-id ID ...] [--image PATH] [--step TEXT ...] [--steps-file PATH]
+  fizzy-cli card update <card-number> [--title TEXT] [--description TEXT | --description-file PATH | --clear-description] [--edit] [--status drafted|published] [--tag-id ID ...] [--image PATH]
+  fizzy-cli card delete <card-number> [--yes]
   fizzy-cli card close <card-number>
   fizzy-cli card reopen <card-number>
   fizzy-cli card not-now <card-number>
@@ -99,6 +251,8 @@ func helpForCard() string {
   fizzy-cli card assign <card-number> --assignee-id <user-id>
   fizzy-cli card watch <card-number>
   fizzy-cli card unwatch <card-number>
+  fizzy-cli card bulk close|reopen|not-now|watch|delete [filters] [--limit N] [--concurrency N] [--yes]
+  fizzy-cli card bulk triage <column> | tag <tag-title> | assign <user> [filters] [...]
 
 FILTERS:
   --board-id ID           repeatable
@@ -113,54 +267,220 @@ FILTERS:
   --creation VALUE        today|yesterday|thisweek|lastweek|thismonth|lastmonth|thisyear|lastyear
   --closure VALUE         today|yesterday|thisweek|lastweek|thismonth|lastmonth|thisyear|lastyear
   --term VALUE            repeatable search terms
-  --all                   follow pagination
+
+EDITING:
+  --edit opens $VISUAL or $EDITOR (default vi) with the title on the first
+  line and the description below it. card update pre-fills the current
+  values and sends only the fields that changed; card create takes --title
+  from the editor. Saving an empty file aborts; deleting only the
+  description clears it. --description-file - reads the description from
+  stdin, and --clear-description removes it.
+
+BULK:
+  card bulk applies an action to every card matching the filters (all
+  pages, up to --limit), or without filters to the card numbers piped on
+  stdin, one per line; 'card list -o plain' output works. It previews the
+  cards and asks you to type their count, like the confirmation of delete
+  commands. Requests run --concurrency at a time (default 4, at most 16).
+  Cards that already have the tag or assignee are skipped. Each card's
+  result is printed, and the exit code is 1 if any failed. Close, reopen,
+  tag, assign and delete are recorded for 'fizzy-cli undo'.
 `
 }
 
 func helpForComment() string {
 	return `USAGE:
-  fizzy-cli comment list <card-number>
+  fizzy-cli comment list <card-number> [--all] [--limit N] [--page-size N]
   fizzy-cli comment get <card-number> <comment-id>
-  fizzy-cli comment create <card-number> --body <text>
-  fizzy-cli comment update <card-number> <comment-id> --body <text>
-  fizzy-cli comment delete <card-number> <comment-id>
+  fizzy-cli comment create <card-number> (--body <text> | --body-file PATH | --edit)
+  fizzy-cli comment update <card-number> <comment-id> (--body <text> | --body-file PATH | --edit)
+  fizzy-cli comment delete <card-number> <comment-id> [--yes]
+
+NOTES:
+  --edit opens $VISUAL or $EDITOR on the comment, pre-filled with the
+  current body on update. Saving an empty file aborts. --body-file - reads
+  the body from stdin.
+`
+}
+
+func helpForStep() string {
+	return `USAGE:
+  fizzy-cli step list <card-number>
+  fizzy-cli step add <card-number> --content <text> [--completed]
+  fizzy-cli step add <card-number> --file <checklist.md|->
+  fizzy-cli step update <card-number> <step-id> --content <text>
+  fizzy-cli step complete <card-number> <step-id>
+  fizzy-cli step uncomplete <card-number> <step-id>
+  fizzy-cli step delete <card-number> <step-id>
+
+NOTES:
+  --file imports a Markdown checklist, one step per list item, in order:
+    - [ ] open step
+    - [x] completed step
+  Plain list items are added as open steps; other lines are ignored. Use -
+  to read from stdin. card create --steps-file accepts the same format.
+  The API has no step positions, so steps cannot be reordered; new steps
+  are appended.
 `
 }
 
 func helpForTag() string {
 	return `USAGE:
-  fizzy-cli tag list
+  fizzy-cli tag list [--all] [--limit N] [--page-size N]
 `
 }
 
 func helpForColumn() string {
 	return `USAGE:
-  fizzy-cli column list --board-id <board-id>
+  fizzy-cli column list --board-id <board-id> [--all] [--limit N] [--page-size N]
   fizzy-cli column get --board-id <board-id> <column-id>
   fizzy-cli column create --board-id <board-id> --name <name> [--color <color>]
   fizzy-cli column update --board-id <board-id> <column-id> [--name <name>] [--color <color>]
-  fizzy-cli column delete --board-id <board-id> <column-id>
+  fizzy-cli column delete --board-id <board-id> <column-id> [--yes]
 `
 }
 
 func helpForUser() string {
 	return `USAGE:
-  fizzy-cli user list
+  fizzy-cli user list [--all] [--limit N] [--page-size N]
   fizzy-cli user get <user-id>
   fizzy-cli user update <user-id> [--name <name>] [--avatar PATH]
-  fizzy-cli user deactivate <user-id>
+  fizzy-cli user deactivate <user-id> [--yes]
 `
 }
 
 func helpForNotification() string {
 	return `USAGE:
-  fizzy-cli notification list [--unread]
+  fizzy-cli notification list [--unread] [--all] [--limit N] [--page-size N]
   fizzy-cli notification read <notification-id>
   fizzy-cli notification unread <notification-id>
   fizzy-cli notification read-all
 `
 }
 
+func helpForDev() string {
+	return `USAGE:
+  fizzy-cli dev fake-server [--port N] [--host ADDR] [--token TOKEN] [--empty]
+
+FAKE SERVER:
+  Serves an in-memory fake of the Fizzy API, seeded with a demo account,
+  until interrupted. Nothing is saved. Point the CLI at it with:
+    FIZZY_BASE_URL=http://127.0.0.1:8484 FIZZY_TOKEN=x FIZZY_ACCOUNT=897362094
+  --port N                port to listen on (default 8484, 0 picks a free one)
+  --host ADDR             address to listen on (default 127.0.0.1)
+  --token TOKEN           accept only this token (default: any token)
+  --empty                 start with no boards or cards
+  Magic-link login accepts any email with the code 123456.
+`
+}
+
+func helpForBatch() string {
+	return `USAGE:
+  fizzy-cli batch run <file> [--continue-on-error] [--dry-run] [--checkpoint PATH] [--report PATH]
+
+FLAGS:
+  --continue-on-error     run the remaining operations after one fails
+  --dry-run               validate the script and send only reads
+  --checkpoint PATH       progress file (default <file>.checkpoint)
+  --report PATH           also write the results as JSON to PATH
+
+SCRIPTS:
+  A JSONL file has one operation per line:
+    {"op": "card.create", "ref": "c1", "board": "Roadmap", "title": "Import"}
+    {"op": "comment.create", "card": "${c1.number}", "body": "Migrated"}
+  A .yaml or .yml file (or a script starting with "- ") is a list of the
+  same mappings. - reads the script from stdin.
+
+  ref names an operation so that later ones can use its outputs as
+  ${ref.field} inside any string value. Boards, columns, tags and users
+  can be given by name, as on the command line.
+
+OPERATIONS:
+  board.create    name [all_access, public_description]   -> id, location
+  column.create   board, name [color]                     -> id, location
+  card.create     board, title [description, status, tags, steps]
+                                                          -> number, location
+  card.update     card [title, description, status, tags]
+                  (an empty description clears it)
+  card.close | card.reopen | card.not-now | card.untriage | card.watch
+                  card
+  card.triage     card, column
+  card.tag        card, tag
+  card.assign     card, user
+  comment.create  card, body                              -> id, location
+  step.create     card, content [completed]               -> id, location
+
+NOTES:
+  The whole script is checked before anything runs. Operations run in
+  order and the run stops at the first failure, unless
+  --continue-on-error is given. Each success is recorded in the checkpoint
+  file; running the same command again skips those operations and reuses
+  their outputs, so fix the failing line and rerun. A card.create whose
+  card was created but whose steps failed resumes at the first missing
+  step instead of creating the card again. The checkpoint is
+  removed once every operation has succeeded. Operations that already ran
+  must not be edited. The exit code is that of the first failure.
+`
+}
+
+func helpForHistory() string {
+	return `USAGE:
+  fizzy-cli history [list] [--limit N]
+  fizzy-cli history show <id>
+
+FLAGS:
+  --limit N               entries to show, newest first (default 20, 0 for all)
+
+NOTES:
+  card update, close, reopen, tag, assign and delete, and comment update
+  and delete record the card or comment as it was before into a journal
+  next to the config file. Only changes made on the current account are
+  listed. show prints the saved snapshot; see 'fizzy-cli help undo'.
+`
+}
+
+func helpForUndo() string {
+	return `USAGE:
+  fizzy-cli undo [<id>]
+
+NOTES:
+  Undoes the latest change that hasn't been undone yet, or the history
+  entry <id>, as far as the API allows (card bulk records one entry per
+  card):
+    card update     restores title, description, status and tags
+    card close      reopens the card; card reopen closes it again
+    card tag        toggles the tag back; card assign toggles the assignee
+  Close, reopen, tag and assign are checked against the card first: if the
+  command changed nothing, or the card has been changed back since, undo
+  reports that there is nothing to undo and leaves the card alone.
+    card delete     re-creates the card with its title, description, tags,
+                    assignees, steps, column, closed state and comments
+    comment update  restores the text
+    comment delete  re-creates the comment, authored by you
+  What can't be restored (a deleted card's number, comment authors, dates,
+  images, rich text formatting) is reported. Each entry can be undone once.
+`
+}
+
+func helpForCompletion() string {
+	return `USAGE:
+  fizzy-cli completion bash|zsh|fish
+
+INSTALL:
+  bash   source <(fizzy-cli completion bash)
+         or: fizzy-cli completion bash > /etc/bash_completion.d/fizzy-cli
+  zsh    fizzy-cli completion zsh > "${fpath[1]}/_fizzy-cli"
+  fish   fizzy-cli completion fish > ~/.config/fish/completions/fizzy-cli.fish
+
+NOTES:
+  Commands, subcommands and flags complete offline. Board, column, card,
+  user, tag, comment, step and notification IDs are suggested from the API
+  with their names and cached for up to a minute (less if --cache-ttl is
+  lower). Profiles that use the encrypted credential store only get live
+  suggestions when FIZZY_PASSPHRASE is set.
+`
+}
+
 func helpForCommand(cmd string) string {
 	switch cmd {
 	case "auth":
@@ -169,12 +489,16 @@ func helpForCommand(cmd string) string {
 		return helpForAccount()
 	case "config":
 		return helpForConfig()
+	case "profile":
+		return helpForProfile()
 	case "board":
 		return helpForBoard()
 	case "card":
 		return helpForCard()
 	case "comment":
 		return helpForComment()
+	case "step":
+		return helpForStep()
 	case "tag":
 		return helpForTag()
 	case "column":
@@ -183,6 +507,16 @@ func helpForCommand(cmd string) string {
 		return helpForUser()
 	case "notification":
 		return helpForNotification()
+	case "completion":
+		return helpForCompletion()
+	case "dev":
+		return helpForDev()
+	case "batch":
+		return helpForBatch()
+	case "history":
+		return helpForHistory()
+	case "undo":
+		return helpForUndo()
 	default:
 		return fmt.Sprintf("Unknown command %q.\n\n%s", cmd, rootHelp)
 	}
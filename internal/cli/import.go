@@ -0,0 +1,569 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fizzy-cli/internal/archive"
+	"fizzy-cli/pkg/fizzy"
+)
+
+// importMapping records the IDs of everything a board import created, by
+// the ID it had in the export, so that running the import again skips what
+// is already there.
+type importMapping struct {
+	Source  importBoardRef        `json:"source"`
+	Target  importBoardRef        `json:"target"`
+	Columns map[string]string     `json:"columns"`
+	Cards   map[int]*importedCard `json:"cards"`
+}
+
+type importBoardRef struct {
+	BaseURL string `json:"base_url"`
+	Account string `json:"account"`
+	BoardID string `json:"board_id,omitempty"`
+}
+
+type importedCard struct {
+	Number   int               `json:"number"`
+	Steps    map[string]string `json:"steps"`
+	Comments map[string]string `json:"comments"`
+}
+
+type importResult struct {
+	Kind   string `json:"kind"`
+	Old    string `json:"old"`
+	New    string `json:"new,omitempty"`
+	Result string `json:"result"`
+	Note   string `json:"note,omitempty"`
+}
+
+var importView = view[importResult]{headers: []string{"KIND", "OLD", "NEW", "RESULT", "NOTE"}, row: func(r importResult) []string {
+	return []string{r.Kind, r.Old, r.New, r.Result, r.Note}
+}}
+
+func runBoardImport(ctx Context, args []string) int {
+	if len(args) < 2 || strings.HasPrefix(args[1], "-") {
+		return handleErr(helpForBoard(), UsageError{Msg: "export file is required"})
+	}
+	file := args[1]
+	fs := flag.NewFlagSet("board import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	into := fs.String("into", "", "Import into an existing board")
+	newName := fs.String("new-name", "", "Name of the new board")
+	dryRun := fs.Bool("dry-run", false, "Print the plan without changing anything")
+	mappingFile := fs.String("mapping", "", "Mapping file")
+	if err := fs.Parse(args[2:]); err != nil {
+		return usageError(helpForBoard(), err)
+	}
+	if *into != "" && *newName != "" {
+		return handleErr(helpForBoard(), UsageError{Msg: "--into and --new-name cannot be used together"})
+	}
+	if *mappingFile == "" {
+		*mappingFile = file + ".mapping.json"
+	}
+	if *dryRun && !ctx.DryRun {
+		ctx.DryRun = true
+		ctx.Client = fizzy.NewClient(newTransport(ctx, ctx.Token, ctx.SessionToken), ctx.Account)
+	}
+
+	a, err := archive.Read(file)
+	if err != nil {
+		return handleErr(helpForBoard(), err)
+	}
+	var source fizzy.Board
+	if err := json.Unmarshal(a.Board, &source); err != nil {
+		return handleErr(helpForBoard(), fmt.Errorf("%s: board: %w", file, err))
+	}
+	m, err := loadMapping(*mappingFile)
+	if err != nil {
+		return handleErr(helpForBoard(), err)
+	}
+	switch {
+	case m.Source.BoardID == "":
+		m.Source = importBoardRef{BaseURL: a.Source.BaseURL, Account: a.Source.Account, BoardID: source.ID}
+		m.Target = importBoardRef{BaseURL: ctx.BaseURL, Account: ctx.Account}
+	case m.Source.BoardID != source.ID:
+		return handleErr(helpForBoard(), fmt.Errorf("%s belongs to an export of another board; pass --mapping to start a new one", *mappingFile))
+	case m.Target.BaseURL != ctx.BaseURL || m.Target.Account != ctx.Account:
+		return handleErr(helpForBoard(), fmt.Errorf("%s records an import into account %s at %s; pass --mapping to import elsewhere", *mappingFile, m.Target.Account, m.Target.BaseURL))
+	}
+
+	im := &importer{ctx: ctx, archive: a, mapping: m, file: *mappingFile, users: map[string]string{}}
+	defer im.cleanup()
+	err = im.board(source, *into, *newName)
+	if err == nil {
+		err = im.columns()
+	}
+	if err == nil {
+		for _, card := range a.Cards {
+			if err = im.card(card); err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil && len(im.results) == 0 {
+		return handleErr(helpForBoard(), err)
+	}
+	raws := make([]json.RawMessage, 0, len(im.results))
+	counts := map[string]int{}
+	for _, r := range im.results {
+		counts[r.Result]++
+		raw, err := json.Marshal(r)
+		if err != nil {
+			return handleErr(helpForBoard(), err)
+		}
+		raws = append(raws, raw)
+	}
+	if code := outputList(ctx, helpForBoard(), im.results, raws, importView); code != 0 {
+		return code
+	}
+	if !ctx.Output.Structured() {
+		var parts []string
+		for _, result := range []string{"created", "updated", "exists", "planned", "skipped", "failed"} {
+			if counts[result] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", counts[result], result))
+			}
+		}
+		summary := fmt.Sprintf("Imported %q: %s.", source.Name, strings.Join(parts, ", "))
+		if ctx.DryRun {
+			summary = fmt.Sprintf("Plan for %q: %s. Nothing was changed.", source.Name, strings.Join(parts, ", "))
+		}
+		fmt.Fprintln(os.Stderr, summary)
+		switch {
+		case ctx.DryRun:
+		case err != nil && im.saved:
+			fmt.Fprintf(os.Stderr, "Progress is saved in %s; run the same command again to resume.\n", im.file)
+		case im.saved:
+			fmt.Fprintf(os.Stderr, "Old to new IDs are saved in %s.\n", im.file)
+		}
+	}
+	if err != nil && err != im.failed {
+		return handleErr(helpForBoard(), err)
+	}
+	if err != nil {
+		return exitCode(err)
+	}
+	return 0
+}
+
+func loadMapping(file string) (*importMapping, error) {
+	m := &importMapping{}
+	data, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, fmt.Errorf("mapping %s: %w", file, err)
+		}
+	}
+	if m.Columns == nil {
+		m.Columns = map[string]string{}
+	}
+	if m.Cards == nil {
+		m.Cards = map[int]*importedCard{}
+	}
+	return m, nil
+}
+
+// importer recreates an export on a board, one request at a time, saving
+// the mapping after each create. In a dry run it only reads and records
+// what it would do.
+type importer struct {
+	ctx     Context
+	archive *archive.Archive
+	mapping *importMapping
+	file    string
+	results []importResult
+	saved   bool
+	// failed is the error of the write that failed, already in results.
+	failed error
+
+	users    map[string]string
+	notNow   map[int]bool
+	imageDir string
+}
+
+func (im *importer) add(kind, old, newID, result, note string) {
+	im.results = append(im.results, importResult{Kind: kind, Old: old, New: newID, Result: result, Note: note})
+}
+
+func (im *importer) save() error {
+	if err := writeJSONFile(im.file, im.mapping); err != nil {
+		return fmt.Errorf("could not write mapping %s: %w", im.file, err)
+	}
+	im.saved = true
+	return nil
+}
+
+// change runs a write and records its result, or only records it in a dry
+// run. do returns the new ID, if any.
+func (im *importer) change(kind, old, result, note string, do func() (string, error)) error {
+	if im.ctx.DryRun {
+		im.add(kind, old, "", "planned", note)
+		return nil
+	}
+	id, err := do()
+	if err != nil {
+		im.add(kind, old, "", "failed", err.Error())
+		im.failed = err
+		return err
+	}
+	im.add(kind, old, id, result, note)
+	return nil
+}
+
+func (im *importer) cleanup() {
+	if im.imageDir != "" {
+		os.RemoveAll(im.imageDir)
+	}
+}
+
+func (im *importer) board(source fizzy.Board, into, newName string) error {
+	m := im.mapping
+	switch {
+	case m.Target.BoardID != "":
+		if into != "" {
+			id, err := resolveBoard(im.ctx, into)
+			if err != nil {
+				return err
+			}
+			if id != m.Target.BoardID {
+				return fmt.Errorf("%s records an import into board %s; pass --mapping to import into another board", im.file, m.Target.BoardID)
+			}
+		}
+		im.add("board", source.ID, m.Target.BoardID, "exists", "")
+		return nil
+	case into != "":
+		id, err := resolveBoard(im.ctx, into)
+		if err != nil {
+			return err
+		}
+		m.Target.BoardID = id
+		im.add("board", source.ID, id, "exists", "import into "+into)
+		if im.ctx.DryRun {
+			return nil
+		}
+		return im.save()
+	}
+	name := firstNonEmpty(newName, source.Name)
+	return im.change("board", source.ID, "created", name, func() (string, error) {
+		resp, err := im.ctx.Client.Boards.Create(requestContext(), &fizzy.BoardCreateRequest{Name: name, AllAccess: source.AllAccess})
+		if err != nil {
+			return "", err
+		}
+		id := locationOutputs(resp, "id")["id"]
+		if id == "" {
+			return "", errors.New("the API did not return the new board's location")
+		}
+		m.Target.BoardID = id
+		return id, im.save()
+	})
+}
+
+// columns maps each exported column to one of the same name on the board,
+// or creates it.
+func (im *importer) columns() error {
+	m := im.mapping
+	existing := map[string]string{}
+	if m.Target.BoardID != "" {
+		columns, err := im.ctx.Client.Columns.Iter(m.Target.BoardID, nil).All(requestContext())
+		if err != nil {
+			return err
+		}
+		for _, c := range columns {
+			existing[c.Name] = c.ID
+		}
+	}
+	for _, raw := range im.archive.Columns {
+		var column fizzy.Column
+		if err := json.Unmarshal(raw, &column); err != nil {
+			return fmt.Errorf("column: %w", err)
+		}
+		if id, ok := m.Columns[column.ID]; ok {
+			im.add("column", column.ID, id, "exists", column.Name)
+			continue
+		}
+		if id, ok := existing[column.Name]; ok {
+			m.Columns[column.ID] = id
+			im.add("column", column.ID, id, "exists", column.Name+" (same name)")
+			if !im.ctx.DryRun {
+				if err := im.save(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		err := im.change("column", column.ID, "created", column.Name, func() (string, error) {
+			resp, err := im.ctx.Client.Columns.Create(requestContext(), m.Target.BoardID, &fizzy.ColumnRequest{Name: column.Name, Color: column.Color})
+			if err != nil {
+				return "", err
+			}
+			id := locationOutputs(resp, "id")["id"]
+			if id == "" {
+				return "", errors.New("the API did not return the new column's location")
+			}
+			m.Columns[column.ID] = id
+			return id, im.save()
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// card creates a card unless the mapping has it, then brings its tags,
+// assignees, steps, comments and state in line with the export.
+func (im *importer) card(c archive.Card) error {
+	reqCtx := requestContext()
+	var old fizzy.Card
+	if err := json.Unmarshal(c.Card, &old); err != nil {
+		return fmt.Errorf("card: %w", err)
+	}
+	label := "#" + strconv.Itoa(old.Number)
+	ic := im.mapping.Cards[old.Number]
+	current := &fizzy.Card{}
+	if ic != nil {
+		card, _, err := im.ctx.Client.Cards.Get(reqCtx, ic.Number)
+		if err != nil {
+			return fmt.Errorf("card %s, imported as #%d: %w", label, ic.Number, err)
+		}
+		current = card
+		im.add("card", label, "#"+strconv.Itoa(ic.Number), "exists", old.Title)
+	} else {
+		imagePath, err := im.image(old.Number, c.Image)
+		if err != nil {
+			return err
+		}
+		err = im.change("card", label, "created", old.Title, func() (string, error) {
+			resp, err := im.ctx.Client.Cards.Create(reqCtx, im.mapping.Target.BoardID, &fizzy.CardRequest{Title: old.Title, Description: optionalText(old.Description), Status: old.Status, ImagePath: imagePath})
+			if err != nil {
+				return "", err
+			}
+			number, ok := cardNumberFromLocation(resp.Headers.Get("Location"))
+			if !ok {
+				return "", errors.New("the API did not return the new card's location")
+			}
+			ic = &importedCard{Number: number, Steps: map[string]string{}, Comments: map[string]string{}}
+			im.mapping.Cards[old.Number] = ic
+			return "#" + strconv.Itoa(number), im.save()
+		})
+		if err != nil {
+			return err
+		}
+		switch {
+		case c.Image == nil:
+		case imagePath == "":
+			im.add("image", firstNonEmpty(c.Image.Path, c.Image.URL), "", "skipped", "not in the archive")
+		case im.ctx.DryRun:
+			im.add("image", c.Image.Path, "", "planned", "")
+		default:
+			im.add("image", c.Image.Path, "#"+strconv.Itoa(ic.Number), "created", "")
+		}
+	}
+	if ic == nil {
+		ic = &importedCard{Steps: map[string]string{}, Comments: map[string]string{}}
+	}
+	number := ic.Number
+
+	for _, title := range old.Tags {
+		if contains(current.Tags, title) {
+			continue
+		}
+		if err := im.change("tag", label, "updated", title, func() (string, error) {
+			_, err := im.ctx.Client.Cards.ToggleTag(reqCtx, number, title)
+			return "#" + strconv.Itoa(number), err
+		}); err != nil {
+			return err
+		}
+	}
+	for _, user := range old.Assignees {
+		who := firstNonEmpty(user.Email, user.Name)
+		userID := im.user(who)
+		if userID == "" {
+			im.add("assignee", label, "", "skipped", "no user "+who+" in this account")
+			continue
+		}
+		if assigned(current, userID) {
+			continue
+		}
+		if err := im.change("assignee", label, "updated", who, func() (string, error) {
+			_, err := im.ctx.Client.Cards.ToggleAssignment(reqCtx, number, userID)
+			return "#" + strconv.Itoa(number), err
+		}); err != nil {
+			return err
+		}
+	}
+	for _, step := range old.Steps {
+		if id, ok := ic.Steps[step.ID]; ok {
+			im.add("step", step.ID, id, "exists", step.Content)
+			continue
+		}
+		if err := im.change("step", step.ID, "created", step.Content, func() (string, error) {
+			resp, err := im.ctx.Client.Steps.Create(reqCtx, number, &fizzy.StepRequest{Content: step.Content, Completed: completedState(step.Completed)})
+			if err != nil {
+				return "", err
+			}
+			id := locationOutputs(resp, "id")["id"]
+			ic.Steps[step.ID] = id
+			return id, im.save()
+		}); err != nil {
+			return err
+		}
+	}
+	comments := make([]fizzy.Comment, 0, len(c.Comments))
+	for _, raw := range c.Comments {
+		var comment fizzy.Comment
+		if err := json.Unmarshal(raw, &comment); err != nil {
+			return fmt.Errorf("card %s: comment: %w", label, err)
+		}
+		comments = append(comments, comment)
+	}
+	// Oldest first, so that they read in the original order.
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].CreatedAt < comments[j].CreatedAt })
+	for _, comment := range comments {
+		note := "by " + firstNonEmpty(comment.Creator.Name, "unknown")
+		if id, ok := ic.Comments[comment.ID]; ok {
+			im.add("comment", comment.ID, id, "exists", note)
+			continue
+		}
+		if err := im.change("comment", comment.ID, "created", note, func() (string, error) {
+			resp, err := im.ctx.Client.Comments.Create(reqCtx, number, &fizzy.CommentRequest{Body: comment.Body.Plain})
+			if err != nil {
+				return "", err
+			}
+			id := locationOutputs(resp, "id")["id"]
+			ic.Comments[comment.ID] = id
+			return id, im.save()
+		}); err != nil {
+			return err
+		}
+	}
+	return im.state(c.State, label, old, current, number)
+}
+
+// state puts the card in its column and closes or postpones it as it was
+// in the export. It runs last since a closed card may not take comments.
+func (im *importer) state(state, label string, old fizzy.Card, current *fizzy.Card, number int) error {
+	reqCtx := requestContext()
+	done := func(err error) (string, error) { return "#" + strconv.Itoa(number), err }
+	if state == archive.StateNotNow {
+		notNow, err := im.isNotNow(number)
+		if err != nil || notNow {
+			return err
+		}
+		return im.change("state", label, "updated", "not now", func() (string, error) {
+			_, err := im.ctx.Client.Cards.NotNow(reqCtx, number)
+			return done(err)
+		})
+	}
+	if state == archive.StateOpen && current.Closed {
+		if err := im.change("state", label, "updated", "reopened", func() (string, error) {
+			_, err := im.ctx.Client.Cards.Reopen(reqCtx, number)
+			return done(err)
+		}); err != nil {
+			return err
+		}
+	}
+	if old.Column != nil {
+		columnID := im.mapping.Columns[old.Column.ID]
+		if current.Column == nil || columnID == "" || current.Column.ID != columnID {
+			if err := im.change("state", label, "updated", "column "+old.Column.Name, func() (string, error) {
+				if columnID == "" {
+					return "", fmt.Errorf("column %q is not in the export", old.Column.Name)
+				}
+				_, err := im.ctx.Client.Cards.Triage(reqCtx, number, columnID)
+				return done(err)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	if state != archive.StateClosed || current.Closed {
+		return nil
+	}
+	return im.change("state", label, "updated", "closed", func() (string, error) {
+		_, err := im.ctx.Client.Cards.Close(reqCtx, number)
+		return done(err)
+	})
+}
+
+// image writes a card's image from the archive to a temporary file for
+// upload. The path is empty when the archive does not hold the image, as
+// with a JSON export, which only links to it.
+func (im *importer) image(number int, image *archive.Image) (string, error) {
+	if image == nil {
+		return "", nil
+	}
+	data, ok := im.archive.Images[image.Path]
+	if image.Path == "" || !ok {
+		return "", nil
+	}
+	if im.imageDir == "" {
+		dir, err := os.MkdirTemp("", "fizzy-import-")
+		if err != nil {
+			return "", err
+		}
+		im.imageDir = dir
+	}
+	name := strings.TrimPrefix(path.Base(image.Path), strconv.Itoa(number)+"-")
+	file := filepath.Join(im.imageDir, strconv.Itoa(number), name)
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// user finds the account's user with an email or name, remembering misses.
+func (im *importer) user(who string) string {
+	if id, ok := im.users[who]; ok {
+		return id
+	}
+	id, err := resolveUser(im.ctx, who)
+	if err != nil {
+		id = ""
+	}
+	im.users[who] = id
+	return id
+}
+
+func (im *importer) isNotNow(number int) (bool, error) {
+	if number == 0 {
+		return false, nil
+	}
+	if im.notNow == nil {
+		cards, err := im.ctx.Client.Cards.Iter(&fizzy.CardListOptions{BoardIDs: []string{im.mapping.Target.BoardID}, IndexedBy: "not_now"}).All(requestContext())
+		if err != nil {
+			return false, err
+		}
+		im.notNow = map[int]bool{}
+		for _, card := range cards {
+			im.notNow[card.Number] = true
+		}
+	}
+	return im.notNow[number], nil
+}
+
+func assigned(card *fizzy.Card, userID string) bool {
+	for _, user := range card.Assignees {
+		if user.ID == userID {
+			return true
+		}
+	}
+	return false
+}
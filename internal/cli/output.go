@@ -1,16 +1,76 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
-	"text/tabwriter"
+	"text/template"
+
+	"fizzy-cli/internal/termtext"
+)
+
+// OutputFormat selects how command results are printed.
+type OutputFormat string
+
+const (
+	FormatTable    OutputFormat = "table"
+	FormatPlain    OutputFormat = "plain"
+	FormatJSON     OutputFormat = "json"
+	FormatNDJSON   OutputFormat = "ndjson"
+	FormatYAML     OutputFormat = "yaml"
+	FormatCSV      OutputFormat = "csv"
+	FormatTSV      OutputFormat = "tsv"
+	FormatMarkdown OutputFormat = "markdown"
 )
 
+var outputFormats = []OutputFormat{FormatTable, FormatPlain, FormatJSON, FormatNDJSON, FormatYAML, FormatCSV, FormatTSV, FormatMarkdown}
+
+func parseOutputFormat(value string) (OutputFormat, error) {
+	switch v := strings.ToLower(strings.TrimSpace(value)); v {
+	case "md":
+		return FormatMarkdown, nil
+	case "yml":
+		return FormatYAML, nil
+	default:
+		for _, f := range outputFormats {
+			if string(f) == v {
+				return f, nil
+			}
+		}
+	}
+	names := make([]string, 0, len(outputFormats))
+	for _, f := range outputFormats {
+		names = append(names, string(f))
+	}
+	return "", fmt.Errorf("invalid --output %q (expected %s)", value, strings.Join(names, ", "))
+}
+
 type OutputMode struct {
-	JSON  bool
-	Plain bool
+	Format   OutputFormat
+	Color    bool
+	Fields   []string
+	Template *template.Template
+	// JQ is nil unless --jq was given; "." parses to an empty path.
+	JQ []jqStep
+}
+
+// Structured reports whether output is a JSON-like document rather than
+// rows, so commands print API payloads instead of status messages.
+func (m OutputMode) Structured() bool {
+	switch {
+	case m.JQ != nil:
+		return true
+	case m.Format == FormatJSON, m.Format == FormatNDJSON, m.Format == FormatYAML:
+		return true
+	}
+	return false
+}
+
+// Tabular reports whether output is the human-oriented table or plain mode.
+func (m OutputMode) Tabular() bool {
+	return m.Format == "" || m.Format == FormatTable || m.Format == FormatPlain
 }
 
 func printJSON(w io.Writer, data any) error {
@@ -19,28 +79,86 @@ func printJSON(w io.Writer, data any) error {
 	return enc.Encode(data)
 }
 
-func printJSONBytes(w io.Writer, data []byte) error {
-	if len(data) == 0 {
+// jsonStream writes a JSON array one element at a time, producing the same
+// layout as printJSON without buffering the whole list.
+type jsonStream struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONStream(w io.Writer) *jsonStream {
+	return &jsonStream{w: w}
+}
+
+func (s *jsonStream) Write(rec record) error {
+	if len(rec.raw) == 0 {
 		return nil
 	}
-	var v any
-	if err := json.Unmarshal(data, &v); err != nil {
+	prefix := ",\n  "
+	if s.count == 0 {
+		prefix = "[\n  "
+	}
+	buf := &bytes.Buffer{}
+	if err := json.Indent(buf, rec.raw, "  ", "  "); err != nil {
 		return fmt.Errorf("invalid JSON response: %w", err)
 	}
-	return printJSON(w, v)
+	if _, err := io.WriteString(s.w, prefix); err != nil {
+		return err
+	}
+	if _, err := buf.WriteTo(s.w); err != nil {
+		return err
+	}
+	s.count++
+	return nil
 }
 
-func printTable(w io.Writer, headers []string, rows [][]string, plain bool) {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
-	if !plain && len(headers) > 0 {
-		fmt.Fprintln(tw, joinRow(headers))
+func (s *jsonStream) Close() error {
+	if s.count == 0 {
+		_, err := io.WriteString(s.w, "[]\n")
+		return err
 	}
-	for _, row := range rows {
-		fmt.Fprintln(tw, joinRow(row))
-	}
-	_ = tw.Flush()
+	_, err := io.WriteString(s.w, "\n]\n")
+	return err
+}
+
+// printTable aligns rows into columns separated by two spaces. Widths are
+// measured in terminal cells, so colored and wide text line up. With color
+// set, headers are bold.
+func printTable(w io.Writer, headers []string, rows [][]string, plain, color bool) {
+	writeTable(w, headers, rows, nil, plain, color)
 }
 
-func joinRow(cols []string) string {
-	return strings.Join(cols, "\t")
+// writeTable is printTable for one page of a longer table: columns are at
+// least as wide as widths, the widths of the pages before, which it
+// returns updated. headers is nil after the first page.
+func writeTable(w io.Writer, headers []string, rows [][]string, widths []int, plain, color bool) []int {
+	all := rows
+	if !plain && len(headers) > 0 {
+		all = append([][]string{headers}, rows...)
+	}
+	for _, row := range all {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := termtext.Width(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	buf := &bytes.Buffer{}
+	for r, row := range all {
+		for i, cell := range row {
+			if r == 0 && color && !plain && len(headers) > 0 {
+				cell = paint(cell, sgrBold)
+			}
+			buf.WriteString(cell)
+			if i < len(row)-1 {
+				buf.WriteString(strings.Repeat(" ", widths[i]-termtext.Width(cell)+2))
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	_, _ = buf.WriteTo(w)
+	return widths
 }
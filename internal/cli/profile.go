@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"fizzy-cli/internal/config"
+	"fizzy-cli/internal/credstore"
+)
+
+func runProfile(ctx Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, helpForProfile())
+		return 2
+	}
+	switch args[0] {
+	case "list":
+		current := ctx.Config.Current()
+		names := ctx.Config.ProfileNames()
+		payload := make([]map[string]any, 0, len(names))
+		raws := make([]json.RawMessage, 0, len(names))
+		for _, name := range names {
+			p, _ := ctx.Config.Profile(name)
+			item := map[string]any{
+				"name":              name,
+				"current":           name == current,
+				"base_url":          firstNonEmpty(p.BaseURL, defaultBaseURL),
+				"account":           p.Account,
+				"token_set":         p.Token != "",
+				"session_token_set": p.SessionToken != "",
+				"credential_store":  firstNonEmpty(p.CredentialStore, credstore.BackendFile),
+				"auth":              profileAuth(p),
+			}
+			raw, err := json.Marshal(item)
+			if err != nil {
+				return handleErr(helpForProfile(), err)
+			}
+			payload = append(payload, item)
+			raws = append(raws, raw)
+		}
+		return outputList(ctx, helpForProfile(), payload, raws, profileView)
+	case "add":
+		if len(args) < 2 {
+			return handleErr(helpForProfile(), UsageError{Msg: "profile name is required"})
+		}
+		name, err := profileName(args[1])
+		if err != nil {
+			return handleErr(helpForProfile(), err)
+		}
+		fs := flag.NewFlagSet("profile add", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		baseURL := fs.String("base-url", "", "API base URL")
+		account := fs.String("account", "", "Account slug")
+		token := fs.String("token", "", "Personal access token")
+		use := fs.Bool("use", false, "Make this the current profile")
+		if err := fs.Parse(args[2:]); err != nil {
+			return usageError(helpForProfile(), err)
+		}
+		if _, exists := ctx.Config.Profile(name); exists {
+			return handleErr(helpForProfile(), UsageError{Msg: fmt.Sprintf("profile %q already exists", name)})
+		}
+		cfg := ctx.Config
+		cfg.SetProfile(name, config.Profile{
+			BaseURL: strings.TrimSpace(*baseURL),
+			Account: normalizeAccount(strings.TrimSpace(*account)),
+			Token:   strings.TrimSpace(*token),
+		})
+		if *use {
+			cfg.CurrentProfile = name
+		}
+		if err := configSave(ctx.ConfigPath, cfg); err != nil {
+			return handleErr(helpForProfile(), err)
+		}
+		fmt.Fprintf(os.Stdout, "Profile %s added.\n", name)
+		return 0
+	case "use":
+		if len(args) < 2 {
+			return handleErr(helpForProfile(), UsageError{Msg: "profile name is required"})
+		}
+		name := strings.TrimSpace(args[1])
+		if _, exists := ctx.Config.Profile(name); !exists && name != config.DefaultProfile {
+			return handleErr(helpForProfile(), UsageError{Msg: fmt.Sprintf("unknown profile %q", name)})
+		}
+		cfg := ctx.Config
+		cfg.CurrentProfile = name
+		if err := configSave(ctx.ConfigPath, cfg); err != nil {
+			return handleErr(helpForProfile(), err)
+		}
+		fmt.Fprintf(os.Stdout, "Switched to profile %s.\n", name)
+		return 0
+	case "remove":
+		if len(args) < 2 {
+			return handleErr(helpForProfile(), UsageError{Msg: "profile name is required"})
+		}
+		name := strings.TrimSpace(args[1])
+		profile, exists := ctx.Config.Profile(name)
+		if !exists {
+			return handleErr(helpForProfile(), UsageError{Msg: fmt.Sprintf("unknown profile %q", name)})
+		}
+		if err := eraseCredentials(ctx, name, profile); err != nil {
+			return handleErr(helpForProfile(), err)
+		}
+		cfg := ctx.Config
+		cfg.RemoveProfile(name)
+		if err := configSave(ctx.ConfigPath, cfg); err != nil {
+			return handleErr(helpForProfile(), err)
+		}
+		fmt.Fprintf(os.Stdout, "Profile %s removed.\n", name)
+		return 0
+	case "rename":
+		if len(args) < 3 {
+			return handleErr(helpForProfile(), UsageError{Msg: "old and new profile names are required"})
+		}
+		oldName := strings.TrimSpace(args[1])
+		newName, err := profileName(args[2])
+		if err != nil {
+			return handleErr(helpForProfile(), err)
+		}
+		profile, exists := ctx.Config.Profile(oldName)
+		if !exists {
+			return handleErr(helpForProfile(), UsageError{Msg: fmt.Sprintf("unknown profile %q", oldName)})
+		}
+		if _, exists := ctx.Config.Profile(newName); exists {
+			return handleErr(helpForProfile(), UsageError{Msg: fmt.Sprintf("profile %q already exists", newName)})
+		}
+		if err := moveCredentials(ctx, oldName, newName, profile); err != nil {
+			return handleErr(helpForProfile(), err)
+		}
+		cfg := ctx.Config
+		wasCurrent := cfg.Current() == oldName
+		cfg.RemoveProfile(oldName)
+		cfg.SetProfile(newName, profile)
+		if wasCurrent {
+			cfg.CurrentProfile = newName
+		}
+		if err := configSave(ctx.ConfigPath, cfg); err != nil {
+			return handleErr(helpForProfile(), err)
+		}
+		fmt.Fprintf(os.Stdout, "Profile %s renamed to %s.\n", oldName, newName)
+		return 0
+	default:
+		fmt.Fprint(os.Stderr, helpForProfile())
+		return 2
+	}
+}
+
+var profileView = view[map[string]any]{headers: []string{"CURRENT", "NAME", "BASE_URL", "ACCOUNT", "AUTH"}, row: profileListRow}
+
+func profileListRow(p map[string]any) []string {
+	marker := ""
+	if p["current"] == true {
+		marker = "*"
+	}
+	return []string{marker, fieldString(p["name"]), fieldString(p["base_url"]), fieldString(p["account"]), fieldString(p["auth"])}
+}
+
+func profileName(value string) (string, error) {
+	name := strings.TrimSpace(value)
+	if name == "" || strings.ContainsAny(name, " \t\n/") {
+		return "", UsageError{Msg: fmt.Sprintf("invalid profile name %q", value)}
+	}
+	return name, nil
+}
+
+func profileAuth(p config.Profile) string {
+	if !usesFileStore(p) {
+		return p.CredentialStore
+	}
+	switch {
+	case p.Token != "":
+		return "token"
+	case p.SessionToken != "":
+		return "session"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,330 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// decodeJSON decodes raw into generic values, keeping numbers as written so
+// card numbers and IDs do not turn into floats.
+func decodeJSON(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON response: %w", err)
+	}
+	return v, nil
+}
+
+// rawItems returns the elements of the array stored under key in body.
+func rawItems(body []byte, key string) []json.RawMessage {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(envelope[key], &items); err != nil {
+		return nil
+	}
+	return items
+}
+
+func parseFields(value string) []string {
+	var fields []string
+	for _, f := range strings.Split(value, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func fieldHeaders(fields []string) []string {
+	headers := make([]string, 0, len(fields))
+	for _, f := range fields {
+		headers = append(headers, strings.ToUpper(strings.ReplaceAll(f, ".", "_")))
+	}
+	return headers
+}
+
+// fieldValue looks up a dotted path such as "board.name" or "steps.0.content"
+// in a decoded JSON document.
+func fieldValue(doc any, path string) any {
+	current := doc
+	for _, part := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			current = v[part]
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			current = v[i]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+func fieldRow(doc any, fields []string) []string {
+	row := make([]string, 0, len(fields))
+	for _, f := range fields {
+		row = append(row, fieldString(fieldValue(doc, f)))
+	}
+	return row
+}
+
+// selectFields returns a JSON object holding only the given fields, in the
+// order they were requested.
+func selectFields(doc any, fields []string) (json.RawMessage, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(fieldValue(doc, f))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func fieldString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fieldString(item))
+		}
+		return strings.Join(parts, ",")
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// jqStep is one segment of a --jq path: an object key, an array index, or
+// [] to iterate over every element.
+type jqStep struct {
+	key     string
+	index   int
+	isIndex bool
+	iterate bool
+}
+
+// parseJQ accepts the path subset of jq: ".", ".key", ".key.sub", ".[0]",
+// ".[]", ".items[].title" and quoted keys such as .["odd key"], and pipes
+// of paths such as ".[] | .title".
+func parseJQ(expr string) ([]jqStep, error) {
+	expr = strings.TrimSpace(expr)
+	steps := []jqStep{}
+	for _, path := range splitJQPipe(expr) {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return nil, fmt.Errorf("invalid --jq expression %q: empty pipe segment", expr)
+		}
+		pathSteps, err := parseJQPath(expr, path)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, pathSteps...)
+	}
+	return steps, nil
+}
+
+// splitJQPipe splits expr at each | outside quotes.
+func splitJQPipe(expr string) []string {
+	var parts []string
+	inQuote := false
+	start := 0
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case expr[i] == '\\' && inQuote:
+			i++
+		case expr[i] == '"':
+			inQuote = !inQuote
+		case expr[i] == '|' && !inQuote:
+			parts = append(parts, expr[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, expr[start:])
+}
+
+// parseJQPath parses one path of expr.
+func parseJQPath(expr, path string) ([]jqStep, error) {
+	if !strings.HasPrefix(path, ".") {
+		return nil, fmt.Errorf("invalid --jq expression %q: must start with '.'", expr)
+	}
+	steps := []jqStep{}
+	rest := path
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid --jq expression %q: missing ']'", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "":
+				steps = append(steps, jqStep{iterate: true})
+			case strings.HasPrefix(inner, `"`):
+				key, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid --jq expression %q: %w", expr, err)
+				}
+				steps = append(steps, jqStep{key: key})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid --jq expression %q: bad index %q", expr, inner)
+				}
+				steps = append(steps, jqStep{index: i, isIndex: true})
+			}
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			if rest == "" || strings.HasPrefix(rest, "[") {
+				continue
+			}
+			if strings.HasPrefix(rest, `"`) {
+				end := strings.Index(rest[1:], `"`)
+				if end < 0 {
+					return nil, fmt.Errorf("invalid --jq expression %q: unterminated key", expr)
+				}
+				steps = append(steps, jqStep{key: rest[1 : end+1]})
+				rest = rest[end+2:]
+				continue
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			steps = append(steps, jqStep{key: rest[:end]})
+			rest = rest[end:]
+		default:
+			return nil, fmt.Errorf("invalid --jq expression %q: unexpected %q", expr, rest)
+		}
+	}
+	return steps, nil
+}
+
+func applyJQ(doc any, steps []jqStep) ([]any, error) {
+	values := []any{doc}
+	for _, step := range steps {
+		next := make([]any, 0, len(values))
+		for _, v := range values {
+			switch {
+			case step.iterate:
+				switch v := v.(type) {
+				case []any:
+					next = append(next, v...)
+				case map[string]any:
+					keys := make([]string, 0, len(v))
+					for k := range v {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, v[k])
+					}
+				case nil:
+				default:
+					return nil, fmt.Errorf("--jq: cannot iterate over %s", jsonType(v))
+				}
+			case step.isIndex:
+				switch v := v.(type) {
+				case []any:
+					i := step.index
+					if i < 0 {
+						i += len(v)
+					}
+					if i < 0 || i >= len(v) {
+						next = append(next, nil)
+					} else {
+						next = append(next, v[i])
+					}
+				case nil:
+					next = append(next, nil)
+				default:
+					return nil, fmt.Errorf("--jq: cannot index %s with a number", jsonType(v))
+				}
+			default:
+				switch v := v.(type) {
+				case map[string]any:
+					next = append(next, v[step.key])
+				case nil:
+					next = append(next, nil)
+				default:
+					return nil, fmt.Errorf("--jq: cannot index %s with %q", jsonType(v), step.key)
+				}
+			}
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// writeJQResults prints each result on its own line. Strings are printed
+// without quotes, like jq -r.
+func writeJQResults(w io.Writer, values []any) error {
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := printJSON(w, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const queryDoc = `{
+  "number": 7,
+  "title": "Add dark mode",
+  "closed": false,
+  "board": {"name": "Roadmap", "id": "b1"},
+  "tags": ["feature", "design"],
+  "steps": [
+    {"content": "Pick the palette", "completed": true},
+    {"content": "Add the toggle", "completed": false}
+  ],
+  "odd key": {"a.b": 1},
+  "column": null
+}`
+
+func TestJQ(t *testing.T) {
+	doc, err := decodeJSON([]byte(queryDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr string
+		want string // the results as written by writeJQResults
+		err  string
+	}{
+		{expr: ".title", want: "Add dark mode\n"},
+		{expr: " .board.name ", want: "Roadmap\n"},
+		{expr: ".number", want: "7\n"},
+		{expr: ".closed", want: "false\n"},
+		{expr: ".missing", want: "null\n"},
+		{expr: ".column.name", want: "null\n"},
+		{expr: ".tags[0]", want: "feature\n"},
+		{expr: ".tags[-1]", want: "design\n"},
+		{expr: ".tags[5]", want: "null\n"},
+		{expr: ".tags[]", want: "feature\ndesign\n"},
+		{expr: ".steps[].content", want: "Pick the palette\nAdd the toggle\n"},
+		{expr: ".steps[1].completed", want: "false\n"},
+		{expr: ".board[]", want: "b1\nRoadmap\n"},
+		{expr: `.["odd key"]["a.b"]`, want: "1\n"},
+		{expr: `."odd key"`, want: "{\n  \"a.b\": 1\n}\n"},
+		{expr: ".tags", want: "[\n  \"feature\",\n  \"design\"\n]\n"},
+		{expr: ".steps[] | .content", want: "Pick the palette\nAdd the toggle\n"},
+		{expr: ".steps | .[0] | .content", want: "Pick the palette\n"},
+		{expr: `.["a|b"]`, want: "null\n"},
+		{expr: "title", err: `invalid --jq expression "title": must start with '.'`},
+		{expr: ".tags[0", err: `invalid --jq expression ".tags[0": missing ']'`},
+		{expr: ".tags[x]", err: `invalid --jq expression ".tags[x]": bad index "x"`},
+		{expr: `."odd`, err: `invalid --jq expression ".\"odd": unterminated key`},
+		{expr: ".tags | ", err: `invalid --jq expression ".tags |": empty pipe segment`},
+		{expr: ".tags | title", err: `invalid --jq expression ".tags | title": must start with '.'`},
+		{expr: ".title[]", err: "--jq: cannot iterate over string"},
+		{expr: ".board[0]", err: "--jq: cannot index object with a number"},
+		{expr: ".tags.name", err: `--jq: cannot index array with "name"`},
+		{expr: ".steps[] | .content.x", err: `--jq: cannot index string with "x"`},
+	}
+	for _, tt := range tests {
+		steps, err := parseJQ(tt.expr)
+		var values []any
+		if err == nil {
+			values, err = applyJQ(doc, steps)
+		}
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: error = %v, want %q", tt.expr, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		buf := &bytes.Buffer{}
+		if err := writeJQResults(buf, values); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s = %q, want %q", tt.expr, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	doc, err := decodeJSON([]byte(queryDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := parseFields(" number, board.name,,steps.1.content,tags,steps.9.content,closed ")
+	if got := strings.Join(fields, "|"); got != "number|board.name|steps.1.content|tags|steps.9.content|closed" {
+		t.Fatalf("parseFields = %s", got)
+	}
+	if got := strings.Join(fieldHeaders(fields), "|"); got != "NUMBER|BOARD_NAME|STEPS_1_CONTENT|TAGS|STEPS_9_CONTENT|CLOSED" {
+		t.Errorf("fieldHeaders = %s", got)
+	}
+	if got := strings.Join(fieldRow(doc, fields), "|"); got != "7|Roadmap|Add the toggle|feature,design||false" {
+		t.Errorf("fieldRow = %s", got)
+	}
+	raw, err := selectFields(doc, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"number":7,"board.name":"Roadmap","steps.1.content":"Add the toggle","tags":["feature","design"],"steps.9.content":null,"closed":false}`
+	if string(raw) != want {
+		t.Errorf("selectFields = %s, want %s", raw, want)
+	}
+	if got := fieldString(fieldValue(doc, "board")); got != `{"id":"b1","name":"Roadmap"}` {
+		t.Errorf("object field = %s", got)
+	}
+}
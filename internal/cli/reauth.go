@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"fizzy-cli/pkg/fizzy"
+)
+
+// credentialsError explains a 401: which credentials were rejected and how
+// to replace them.
+type credentialsError struct {
+	err     *fizzy.APIError
+	session bool
+}
+
+func (e *credentialsError) Error() string {
+	if e.session {
+		return "session expired; run 'fizzy-cli auth login --email <email>' to sign in again"
+	}
+	return "token rejected (invalid, expired or revoked); run 'fizzy-cli auth login' or set FIZZY_TOKEN"
+}
+
+func (e *credentialsError) Unwrap() error { return e.err }
+
+// unauthorizedHandler turns 401 responses into a credentialsError. With a
+// login function, an expired session is renewed once with it and the
+// request retried.
+func unauthorizedHandler(transport *fizzy.Transport, login func() (string, bool)) func(context.Context, *fizzy.APIError) (bool, error) {
+	var mu sync.Mutex
+	asked, renewed := false, false
+	return func(_ context.Context, apiErr *fizzy.APIError) (bool, error) {
+		// card bulk sends requests in parallel: one login runs, and the
+		// requests that failed meanwhile are retried with its session.
+		mu.Lock()
+		defer mu.Unlock()
+		token, sessionToken := transport.Credentials()
+		session := token == "" && sessionToken != ""
+		if session && login != nil && !asked {
+			asked = true
+			if token, ok := login(); ok {
+				transport.SetSessionToken(token)
+				renewed = true
+			}
+		}
+		if renewed {
+			return true, nil
+		}
+		return false, &credentialsError{err: apiErr, session: session}
+	}
+}
+
+// reloginPrompt returns the login function for unauthorizedHandler: a
+// magic-link login when there is a terminal to ask on, otherwise nil.
+func reloginPrompt(ctx Context) func() (string, bool) {
+	if !interactive() {
+		return nil
+	}
+	return func() (string, bool) { return relogin(ctx) }
+}
+
+// relogin asks for an email address and runs the magic-link login. An
+// empty answer cancels it.
+func relogin(ctx Context) (string, bool) {
+	fmt.Fprintln(os.Stderr, "Your session has expired.")
+	email, err := readSecret("Email to send a new login code to (empty to cancel)")
+	if err != nil || strings.TrimSpace(email) == "" {
+		return "", false
+	}
+	token, location, err := authMagicLink(ctx, strings.TrimSpace(email), "")
+	if err != nil {
+		printErr(err)
+		return "", false
+	}
+	fmt.Fprintf(os.Stderr, "Session saved to %s (profile %s)\n", location, ctx.ProfileName)
+	return token, true
+}
+
+func interactive() bool {
+	return isTTY(os.Stdin) && isTTY(os.Stderr)
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fizzy-cli/pkg/fizzy"
+)
+
+// sessionServer accepts only the session token "renewed".
+func sessionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session_token"); err != nil || c.Value != "renewed" {
+			http.Error(w, `{"error": "unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestReloginOnceForParallelRequests(t *testing.T) {
+	srv := sessionServer(t)
+	transport := fizzy.NewTransport(srv.URL, "", "expired", "test")
+	var logins atomic.Int32
+	transport.Unauthorized = unauthorizedHandler(transport, func() (string, bool) {
+		logins.Add(1)
+		// Keep the other requests waiting on the login.
+		time.Sleep(50 * time.Millisecond)
+		return "renewed", true
+	})
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = transport.Do(context.Background(), http.MethodGet, "/my/identity", nil, nil, "", nil)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		t.Errorf("requests failed: %v", err)
+	}
+	if n := logins.Load(); n != 1 {
+		t.Errorf("logged in %d times, want once", n)
+	}
+	if _, session := transport.Credentials(); session != "renewed" {
+		t.Errorf("session token = %q, want the renewed one", session)
+	}
+}
+
+func TestReloginCanceled(t *testing.T) {
+	srv := sessionServer(t)
+	transport := fizzy.NewTransport(srv.URL, "", "expired", "test")
+	var logins atomic.Int32
+	transport.Unauthorized = unauthorizedHandler(transport, func() (string, bool) {
+		logins.Add(1)
+		return "", false
+	})
+	for i := 0; i < 2; i++ {
+		_, err := transport.Do(context.Background(), http.MethodGet, "/my/identity", nil, nil, "", nil)
+		var credErr *credentialsError
+		if !errors.As(err, &credErr) || !credErr.session {
+			t.Errorf("request %d: got %v, want an expired session error", i+1, err)
+		}
+	}
+	if n := logins.Load(); n != 1 {
+		t.Errorf("asked to log in %d times, want once", n)
+	}
+}
+
+func TestTokenRejected(t *testing.T) {
+	srv := sessionServer(t)
+	transport := fizzy.NewTransport(srv.URL, "bad-token", "", "test")
+	transport.Unauthorized = unauthorizedHandler(transport, func() (string, bool) {
+		t.Error("asked to log in for a rejected token")
+		return "", false
+	})
+	_, err := transport.Do(context.Background(), http.MethodGet, "/my/identity", nil, nil, "", nil)
+	var credErr *credentialsError
+	if !errors.As(err, &credErr) || credErr.session || exitCode(err) != 3 {
+		t.Errorf("got %v (exit %d), want a rejected token error with exit 3", err, exitCode(err))
+	}
+}
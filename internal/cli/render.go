@@ -0,0 +1,498 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"fizzy-cli/pkg/fizzy"
+)
+
+// view describes how one kind of item is displayed: the columns used by
+// tabular formats and, optionally, the detailed text shown by get commands
+// in table mode and the SGR attributes of each cell when color is on.
+type view[T any] struct {
+	headers []string
+	row     func(T) []string
+	detail  func(*T) string
+	style   func(T) []string
+}
+
+func (v view[T]) columns(mode OutputMode) []string {
+	if len(mode.Fields) > 0 {
+		return fieldHeaders(mode.Fields)
+	}
+	return v.headers
+}
+
+// record is one item handed to a renderer. value is what --format templates
+// see, raw the item's JSON and row its table cells.
+type record struct {
+	value any
+	raw   json.RawMessage
+	row   []string
+}
+
+func newRecord[T any](mode OutputMode, item T, value any, raw json.RawMessage, v view[T]) (record, error) {
+	rec := record{value: value, raw: raw}
+	if len(mode.Fields) == 0 {
+		if v.row != nil {
+			rec.row = v.row(item)
+		}
+		if mode.Color && v.style != nil {
+			for i, attrs := range v.style(item) {
+				if i < len(rec.row) {
+					rec.row[i] = paint(rec.row[i], attrs)
+				}
+			}
+		}
+		return rec, nil
+	}
+	doc, err := decodeJSON(raw)
+	if err != nil {
+		return rec, err
+	}
+	rec.row = fieldRow(doc, mode.Fields)
+	rec.raw, err = selectFields(doc, mode.Fields)
+	return rec, err
+}
+
+// renderer writes records in one output format. single is set when the
+// output is one object rather than a list.
+type renderer interface {
+	Write(rec record) error
+	Close() error
+}
+
+func newRenderer(w io.Writer, mode OutputMode, headers []string, single bool) renderer {
+	switch {
+	case mode.JQ != nil:
+		return &jqRenderer{w: w, steps: mode.JQ, single: single}
+	case mode.Template != nil:
+		return &templateRenderer{w: w, tmpl: mode.Template}
+	}
+	switch mode.Format {
+	case FormatJSON:
+		if single {
+			return &jsonItemRenderer{w: w}
+		}
+		return newJSONStream(w)
+	case FormatNDJSON:
+		return &ndjsonRenderer{w: w}
+	case FormatYAML:
+		return &yamlRenderer{w: w, single: single}
+	case FormatCSV:
+		return newDelimitedRenderer(w, headers, ',')
+	case FormatTSV:
+		return newDelimitedRenderer(w, headers, '\t')
+	case FormatMarkdown:
+		return &markdownRenderer{w: w, headers: headers}
+	}
+	return &tableRenderer{w: w, headers: headers, plain: mode.Format == FormatPlain, color: mode.Color}
+}
+
+// tablePageRows is how many rows a table holds before printing them, so
+// that long lists stream. Columns are sized by the rows printed so far, so
+// a later page can only widen them.
+const tablePageRows = 100
+
+type tableRenderer struct {
+	w       io.Writer
+	headers []string
+	plain   bool
+	color   bool
+	rows    [][]string
+	widths  []int
+	started bool
+}
+
+func (r *tableRenderer) Write(rec record) error {
+	r.rows = append(r.rows, rec.row)
+	if len(r.rows) >= tablePageRows {
+		r.flush()
+	}
+	return nil
+}
+
+func (r *tableRenderer) flush() {
+	headers := r.headers
+	if r.started {
+		headers = nil
+	}
+	r.widths = writeTable(r.w, headers, r.rows, r.widths, r.plain, r.color)
+	r.rows, r.started = r.rows[:0], true
+}
+
+func (r *tableRenderer) Close() error {
+	if len(r.rows) > 0 || !r.started {
+		r.flush()
+	}
+	return nil
+}
+
+type jsonItemRenderer struct {
+	w io.Writer
+}
+
+func (r *jsonItemRenderer) Write(rec record) error {
+	if len(rec.raw) == 0 {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	if err := json.Indent(buf, rec.raw, "", "  "); err != nil {
+		return fmt.Errorf("invalid JSON response: %w", err)
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(r.w)
+	return err
+}
+
+func (r *jsonItemRenderer) Close() error { return nil }
+
+type ndjsonRenderer struct {
+	w io.Writer
+}
+
+func (r *ndjsonRenderer) Write(rec record) error {
+	if len(rec.raw) == 0 {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, rec.raw); err != nil {
+		return fmt.Errorf("invalid JSON response: %w", err)
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(r.w)
+	return err
+}
+
+func (r *ndjsonRenderer) Close() error { return nil }
+
+type yamlRenderer struct {
+	w      io.Writer
+	single bool
+	count  int
+}
+
+func (r *yamlRenderer) Write(rec record) error {
+	if len(rec.raw) == 0 {
+		return nil
+	}
+	node, err := parseYAMLNode(rec.raw)
+	if err != nil {
+		return err
+	}
+	r.count++
+	if r.single {
+		return writeYAMLLines(r.w, node.lines())
+	}
+	list := &yamlNode{array: true, items: []*yamlNode{node}}
+	return writeYAMLLines(r.w, list.lines())
+}
+
+func (r *yamlRenderer) Close() error {
+	if !r.single && r.count == 0 {
+		_, err := io.WriteString(r.w, "[]\n")
+		return err
+	}
+	return nil
+}
+
+// delimitedRenderer writes CSV or TSV. Fields containing the separator,
+// quotes or newlines are quoted, so card titles and comment bodies survive
+// a round trip through a spreadsheet.
+type delimitedRenderer struct {
+	w       *csv.Writer
+	headers []string
+	started bool
+}
+
+func newDelimitedRenderer(w io.Writer, headers []string, comma rune) *delimitedRenderer {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	return &delimitedRenderer{w: cw, headers: headers}
+}
+
+func (r *delimitedRenderer) header() error {
+	if r.started {
+		return nil
+	}
+	r.started = true
+	if len(r.headers) == 0 {
+		return nil
+	}
+	return r.w.Write(r.headers)
+}
+
+func (r *delimitedRenderer) Write(rec record) error {
+	if err := r.header(); err != nil {
+		return err
+	}
+	if err := r.w.Write(rec.row); err != nil {
+		return err
+	}
+	r.w.Flush()
+	return r.w.Error()
+}
+
+func (r *delimitedRenderer) Close() error {
+	if err := r.header(); err != nil {
+		return err
+	}
+	r.w.Flush()
+	return r.w.Error()
+}
+
+type markdownRenderer struct {
+	w       io.Writer
+	headers []string
+	started bool
+}
+
+func (r *markdownRenderer) header() error {
+	if r.started {
+		return nil
+	}
+	r.started = true
+	if len(r.headers) == 0 {
+		return nil
+	}
+	separators := make([]string, len(r.headers))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	_, err := fmt.Fprintf(r.w, "%s\n%s\n", markdownRow(r.headers), markdownRow(separators))
+	return err
+}
+
+func (r *markdownRenderer) Write(rec record) error {
+	if err := r.header(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(r.w, markdownRow(rec.row))
+	return err
+}
+
+func (r *markdownRenderer) Close() error {
+	return r.header()
+}
+
+func markdownRow(cells []string) string {
+	escaped := make([]string, 0, len(cells))
+	for _, c := range cells {
+		c = strings.ReplaceAll(c, `\`, `\\`)
+		c = strings.ReplaceAll(c, "|", `\|`)
+		c = strings.ReplaceAll(strings.ReplaceAll(c, "\r\n", "\n"), "\n", "<br>")
+		escaped = append(escaped, c)
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+type templateRenderer struct {
+	w    io.Writer
+	tmpl *template.Template
+}
+
+func (r *templateRenderer) Write(rec record) error {
+	return executeTemplate(r.w, r.tmpl, rec.value)
+}
+
+func (r *templateRenderer) Close() error { return nil }
+
+// jqRenderer applies --jq to the output as a whole. On lists, expressions
+// that start with .[] are applied item by item so output still streams.
+type jqRenderer struct {
+	w      io.Writer
+	steps  []jqStep
+	single bool
+	items  []any
+}
+
+func (r *jqRenderer) streaming() bool {
+	return !r.single && len(r.steps) > 0 && r.steps[0].iterate
+}
+
+func (r *jqRenderer) Write(rec record) error {
+	if len(rec.raw) == 0 {
+		return nil
+	}
+	doc, err := decodeJSON(rec.raw)
+	if err != nil {
+		return err
+	}
+	switch {
+	case r.single:
+		return r.emit(doc, r.steps)
+	case r.streaming():
+		return r.emit(doc, r.steps[1:])
+	}
+	r.items = append(r.items, doc)
+	return nil
+}
+
+func (r *jqRenderer) Close() error {
+	if r.single || r.streaming() {
+		return nil
+	}
+	items := r.items
+	if items == nil {
+		items = []any{}
+	}
+	return r.emit(items, r.steps)
+}
+
+func (r *jqRenderer) emit(doc any, steps []jqStep) error {
+	values, err := applyJQ(doc, steps)
+	if err != nil {
+		return err
+	}
+	return writeJQResults(r.w, values)
+}
+
+func executeTemplate(w io.Writer, tmpl *template.Template, value any) error {
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, value); err != nil {
+		return fmt.Errorf("--format: %w", err)
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func outputIterator[T any](ctx Context, help string, it *fizzy.Iterator[T], v view[T]) int {
+	r := newRenderer(os.Stdout, ctx.Output, v.columns(ctx.Output), false)
+	// On an error part way, what was printed is completed first, e.g. the
+	// JSON array closed, so that stdout stays well-formed.
+	fail := func(err error) int {
+		r.Close()
+		return handleErr(help, err)
+	}
+	for it.Next(requestContext()) {
+		item := it.Item()
+		rec, err := newRecord(ctx.Output, item, item, it.Raw(), v)
+		if err != nil {
+			return fail(err)
+		}
+		if err := r.Write(rec); err != nil {
+			return fail(err)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return fail(err)
+	}
+	if err := r.Close(); err != nil {
+		return handleErr(help, err)
+	}
+	return 0
+}
+
+// outputList prints items that are already in memory, such as the accounts
+// embedded in an identity. raws holds each item's JSON.
+func outputList[T any](ctx Context, help string, items []T, raws []json.RawMessage, v view[T]) int {
+	r := newRenderer(os.Stdout, ctx.Output, v.columns(ctx.Output), false)
+	fail := func(err error) int {
+		r.Close()
+		return handleErr(help, err)
+	}
+	for i, item := range items {
+		var raw json.RawMessage
+		if i < len(raws) {
+			raw = raws[i]
+		}
+		rec, err := newRecord(ctx.Output, item, item, raw, v)
+		if err != nil {
+			return fail(err)
+		}
+		if err := r.Write(rec); err != nil {
+			return fail(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		return handleErr(help, err)
+	}
+	return 0
+}
+
+// outputItem prints the result of a get command. In table and plain mode
+// the view's detail text is shown unless --fields asks for columns.
+func outputItem[T any](ctx Context, resp *fizzy.Response, item *T, v view[T]) int {
+	mode := ctx.Output
+	if mode.Tabular() && mode.JQ == nil && mode.Template == nil && len(mode.Fields) == 0 && v.detail != nil {
+		fmt.Fprintln(os.Stdout, v.detail(item))
+		return 0
+	}
+	rec, err := newRecord(mode, *item, item, resp.Body, v)
+	if err != nil {
+		return handleErr("", err)
+	}
+	if err := renderOne(mode, v.columns(mode), rec); err != nil {
+		return handleErr("", err)
+	}
+	return 0
+}
+
+// outputPayload prints a small result object built by the CLI itself, such
+// as the status of a mutation or the effective configuration.
+func outputPayload(ctx Context, payload any) int {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return handleErr("", err)
+	}
+	rec, err := newRecord(ctx.Output, any(payload), payload, raw, view[any]{})
+	if err != nil {
+		return handleErr("", err)
+	}
+	if err := renderOne(ctx.Output, nil, rec); err != nil {
+		return handleErr("", err)
+	}
+	return 0
+}
+
+func renderOne(mode OutputMode, headers []string, rec record) error {
+	r := newRenderer(os.Stdout, mode, headers, true)
+	if err := r.Write(rec); err != nil {
+		return err
+	}
+	return r.Close()
+}
+
+func outputLocation(ctx Context, resp *fizzy.Response, successMessage string) int {
+	location := resp.Headers.Get("Location")
+	if ctx.Output.Structured() {
+		return outputPayload(ctx, map[string]any{"status": resp.Status, "location": location})
+	}
+	if location != "" {
+		fmt.Fprintf(os.Stdout, "%s: %s\n", successMessage, location)
+		return 0
+	}
+	fmt.Fprintln(os.Stdout, successMessage+".")
+	return 0
+}
+
+func outputNoContent(ctx Context, resp *fizzy.Response, successMessage string) int {
+	if ctx.Output.Structured() {
+		return outputPayload(ctx, map[string]any{"status": resp.Status})
+	}
+	fmt.Fprintln(os.Stdout, successMessage+".")
+	return 0
+}
+
+// outputResponse prints a response body as is for the structured formats.
+func outputResponse(ctx Context, resp *fizzy.Response) int {
+	if len(resp.Body) == 0 {
+		return outputPayload(ctx, map[string]any{"status": resp.Status})
+	}
+	doc, err := decodeJSON(resp.Body)
+	if err != nil {
+		return handleErr("", err)
+	}
+	if err := renderOne(ctx.Output, nil, record{value: doc, raw: resp.Body}); err != nil {
+		return handleErr("", err)
+	}
+	return 0
+}
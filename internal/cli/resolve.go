@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"fizzy-cli/internal/cache"
+	"fizzy-cli/pkg/fizzy"
+)
+
+// Fizzy IDs are 25 character base36 strings; values shaped like one are
+// passed through without a lookup.
+var idPattern = regexp.MustCompile(`^[0-9a-z]{25}$`)
+
+type candidate struct {
+	ID     string   `json:"id"`
+	Label  string   `json:"label"`
+	Names  []string `json:"names"`
+	Detail string   `json:"detail,omitempty"`
+}
+
+type ambiguousError struct {
+	Kind       string
+	Value      string
+	Candidates []candidate
+}
+
+func (e ambiguousError) Error() string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "%q matches %d %ss; use one of these IDs:", e.Value, len(e.Candidates), e.Kind)
+	for _, c := range e.Candidates {
+		fmt.Fprintf(builder, "\n  %s  %s", c.ID, c.Label)
+		if c.Detail != "" {
+			fmt.Fprintf(builder, " (%s)", c.Detail)
+		}
+	}
+	return builder.String()
+}
+
+func resolveBoard(ctx Context, value string) (string, error) {
+	return resolveName(ctx, "board", "", value, func() ([]candidate, error) {
+		return boardCandidates(ctx)
+	})
+}
+
+func resolveColumn(ctx Context, boardID, value string) (string, error) {
+	return resolveName(ctx, "column", boardID, value, func() ([]candidate, error) {
+		return columnCandidates(ctx, boardID)
+	})
+}
+
+// resolveCardColumn resolves a column name for a card by looking up the
+// board the card belongs to.
+func resolveCardColumn(ctx Context, number int, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if idPattern.MatchString(value) {
+		return value, nil
+	}
+	card, _, err := ctx.Client.Cards.Get(requestContext(), number)
+	if err != nil {
+		return "", err
+	}
+	return resolveColumn(ctx, card.Board.ID, value)
+}
+
+func resolveUser(ctx Context, value string) (string, error) {
+	return resolveName(ctx, "user", "", value, func() ([]candidate, error) {
+		return userCandidates(ctx)
+	})
+}
+
+func resolveTag(ctx Context, value string) (string, error) {
+	return resolveName(ctx, "tag", "", strings.TrimPrefix(strings.TrimSpace(value), "#"), func() ([]candidate, error) {
+		return tagCandidates(ctx)
+	})
+}
+
+func resolveBoardColumn(ctx Context, boardValue, columnValue string) (string, string, error) {
+	boardID, err := resolveBoard(ctx, boardValue)
+	if err != nil {
+		return "", "", err
+	}
+	columnID, err := resolveColumn(ctx, boardID, columnValue)
+	if err != nil {
+		return "", "", err
+	}
+	return boardID, columnID, nil
+}
+
+func resolveCardFilters(ctx Context, opts *fizzy.CardListOptions, boards, tags, assignees, creators, closers []string) error {
+	var err error
+	if opts.BoardIDs, err = resolveEach(ctx, boards, resolveBoard); err != nil {
+		return err
+	}
+	if opts.TagIDs, err = resolveEach(ctx, tags, resolveTag); err != nil {
+		return err
+	}
+	if opts.AssigneeIDs, err = resolveEach(ctx, assignees, resolveUser); err != nil {
+		return err
+	}
+	if opts.CreatorIDs, err = resolveEach(ctx, creators, resolveUser); err != nil {
+		return err
+	}
+	if opts.CloserIDs, err = resolveEach(ctx, closers, resolveUser); err != nil {
+		return err
+	}
+	return nil
+}
+
+func resolveEach(ctx Context, values []string, fn func(Context, string) (string, error)) ([]string, error) {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		id, err := fn(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// resolveName maps a name, email or title to an ID using the cached list
+// for kind. A cache miss or an unmatched value triggers a fresh fetch.
+func resolveName(ctx Context, kind, scope, value string, fetch func() ([]candidate, error)) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || idPattern.MatchString(value) {
+		return value, nil
+	}
+
+	store := resolveCache(ctx)
+	key := candidateKey(ctx, kind, scope)
+	var candidates []candidate
+	cached := store.Get(key, &candidates)
+	for {
+		if !cached {
+			fetched, err := fetch()
+			if err != nil {
+				return "", err
+			}
+			candidates = fetched
+			_ = store.Put(key, candidates)
+		}
+		matches := matchCandidates(candidates, value)
+		switch {
+		case len(matches) == 1:
+			return matches[0].ID, nil
+		case len(matches) > 1:
+			return "", ambiguousError{Kind: kind, Value: value, Candidates: matches}
+		case cached:
+			cached = false
+			continue
+		}
+		return "", fmt.Errorf("no %s matches %q", kind, value)
+	}
+}
+
+func boardCandidates(ctx Context) ([]candidate, error) {
+	boards, err := ctx.Client.Boards.Iter(nil).All(requestContext())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]candidate, 0, len(boards))
+	for _, b := range boards {
+		out = append(out, candidate{ID: b.ID, Label: b.Name, Names: []string{b.Name}})
+	}
+	return out, nil
+}
+
+func columnCandidates(ctx Context, boardID string) ([]candidate, error) {
+	columns, err := ctx.Client.Columns.Iter(boardID, nil).All(requestContext())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]candidate, 0, len(columns))
+	for _, c := range columns {
+		out = append(out, candidate{ID: c.ID, Label: c.Name, Names: []string{c.Name}})
+	}
+	return out, nil
+}
+
+func userCandidates(ctx Context) ([]candidate, error) {
+	users, err := ctx.Client.Users.Iter(nil).All(requestContext())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]candidate, 0, len(users))
+	for _, u := range users {
+		out = append(out, candidate{ID: u.ID, Label: u.Name, Names: []string{u.Name, u.Email}, Detail: u.Email})
+	}
+	return out, nil
+}
+
+func tagCandidates(ctx Context) ([]candidate, error) {
+	tags, err := ctx.Client.Tags.Iter(nil).All(requestContext())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]candidate, 0, len(tags))
+	for _, t := range tags {
+		out = append(out, candidate{ID: t.ID, Label: t.Title, Names: []string{t.Title}})
+	}
+	return out, nil
+}
+
+func candidateKey(ctx Context, kind, scope string) string {
+	return cache.Key("resolve", ctx.BaseURL, ctx.Account, kind, scope)
+}
+
+func matchCandidates(candidates []candidate, value string) []candidate {
+	for _, c := range candidates {
+		if c.ID == value {
+			return []candidate{c}
+		}
+	}
+	var matches []candidate
+	for _, c := range candidates {
+		for _, name := range c.Names {
+			if name != "" && strings.EqualFold(name, value) {
+				matches = append(matches, c)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func resolveCache(ctx Context) *cache.Store {
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil
+	}
+	return cache.New(dir, ctx.CacheTTL)
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"fizzy-cli/pkg/fizzy"
+)
+
+var stepView = view[fizzy.Step]{headers: []string{"ID", "DONE", "CONTENT"}, row: stepListRow, style: stepListStyle}
+
+func stepListRow(s fizzy.Step) []string {
+	done := "no"
+	if s.Completed {
+		done = "yes"
+	}
+	return []string{s.ID, done, s.Content}
+}
+
+func stepListStyle(s fizzy.Step) []string {
+	if s.Completed {
+		return []string{sgrDim, sgrGreen, sgrDim}
+	}
+	return nil
+}
+
+func runStep(ctx Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, helpForStep())
+		return 2
+	}
+	if err := ensureToken(ctx); err != nil {
+		return handleErr(helpForStep(), err)
+	}
+	if err := ensureAccount(ctx); err != nil {
+		return handleErr(helpForStep(), err)
+	}
+	switch args[0] {
+	case "list":
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForStep(), err)
+		}
+		steps, resp, err := ctx.Client.Steps.List(requestContext(), number)
+		if err != nil {
+			return handleErr(helpForStep(), err)
+		}
+		return outputList(ctx, helpForStep(), steps, rawItems(resp.Body, "steps"), stepView)
+	case "add":
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForStep(), err)
+		}
+		fs := flag.NewFlagSet("step add", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		content := fs.String("content", "", "Step text")
+		completed := fs.Bool("completed", false, "Add the step already completed")
+		file := fs.String("file", "", "Markdown checklist to import, - for stdin")
+		if err := fs.Parse(args[2:]); err != nil {
+			return usageError(helpForStep(), err)
+		}
+		switch {
+		case *file != "" && *content != "":
+			return handleErr(helpForStep(), UsageError{Msg: "--content and --file cannot be used together"})
+		case *file != "":
+			steps, err := readChecklist(*file)
+			if err != nil {
+				return handleErr(helpForStep(), err)
+			}
+			return addSteps(ctx, number, steps)
+		case strings.TrimSpace(*content) == "":
+			return handleErr(helpForStep(), UsageError{Msg: "--content or --file is required"})
+		}
+		req := &fizzy.StepRequest{Content: strings.TrimSpace(*content)}
+		if *completed {
+			req.Completed = completedState(true)
+		}
+		resp, err := ctx.Client.Steps.Create(requestContext(), number, req)
+		if err != nil {
+			return handleErr(helpForStep(), err)
+		}
+		return outputLocation(ctx, resp, "Step added")
+	case "update":
+		if len(args) < 3 {
+			return handleErr(helpForStep(), UsageError{Msg: "card number and step id are required"})
+		}
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForStep(), err)
+		}
+		fs := flag.NewFlagSet("step update", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		content := fs.String("content", "", "Step text")
+		if err := fs.Parse(args[3:]); err != nil {
+			return usageError(helpForStep(), err)
+		}
+		if strings.TrimSpace(*content) == "" {
+			return handleErr(helpForStep(), UsageError{Msg: "--content is required"})
+		}
+		resp, err := ctx.Client.Steps.Update(requestContext(), number, args[2], &fizzy.StepRequest{Content: strings.TrimSpace(*content)})
+		if err != nil {
+			return handleErr(helpForStep(), err)
+		}
+		return outputNoContent(ctx, resp, "Step updated")
+	case "complete", "uncomplete":
+		if len(args) < 3 {
+			return handleErr(helpForStep(), UsageError{Msg: "card number and step id are required"})
+		}
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForStep(), err)
+		}
+		done := args[0] == "complete"
+		resp, err := ctx.Client.Steps.Update(requestContext(), number, args[2], &fizzy.StepRequest{Completed: completedState(done)})
+		if err != nil {
+			return handleErr(helpForStep(), err)
+		}
+		if done {
+			return outputNoContent(ctx, resp, "Step completed")
+		}
+		return outputNoContent(ctx, resp, "Step marked incomplete")
+	case "delete":
+		if len(args) < 3 {
+			return handleErr(helpForStep(), UsageError{Msg: "card number and step id are required"})
+		}
+		number, err := cardNumberArg(args)
+		if err != nil {
+			return handleErr(helpForStep(), err)
+		}
+		resp, err := ctx.Client.Steps.Delete(requestContext(), number, args[2])
+		if err != nil {
+			return handleErr(helpForStep(), err)
+		}
+		return outputNoContent(ctx, resp, "Step deleted")
+	default:
+		fmt.Fprint(os.Stderr, helpForStep())
+		return 2
+	}
+}
+
+// addSteps creates steps in order, stopping at the first failure.
+func addSteps(ctx Context, number int, steps []fizzy.StepRequest) int {
+	if err := createSteps(ctx, number, steps); err != nil {
+		return handleErr(helpForStep(), err)
+	}
+	if ctx.Output.Structured() {
+		return outputPayload(ctx, map[string]any{"card": number, "steps_added": len(steps)})
+	}
+	fmt.Fprintf(os.Stdout, "Added %d steps to card %d.\n", len(steps), number)
+	return 0
+}
+
+func createSteps(ctx Context, number int, steps []fizzy.StepRequest) error {
+	for i := range steps {
+		if _, err := ctx.Client.Steps.Create(requestContext(), number, &steps[i]); err != nil {
+			return fmt.Errorf("step %d of %d (%q): %w", i+1, len(steps), steps[i].Content, err)
+		}
+	}
+	return nil
+}
+
+func completedState(done bool) *bool {
+	return &done
+}
+
+// readChecklist reads a Markdown checklist from path, or stdin for "-".
+func readChecklist(path string) ([]fizzy.StepRequest, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	steps, err := parseChecklist(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return nil, UsageError{Msg: fmt.Sprintf("no checklist items found in %s", checklistSource(path))}
+	}
+	return steps, nil
+}
+
+func checklistSource(path string) string {
+	if path == "-" {
+		return "stdin"
+	}
+	return path
+}
+
+var checklistItem = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(?:\[([ xX])\]\s*)?(.*)$`)
+
+// parseChecklist turns Markdown list items into steps. "- [x] text" is a
+// completed step, "- [ ] text" and plain "- text" items are open ones.
+// Headings, blank lines and other text are skipped.
+func parseChecklist(r io.Reader) ([]fizzy.StepRequest, error) {
+	var steps []fizzy.StepRequest
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m := checklistItem.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		content := strings.TrimSpace(m[2])
+		if content == "" {
+			continue
+		}
+		step := fizzy.StepRequest{Content: content}
+		if m[1] == "x" || m[1] == "X" {
+			step.Completed = completedState(true)
+		}
+		steps = append(steps, step)
+	}
+	return steps, scanner.Err()
+}
+
+// cardNumberFromLocation extracts the card number from the Location header
+// returned when a card is created, e.g. /897362094/cards/42.
+func cardNumberFromLocation(location string) (int, bool) {
+	if location == "" {
+		return 0, false
+	}
+	base := strings.TrimSuffix(path.Base(strings.TrimRight(location, "/")), ".json")
+	n, err := strconv.Atoi(base)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
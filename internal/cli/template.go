@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var templateFuncs = template.FuncMap{
+	"join":     templateJoin,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"trim":     strings.TrimSpace,
+	"truncate": templateTruncate,
+	"pad":      templatePad,
+	"default":  templateDefault,
+	"json":     templateJSON,
+	"date":     templateDate,
+}
+
+func parseOutputTemplate(text string) (*template.Template, error) {
+	text = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(text)
+	tmpl, err := template.New("format").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// templateJoin joins any slice, e.g. {{join .Tags ", "}}.
+func templateJoin(items any, sep string) string {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Sprint(items)
+	}
+	parts := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		parts = append(parts, fmt.Sprint(v.Index(i).Interface()))
+	}
+	return strings.Join(parts, sep)
+}
+
+// templateTruncate shortens s to n characters: {{.Title | truncate 20}}.
+func templateTruncate(n int, s string) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// templatePad left-aligns s in a column of n characters.
+func templatePad(n int, s string) string {
+	if pad := n - len([]rune(s)); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+func templateDefault(fallback string, v any) any {
+	if v == nil {
+		return fallback
+	}
+	rv := reflect.ValueOf(v)
+	if rv.IsZero() {
+		return fallback
+	}
+	return v
+}
+
+func templateJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// templateDate reformats an RFC 3339 timestamp: {{.CreatedAt | date "2006-01-02"}}.
+func templateDate(layout, value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutputTemplate(t *testing.T) {
+	type board struct{ Name string }
+	item := struct {
+		Number    int
+		Title     string
+		Tags      []string
+		Board     board
+		Column    *board
+		CreatedAt string
+	}{7, "Add dark mode", []string{"feature", "design"}, board{"Roadmap"}, nil, "2026-10-17T12:30:00Z"}
+
+	tests := []struct {
+		text string
+		want string
+		err  string
+	}{
+		{text: `{{.Number}}\t{{.Title}}`, want: "7\tAdd dark mode"},
+		{text: `{{join .Tags ", "}}`, want: "feature, design"},
+		{text: `{{.Board.Name | upper}} {{.Board.Name | lower}}`, want: "ROADMAP roadmap"},
+		{text: `{{.Title | truncate 8}}|{{.Title | truncate 1}}|{{.Title | truncate 0}}`, want: "Add dar…|…|Add dark mode"},
+		{text: `[{{pad 8 .Board.Name}}]`, want: "[Roadmap ]"},
+		{text: `{{default "none" .Column}} {{default "none" .Title}}`, want: "none Add dark mode"},
+		{text: `{{json .Tags}}`, want: `["feature","design"]`},
+		{text: `{{.CreatedAt | date "2006-01-02"}} {{"soon" | date "2006"}}`, want: "2026-10-17 soon"},
+		{text: `{{trim "  x  "}}\n`, want: "x\n"},
+		{text: `{{.Title`, err: "invalid --format template: "},
+		{text: `{{nope .Title}}`, err: `invalid --format template: template: format:1: function "nope" not defined`},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseOutputTemplate(tt.text)
+		if tt.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+				t.Errorf("%s: error = %v, want %q", tt.text, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.text, err)
+			continue
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, item); err != nil {
+			t.Errorf("%s: %v", tt.text, err)
+			continue
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s = %q, want %q", tt.text, b.String(), tt.want)
+		}
+	}
+}
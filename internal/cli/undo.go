@@ -0,0 +1,640 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fizzy-cli/internal/journal"
+	"fizzy-cli/pkg/fizzy"
+)
+
+func journalStore(ctx Context) *journal.Store {
+	return journal.New(journal.DefaultDir(ctx.ConfigPath))
+}
+
+// journalChange records a change that has been made. A journal that can't
+// be written doesn't fail the command; the change already happened.
+func journalChange(ctx Context, e journal.Entry) {
+	e.BaseURL, e.Account = ctx.BaseURL, ctx.Account
+	if err := journalStore(ctx).Add(&e); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not write the undo journal: %v\n", err)
+	}
+}
+
+func snapshotCard(ctx Context, number int) (json.RawMessage, error) {
+	_, resp, err := ctx.Client.Cards.Get(requestContext(), number)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func snapshotComment(ctx Context, number int, id string) (json.RawMessage, error) {
+	_, resp, err := ctx.Client.Comments.Get(requestContext(), number, id)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// cardChange runs a card action such as close and journals it.
+func cardChange(ctx Context, args []string, name string, action func(context.Context, int) (*fizzy.Response, error), message string) int {
+	number, err := cardNumberArg(args)
+	if err != nil {
+		return handleErr(helpForCard(), err)
+	}
+	before, err := snapshotCard(ctx, number)
+	if err != nil {
+		return handleErr(helpForCard(), err)
+	}
+	resp, err := action(requestContext(), number)
+	if err != nil {
+		return handleErr(helpForCard(), err)
+	}
+	journalChange(ctx, journal.Entry{Action: name, Card: number, Before: before})
+	return outputNoContent(ctx, resp, message)
+}
+
+// updatedFields names what a card update request changes.
+func updatedFields(req *fizzy.CardRequest) []string {
+	var fields []string
+	if req.Title != "" {
+		fields = append(fields, "title")
+	}
+	if req.Description != nil {
+		fields = append(fields, "description")
+	}
+	if req.Status != "" {
+		fields = append(fields, "status")
+	}
+	if len(req.TagIDs) > 0 {
+		fields = append(fields, "tags")
+	}
+	if req.ImagePath != "" {
+		fields = append(fields, "image")
+	}
+	return fields
+}
+
+func runHistory(ctx Context, args []string) int {
+	if len(args) > 0 && args[0] == "show" {
+		if len(args) < 2 {
+			return handleErr(helpForHistory(), UsageError{Msg: "history entry id is required"})
+		}
+		entry, err := journalEntry(ctx, args[1])
+		if err != nil {
+			return handleErr(helpForHistory(), err)
+		}
+		raw, err := json.Marshal(entry)
+		if err != nil {
+			return handleErr(helpForHistory(), err)
+		}
+		return outputItem(ctx, &fizzy.Response{Body: raw}, entry, historyView)
+	}
+	if len(args) > 0 && args[0] != "list" && !strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(os.Stderr, helpForHistory())
+		return 2
+	}
+	if len(args) > 0 && args[0] == "list" {
+		args = args[1:]
+	}
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	limit := fs.Int("limit", 20, "Entries to show, 0 for all")
+	if err := fs.Parse(args); err != nil {
+		return usageError(helpForHistory(), err)
+	}
+	entries, err := accountEntries(ctx)
+	if err != nil {
+		return handleErr(helpForHistory(), err)
+	}
+	// Newest first.
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if *limit > 0 && len(entries) > *limit {
+		entries = entries[:*limit]
+	}
+	raws := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		raw, err := json.Marshal(e)
+		if err != nil {
+			return handleErr(helpForHistory(), err)
+		}
+		raws = append(raws, raw)
+	}
+	return outputList(ctx, helpForHistory(), entries, raws, historyView)
+}
+
+func runUndo(ctx Context, args []string) int {
+	if err := ensureToken(ctx); err != nil {
+		return handleErr(helpForUndo(), err)
+	}
+	if err := ensureAccount(ctx); err != nil {
+		return handleErr(helpForUndo(), err)
+	}
+	var entry *journal.Entry
+	if len(args) > 0 {
+		e, err := journalEntry(ctx, args[0])
+		if err != nil {
+			return handleErr(helpForUndo(), err)
+		}
+		if e.UndoneAt != nil {
+			return handleErr(helpForUndo(), fmt.Errorf("history entry %d was already undone", e.ID))
+		}
+		entry = e
+	} else {
+		entries, err := accountEntries(ctx)
+		if err != nil {
+			return handleErr(helpForUndo(), err)
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].UndoneAt == nil {
+				entry = &entries[i]
+				break
+			}
+		}
+		if entry == nil {
+			return handleErr(helpForUndo(), errors.New("nothing to undo"))
+		}
+	}
+
+	restored, lost, err := undoEntry(ctx, *entry)
+	var nothing errNothingToUndo
+	if err != nil && !errors.As(err, &nothing) {
+		return handleErr(helpForUndo(), fmt.Errorf("undo %d (%s): %w", entry.ID, entry.Action, err))
+	}
+	// An entry with nothing to undo is marked undone too, so that the next
+	// undo moves on to the change before it.
+	now := time.Now()
+	entry.UndoneAt = &now
+	entry.UndoNotes = nil
+	if nothing.reason != "" {
+		entry.UndoNotes = append(entry.UndoNotes, nothing.Error())
+	}
+	for _, r := range restored {
+		entry.UndoNotes = append(entry.UndoNotes, "restored: "+r)
+	}
+	for _, l := range lost {
+		entry.UndoNotes = append(entry.UndoNotes, "not restored: "+l)
+	}
+	if err := journalStore(ctx).Save(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not update the undo journal: %v\n", err)
+	}
+
+	if ctx.Output.Structured() {
+		payload := map[string]any{"id": entry.ID, "action": entry.Action, "card": entry.Card, "restored": nonNil(restored), "not_restored": nonNil(lost)}
+		if nothing.reason != "" {
+			payload["nothing_to_undo"] = nothing.reason
+		}
+		return outputPayload(ctx, payload)
+	}
+	if nothing.reason != "" {
+		fmt.Fprintf(os.Stdout, "Nothing to undo for %d: %s; %s.\n", entry.ID, describeEntry(*entry), nothing.reason)
+		return 0
+	}
+	fmt.Fprintf(os.Stdout, "Undid %d: %s.\n", entry.ID, describeEntry(*entry))
+	for _, note := range entry.UndoNotes {
+		fmt.Fprintf(os.Stdout, "  %s\n", note)
+	}
+	return 0
+}
+
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// accountEntries returns the journal entries made against the current
+// account, oldest first.
+func accountEntries(ctx Context) ([]journal.Entry, error) {
+	all, err := journalStore(ctx).List()
+	if err != nil {
+		return nil, err
+	}
+	var entries []journal.Entry
+	for _, e := range all {
+		if e.Account == ctx.Account && e.BaseURL == ctx.BaseURL {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func journalEntry(ctx Context, value string) (*journal.Entry, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(value), "#"))
+	if err != nil || id <= 0 {
+		return nil, UsageError{Msg: fmt.Sprintf("invalid history entry id %q", value)}
+	}
+	e, err := journalStore(ctx).Get(id)
+	if errors.Is(err, journal.ErrNotFound) {
+		return nil, fmt.Errorf("no history entry %d", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if e.Account != ctx.Account || e.BaseURL != ctx.BaseURL {
+		return nil, fmt.Errorf("history entry %d was made on account %s at %s", id, e.Account, e.BaseURL)
+	}
+	return e, nil
+}
+
+// undoEntry reverses one change as far as the API allows. It returns what
+// was restored and what was not; err is set only when nothing could be
+// done.
+func undoEntry(ctx Context, e journal.Entry) ([]string, []string, error) {
+	reqCtx := requestContext()
+	switch e.Action {
+	case "card update":
+		var before fizzy.Card
+		if err := json.Unmarshal(e.Before, &before); err != nil {
+			return nil, nil, err
+		}
+		var restored, lost []string
+		req := &fizzy.CardRequest{}
+		for _, field := range e.Fields {
+			switch field {
+			case "title":
+				if before.Title == "" {
+					lost = append(lost, "title: the previous title is not known")
+					continue
+				}
+				req.Title = before.Title
+			case "description":
+				req.Description = &before.Description
+			case "status":
+				if before.Status == "" {
+					lost = append(lost, "status: the previous status is not known")
+					continue
+				}
+				req.Status = before.Status
+			case "image":
+				lost = append(lost, "image: the previous image is not kept")
+				continue
+			case "tags":
+				continue
+			}
+			restored = append(restored, field)
+		}
+		if req.Title != "" || req.Description != nil || req.Status != "" {
+			if _, err := ctx.Client.Cards.Update(reqCtx, e.Card, req); err != nil {
+				return nil, nil, err
+			}
+		}
+		if contains(e.Fields, "tags") {
+			current, _, err := ctx.Client.Cards.Get(reqCtx, e.Card)
+			if err != nil {
+				return restored, append(lost, "tags: "+err.Error()), nil
+			}
+			for _, title := range symmetricDifference(before.Tags, current.Tags) {
+				if _, err := ctx.Client.Cards.ToggleTag(reqCtx, e.Card, title); err != nil {
+					lost = append(lost, fmt.Sprintf("tag %s: %v", title, err))
+					continue
+				}
+				restored = append(restored, "tag "+title)
+			}
+		}
+		if len(restored) == 0 {
+			return nil, lost, errors.New(strings.Join(lost, "; "))
+		}
+		return restored, lost, nil
+	case "card close", "card reopen", "card tag", "card assign":
+		return undoCardToggle(ctx, e)
+	case "card delete":
+		return undoCardDelete(ctx, e)
+	case "comment update":
+		var before fizzy.Comment
+		if err := json.Unmarshal(e.Before, &before); err != nil {
+			return nil, nil, err
+		}
+		if _, err := ctx.Client.Comments.Update(reqCtx, e.Card, e.Target, &fizzy.CommentRequest{Body: before.Body.Plain}); err != nil {
+			return nil, nil, err
+		}
+		var lost []string
+		if richText(before.Body.HTML) {
+			lost = append(lost, "formatting: the comment was restored as plain text")
+		}
+		return []string{"comment text"}, lost, nil
+	case "comment delete":
+		var before fizzy.Comment
+		if err := json.Unmarshal(e.Before, &before); err != nil {
+			return nil, nil, err
+		}
+		resp, err := ctx.Client.Comments.Create(reqCtx, e.Card, &fizzy.CommentRequest{Body: before.Body.Plain})
+		if err != nil {
+			return nil, nil, err
+		}
+		restored := []string{"comment text, as a new comment"}
+		if location := resp.Headers.Get("Location"); location != "" {
+			restored[0] += " at " + location
+		}
+		lost := []string{fmt.Sprintf("author and date: it is now a comment by you, not by %s on %s", firstNonEmpty(before.Creator.Name, "its author"), templateDate("2006-01-02", before.CreatedAt))}
+		if richText(before.Body.HTML) {
+			lost = append(lost, "formatting: the comment was restored as plain text")
+		}
+		return restored, lost, nil
+	}
+	return nil, nil, fmt.Errorf("%s cannot be undone", e.Action)
+}
+
+// errNothingToUndo reports an entry whose change is not in effect: the
+// command changed nothing, or the card was changed back since.
+type errNothingToUndo struct{ reason string }
+
+func (e errNothingToUndo) Error() string { return "nothing to undo: " + e.reason }
+
+// undoCardToggle reverses a close, reopen, tag or assignment. The actions
+// toggle, so it first compares the card before the change with the card
+// now, and leaves the card alone unless the change is still in effect.
+func undoCardToggle(ctx Context, e journal.Entry) ([]string, []string, error) {
+	reqCtx := requestContext()
+	var before fizzy.Card
+	if err := json.Unmarshal(e.Before, &before); err != nil {
+		return nil, nil, err
+	}
+	current, _, err := ctx.Client.Cards.Get(reqCtx, e.Card)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch e.Action {
+	case "card close", "card reopen":
+		closed := e.Action == "card close"
+		switch {
+		case before.Closed == closed:
+			return nil, nil, errNothingToUndo{"the card was " + closedWord(closed) + " already"}
+		case current.Closed != closed:
+			return nil, nil, errNothingToUndo{"the card is " + closedWord(current.Closed) + " again"}
+		case closed:
+			_, err = ctx.Client.Cards.Reopen(reqCtx, e.Card)
+			return undone("reopened card", err)
+		default:
+			_, err = ctx.Client.Cards.Close(reqCtx, e.Card)
+			return undone("closed card again", err)
+		}
+	case "card tag":
+		title := tagTitle(e.Target)
+		had := hasTag(before.Tags, title)
+		if hasTag(current.Tags, title) == had {
+			return nil, nil, errNothingToUndo{fmt.Sprintf("the card %s tag %s, as before", hasWord(had), title)}
+		}
+		_, err = ctx.Client.Cards.ToggleTag(reqCtx, e.Card, title)
+		if had {
+			return undone("added tag "+title+" back", err)
+		}
+		return undone("removed tag "+title, err)
+	default:
+		wasAssigned := assigned(&before, e.Target)
+		isAssigned := assigned(current, e.Target)
+		name := assigneeName(e.Target, &before, current)
+		if isAssigned == wasAssigned {
+			if wasAssigned {
+				return nil, nil, errNothingToUndo{fmt.Sprintf("the card is assigned to %s, as before", name)}
+			}
+			return nil, nil, errNothingToUndo{fmt.Sprintf("the card is not assigned to %s, as before", name)}
+		}
+		_, err = ctx.Client.Cards.ToggleAssignment(reqCtx, e.Card, e.Target)
+		if wasAssigned {
+			return undone("assigned "+name+" again", err)
+		}
+		return undone("unassigned "+name, err)
+	}
+}
+
+func undone(what string, err error) ([]string, []string, error) {
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{what}, nil, nil
+}
+
+func closedWord(closed bool) string {
+	if closed {
+		return "closed"
+	}
+	return "open"
+}
+
+func hasWord(has bool) string {
+	if has {
+		return "has"
+	}
+	return "does not have"
+}
+
+// assigneeName finds the name of the user with the given ID among the
+// cards' assignees, falling back to the ID.
+func assigneeName(id string, cards ...*fizzy.Card) string {
+	for _, card := range cards {
+		for _, user := range card.Assignees {
+			if user.ID == id && user.Name != "" {
+				return user.Name
+			}
+		}
+	}
+	return id
+}
+
+func undoCardDelete(ctx Context, e journal.Entry) ([]string, []string, error) {
+	reqCtx := requestContext()
+	var before fizzy.Card
+	if err := json.Unmarshal(e.Before, &before); err != nil {
+		return nil, nil, err
+	}
+	resp, err := ctx.Client.Cards.Create(reqCtx, before.Board.ID, &fizzy.CardRequest{Title: before.Title, Description: optionalText(before.Description), Status: before.Status})
+	if err != nil {
+		return nil, nil, err
+	}
+	number, ok := cardNumberFromLocation(resp.Headers.Get("Location"))
+	if !ok {
+		return []string{"title and description, on a new card"}, []string{"everything else: the new card's number is unknown"}, nil
+	}
+	restored := []string{fmt.Sprintf("card as #%d on %s, with its title and description", number, before.Board.Name)}
+	lost := []string{fmt.Sprintf("card number: it was #%d", before.Number), "creator and dates"}
+	note := func(what string, err error) {
+		if err != nil {
+			lost = append(lost, fmt.Sprintf("%s: %v", what, err))
+		} else {
+			restored = append(restored, what)
+		}
+	}
+	for _, title := range before.Tags {
+		_, err := ctx.Client.Cards.ToggleTag(reqCtx, number, title)
+		note("tag "+title, err)
+	}
+	for _, user := range before.Assignees {
+		_, err := ctx.Client.Cards.ToggleAssignment(reqCtx, number, user.ID)
+		note("assignee "+firstNonEmpty(user.Name, user.ID), err)
+	}
+	for _, step := range before.Steps {
+		_, err := ctx.Client.Steps.Create(reqCtx, number, &fizzy.StepRequest{Content: step.Content, Completed: completedState(step.Completed)})
+		note(fmt.Sprintf("step %q", step.Content), err)
+	}
+	if before.Column != nil {
+		_, err := ctx.Client.Cards.Triage(reqCtx, number, before.Column.ID)
+		note("column "+before.Column.Name, err)
+	}
+	if before.Closed {
+		_, err := ctx.Client.Cards.Close(reqCtx, number)
+		note("closed state", err)
+	}
+	var comments []fizzy.Comment
+	if len(e.Comments) > 0 {
+		if err := json.Unmarshal(e.Comments, &comments); err != nil {
+			lost = append(lost, "comments: "+err.Error())
+		}
+	}
+	// Oldest first, so that they read in the original order.
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].CreatedAt < comments[j].CreatedAt })
+	recreated := 0
+	for _, comment := range comments {
+		if _, err := ctx.Client.Comments.Create(reqCtx, number, &fizzy.CommentRequest{Body: comment.Body.Plain}); err != nil {
+			lost = append(lost, fmt.Sprintf("comment by %s: %v", firstNonEmpty(comment.Creator.Name, "its author"), err))
+			continue
+		}
+		recreated++
+	}
+	if recreated > 0 {
+		restored = append(restored, plural(recreated, "comment")+" as plain text")
+		lost = append(lost, fmt.Sprintf("comment authors and dates: the comments are now yours; the originals are kept in 'fizzy-cli history show %d'", e.ID))
+	}
+	return restored, lost, nil
+}
+
+// symmetricDifference returns the items in exactly one of a and b.
+func symmetricDifference(a, b []string) []string {
+	var out []string
+	for _, v := range a {
+		if !contains(b, v) {
+			out = append(out, v)
+		}
+	}
+	for _, v := range b {
+		if !contains(a, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+var richTags = regexp.MustCompile(`<(?:[a-oq-z]|/[a-oq-z]|p[a-z])`)
+
+// richText reports whether comment HTML has formatting beyond paragraphs
+// and line breaks, which a plain-text restore loses.
+func richText(html string) bool {
+	return richTags.MatchString(strings.ReplaceAll(strings.ReplaceAll(html, "<br>", ""), "<br/>", ""))
+}
+
+func describeEntry(e journal.Entry) string {
+	var b strings.Builder
+	b.WriteString(e.Action)
+	if e.Card > 0 {
+		fmt.Fprintf(&b, " #%d", e.Card)
+	}
+	switch e.Action {
+	case "card update":
+		b.WriteString(" (" + strings.Join(e.Fields, ", ") + ")")
+	case "card tag":
+		b.WriteString(" " + e.Target)
+	}
+	if title := entryTitle(e); title != "" {
+		fmt.Fprintf(&b, " %q", title)
+	}
+	return b.String()
+}
+
+// entryTitle is the card title or comment excerpt from the snapshot.
+func entryTitle(e journal.Entry) string {
+	var doc struct {
+		Title string `json:"title"`
+		Body  struct {
+			Plain string `json:"plain_text"`
+		} `json:"body"`
+	}
+	if json.Unmarshal(e.Before, &doc) != nil {
+		return ""
+	}
+	if doc.Title != "" {
+		return doc.Title
+	}
+	return excerpt(doc.Body.Plain, 40)
+}
+
+func excerpt(s string, width int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > width {
+		return string(r[:width-1]) + "…"
+	}
+	return s
+}
+
+var historyView = view[journal.Entry]{headers: []string{"ID", "TIME", "ACTION", "CARD", "DETAIL", "UNDONE"}, row: historyRow, detail: historyDetail}
+
+func historyRow(e journal.Entry) []string {
+	card := ""
+	if e.Card > 0 {
+		card = "#" + strconv.Itoa(e.Card)
+	}
+	detail := entryTitle(e)
+	switch e.Action {
+	case "card update":
+		detail = strings.Join(e.Fields, ", ") + ": " + detail
+	case "card tag":
+		detail = "tag " + e.Target
+	case "card assign":
+		detail = "user " + e.Target
+	}
+	undone := ""
+	if e.UndoneAt != nil {
+		undone = e.UndoneAt.Local().Format("2006-01-02 15:04")
+	}
+	return []string{strconv.Itoa(e.ID), e.Time.Local().Format("2006-01-02 15:04"), e.Action, card, detail, undone}
+}
+
+func historyDetail(e *journal.Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d: %s\n", e.ID, describeEntry(*e))
+	fmt.Fprintf(&b, "Time:    %s\n", e.Time.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "Account: %s (%s)\n", e.Account, e.BaseURL)
+	if e.UndoneAt != nil {
+		fmt.Fprintf(&b, "Undone:  %s\n", e.UndoneAt.Local().Format("2006-01-02 15:04:05"))
+		for _, note := range e.UndoNotes {
+			fmt.Fprintf(&b, "  %s\n", note)
+		}
+	}
+	for _, part := range []struct {
+		label string
+		raw   json.RawMessage
+	}{{"Before", e.Before}, {"Comments", e.Comments}} {
+		if len(part.raw) == 0 {
+			continue
+		}
+		var doc any
+		if json.Unmarshal(part.raw, &doc) != nil {
+			continue
+		}
+		pretty, _ := json.MarshalIndent(doc, "", "  ")
+		fmt.Fprintf(&b, "\n%s:\n%s\n", part.label, pretty)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,450 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// yamlNode is a JSON value that remembers the order of object keys, so YAML
+// output lists fields in the same order as the API response.
+type yamlNode struct {
+	scalar string
+	keys   []string
+	values []*yamlNode
+	items  []*yamlNode
+	object bool
+	array  bool
+}
+
+func parseYAMLNode(raw []byte) (*yamlNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	node, err := decodeYAMLNode(dec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON response: %w", err)
+	}
+	return node, nil
+}
+
+func decodeYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			node := &yamlNode{object: true}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := keyTok.(string)
+				if !ok {
+					return nil, errors.New("object key is not a string")
+				}
+				value, err := decodeYAMLNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key)
+				node.values = append(node.values, value)
+			}
+			_, err := dec.Token()
+			return node, err
+		case '[':
+			node := &yamlNode{array: true}
+			for dec.More() {
+				item, err := decodeYAMLNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				node.items = append(node.items, item)
+			}
+			_, err := dec.Token()
+			return node, err
+		}
+		return nil, fmt.Errorf("unexpected %v", t)
+	case nil:
+		return &yamlNode{scalar: "null"}, nil
+	case bool:
+		return &yamlNode{scalar: fmt.Sprint(t)}, nil
+	case json.Number:
+		return &yamlNode{scalar: t.String()}, nil
+	case string:
+		return &yamlNode{scalar: yamlString(t)}, nil
+	}
+	return nil, fmt.Errorf("unexpected token %v", tok)
+}
+
+// inline reports whether the node fits on the same line as its key.
+func (n *yamlNode) inline() bool {
+	return (!n.object && !n.array) || (n.object && len(n.keys) == 0) || (n.array && len(n.items) == 0)
+}
+
+func (n *yamlNode) inlineValue() string {
+	switch {
+	case n.object:
+		return "{}"
+	case n.array:
+		return "[]"
+	}
+	return n.scalar
+}
+
+// lines renders the node as a YAML block without base indentation.
+func (n *yamlNode) lines() []string {
+	if n.inline() {
+		return []string{n.inlineValue()}
+	}
+	var out []string
+	if n.object {
+		for i, key := range n.keys {
+			value := n.values[i]
+			if value.inline() {
+				out = append(out, yamlString(key)+": "+value.inlineValue())
+				continue
+			}
+			out = append(out, yamlString(key)+":")
+			for _, line := range value.lines() {
+				out = append(out, "  "+line)
+			}
+		}
+		return out
+	}
+	for _, item := range n.items {
+		for i, line := range item.lines() {
+			if i == 0 {
+				out = append(out, "- "+line)
+			} else {
+				out = append(out, "  "+line)
+			}
+		}
+	}
+	return out
+}
+
+func writeYAMLLines(w io.Writer, lines []string) error {
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// yamlString quotes s when YAML would otherwise read it as something other
+// than the plain string, using JSON escapes, which YAML accepts in
+// double-quoted scalars.
+func yamlString(s string) string {
+	if !yamlNeedsQuotes(s) {
+		return s
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	if _, err := json.Number(s).Float64(); err == nil {
+		return true
+	}
+	if yamlTimestamp(s) {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlTimestamp reports whether s starts like a YYYY-MM-DD date, which YAML
+// parsers would otherwise read as a timestamp.
+func yamlTimestamp(s string) bool {
+	if len(s) < 10 || s[4] != '-' || s[7] != '-' {
+		return false
+	}
+	for _, i := range []int{0, 1, 2, 3, 5, 6, 8, 9} {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+type yamlMapping struct {
+	line   int
+	fields map[string]any
+}
+
+// parseYAMLList reads the YAML subset that batch scripts use: a top-level
+// list of flat mappings whose values are scalars, [a, b] flow lists, block
+// lists, or | and > block scalars. Nested mappings, anchors and tags are
+// not supported.
+func parseYAMLList(data []byte) ([]yamlMapping, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	var out []yamlMapping
+	keyIndent := -1
+	for i := 0; i < len(lines); i++ {
+		line := i + 1
+		text := stripYAMLComment(lines[i])
+		body := strings.TrimSpace(text)
+		if body == "" || body == "---" {
+			continue
+		}
+		if strings.Contains(text[:len(text)-len(strings.TrimLeft(text, " \t"))], "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in indentation", line)
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		if indent == 0 && (body == "-" || strings.HasPrefix(body, "- ")) {
+			out = append(out, yamlMapping{line: line, fields: map[string]any{}})
+			rest := strings.TrimLeft(body[1:], " ")
+			keyIndent = len(body) - len(rest)
+			if rest == "" {
+				// The keys start on the next line, at its indentation.
+				keyIndent = -1
+				continue
+			}
+			body = rest
+		} else if len(out) > 0 && keyIndent < 0 && indent > 0 {
+			keyIndent = indent
+		} else if len(out) == 0 || indent != keyIndent {
+			return nil, fmt.Errorf("line %d: expected a list of mappings, each item starting with \"- \"", line)
+		}
+		key, value, ok := strings.Cut(body, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || (value != "" && value[0] != ' ') {
+			return nil, fmt.Errorf("line %d: expected key: value", line)
+		}
+		fields := out[len(out)-1].fields
+		if _, dup := fields[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line, key)
+		}
+		value = strings.TrimSpace(value)
+
+		// Collect the more-indented lines that belong to this key. A block
+		// list may also sit at the key's own indentation.
+		belongs := func(next string) bool {
+			trimmed := strings.TrimLeft(next, " ")
+			n := len(next) - len(trimmed)
+			return strings.TrimSpace(next) == "" || n > keyIndent || (n == keyIndent && value == "" && strings.HasPrefix(trimmed, "-"))
+		}
+		var block []string
+		for i+1 < len(lines) && belongs(lines[i+1]) {
+			block = append(block, lines[i+1])
+			i++
+		}
+
+		switch {
+		case value == "|" || value == "|-" || value == ">" || value == ">-":
+			fields[key] = yamlBlockScalar(block, value)
+		case value == "":
+			items, err := yamlBlockList(block, line)
+			if err != nil {
+				return nil, err
+			}
+			if items == nil {
+				fields[key] = nil
+			} else {
+				fields[key] = items
+			}
+		case strings.HasPrefix(value, "["):
+			if strings.TrimSpace(strings.Join(block, "")) != "" {
+				return nil, fmt.Errorf("line %d: flow lists must fit on one line", line)
+			}
+			items, err := yamlFlowList(value, line)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = items
+		default:
+			if strings.TrimSpace(strings.Join(block, "")) != "" {
+				return nil, fmt.Errorf("line %d: use | for values that span lines", line)
+			}
+			scalar, err := yamlScalar(value, line)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = scalar
+		}
+	}
+	return out, nil
+}
+
+// stripYAMLComment removes a # comment that starts a line or follows a
+// space, outside quotes.
+func stripYAMLComment(s string) string {
+	var quote rune
+	escaped := false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			switch {
+			case r == '\\' && quote == '"':
+				escaped = true
+			case r == '\'' && quote == '\'' && i+1 < len(s) && s[i+1] == '\'':
+				// '' is a quote inside a single-quoted string.
+				escaped = true
+			case r == quote:
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			if i == 0 || strings.ContainsRune(" [,:", rune(s[i-1])) {
+				quote = r
+			}
+		case r == '#' && (i == 0 || s[i-1] == ' '):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func yamlBlockScalar(block []string, style string) string {
+	indent := -1
+	for _, l := range block {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if n := len(l) - len(strings.TrimLeft(l, " ")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	text := make([]string, len(block))
+	for i, l := range block {
+		if len(l) >= indent && indent >= 0 {
+			l = l[indent:]
+		}
+		text[i] = strings.TrimRight(l, " ")
+	}
+	s := strings.TrimRight(strings.Join(text, "\n"), "\n")
+	if strings.HasPrefix(style, ">") {
+		var b strings.Builder
+		for i, l := range strings.Split(s, "\n") {
+			switch {
+			case i == 0:
+			case l == "":
+				b.WriteString("\n")
+				continue
+			case !strings.HasSuffix(b.String(), "\n"):
+				b.WriteString(" ")
+			}
+			b.WriteString(l)
+		}
+		s = b.String()
+	}
+	if !strings.HasSuffix(style, "-") && s != "" {
+		s += "\n"
+	}
+	return s
+}
+
+func yamlBlockList(block []string, line int) ([]any, error) {
+	var items []any
+	for i, l := range block {
+		body := strings.TrimSpace(stripYAMLComment(l))
+		if body == "" {
+			continue
+		}
+		if body != "-" && !strings.HasPrefix(body, "- ") {
+			return nil, fmt.Errorf("line %d: expected a list item starting with \"- \"", line+i+1)
+		}
+		item, err := yamlScalar(strings.TrimSpace(body[1:]), line+i+1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func yamlFlowList(value string, line int) ([]any, error) {
+	if !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("line %d: unterminated list", line)
+	}
+	inner := strings.TrimSpace(value[1 : len(value)-1])
+	items := []any{}
+	if inner == "" {
+		return items, nil
+	}
+	var parts []string
+	var quote rune
+	start := 0
+	for i, r := range inner {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			parts = append(parts, inner[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, inner[start:])
+	for _, part := range parts {
+		item, err := yamlScalar(strings.TrimSpace(part), line)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// yamlScalar reads a quoted or plain scalar. Plain true, false, null and
+// integers keep their type; everything else is a string.
+func yamlScalar(s string, line int) (any, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid double-quoted string %s", line, s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("line %d: invalid single-quoted string %s", line, s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	switch strings.ToLower(s) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null", "~", "":
+		return nil, nil
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return json.Number(s), nil
+	}
+	return s, nil
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLList(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string // the mappings as JSON
+		err  string
+	}{
+		{
+			name: "flat mappings",
+			in: `---
+- op: card.create
+  board: Roadmap
+  title: Hello
+-
+  op: card.close
+  card: 7
+`,
+			want: `[{"board":"Roadmap","op":"card.create","title":"Hello"},{"card":7,"op":"card.close"}]`,
+		},
+		{
+			name: "sequences",
+			in: `- op: card.create
+  tags: [bug, "a, b", 'c']
+  empty: []
+  steps:
+    - One
+    - "Two: too"
+  more:
+  - Three
+`,
+			want: `[{"empty":[],"more":["Three"],"op":"card.create","steps":["One","Two: too"],"tags":["bug","a, b","c"]}]`,
+		},
+		{
+			name: "quoted scalars",
+			in: `- double: "Say \"hi\"\tnow"
+  single: 'it''s # not a comment'
+  colon: "key: value"
+  number: "42"
+`,
+			want: `[{"colon":"key: value","double":"Say \"hi\"\tnow","number":"42","single":"it's # not a comment"}]`,
+		},
+		{
+			name: "plain scalar types",
+			in: `- yes: true
+  no: false
+  none: null
+  tilde: ~
+  blank:
+  int: 42
+  float: 4.5
+  word: on
+`,
+			want: `[{"blank":null,"float":"4.5","int":42,"no":false,"none":null,"tilde":null,"word":"on","yes":true}]`,
+		},
+		{
+			name: "block scalars",
+			in: `- literal: |
+    First line
+      indented
+
+    Last line
+  strip: |-
+    No newline
+  folded: >
+    One
+    paragraph
+
+    Two
+  folded_strip: >-
+    Joined
+    words
+`,
+			want: `[{"folded":"One paragraph\nTwo\n","folded_strip":"Joined words","literal":"First line\n  indented\n\nLast line\n","strip":"No newline"}]`,
+		},
+		{
+			name: "comments",
+			in: `# A script
+- op: comment.create # trailing
+  # between keys
+  body: a#b stays
+  tags: [x, y] # after a list
+`,
+			want: `[{"body":"a#b stays","op":"comment.create","tags":["x","y"]}]`,
+		},
+		{
+			name: "references",
+			in: `- op: comment.create
+  card: ${c1.number}
+  body: "Moved from ${b.id}"
+  steps: [${s.id}, "${t.id}"]
+`,
+			want: `[{"body":"Moved from ${b.id}","card":"${c1.number}","op":"comment.create","steps":["${s.id}","${t.id}"]}]`,
+		},
+		{name: "empty", in: "# nothing\n", want: `null`},
+		{name: "top-level mapping", in: "op: card.close\n", err: `line 1: expected a list of mappings`},
+		{name: "bad indentation", in: "- op: card.close\n card: 7\n", err: `line 2: expected a list of mappings`},
+		{name: "deeper indentation", in: "- op: card.close\n   card: 7\n", err: `line 1: use | for values that span lines`},
+		{name: "tab indentation", in: "- op: card.close\n\tcard: 7\n", err: `line 2: tabs are not allowed in indentation`},
+		{name: "nested map", in: "- op: card.close\n  card:\n    number: 7\n", err: `line 3: expected a list item starting with "- "`},
+		{name: "missing colon", in: "- op: card.close\n  card\n", err: `line 2: expected key: value`},
+		{name: "no space after colon", in: "- op:card.close\n", err: `line 1: expected key: value`},
+		{name: "duplicate key", in: "- op: card.close\n  op: card.reopen\n", err: `line 2: duplicate key "op"`},
+		{name: "multi-line plain", in: "- title: One\n    two\n", err: `line 1: use | for values that span lines`},
+		{name: "multi-line flow list", in: "- tags: [a,\n    b]\n", err: `line 1: flow lists must fit on one line`},
+		{name: "unterminated flow list", in: "- tags: [a, b\n", err: `line 1: unterminated list`},
+		{name: "bad double quotes", in: "- title: \"open\n", err: `line 1: invalid double-quoted string "open`},
+		{name: "bad single quotes", in: "- title: 'open\n", err: `line 1: invalid single-quoted string 'open`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mappings, err := parseYAMLList([]byte(tt.in))
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields []map[string]any
+			for _, m := range mappings {
+				fields = append(fields, m.fields)
+			}
+			got, err := json.Marshal(fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLListLines(t *testing.T) {
+	mappings, err := parseYAMLList([]byte("# header\n- op: a\n\n- op: b\n  steps:\n    - x\n- op: c\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, m := range mappings {
+		lines = append(lines, m.line)
+	}
+	if got, _ := json.Marshal(lines); string(got) != "[2,4,7]" {
+		t.Errorf("lines = %s, want [2,4,7]", got)
+	}
+}
@@ -5,13 +5,25 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
+const DefaultProfile = "default"
+
 type Config struct {
-	BaseURL      string `json:"base_url"`
-	Token        string `json:"token"`
-	SessionToken string `json:"session_token"`
-	Account      string `json:"account"`
+	CurrentProfile string             `json:"current_profile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty"`
+}
+
+type Profile struct {
+	BaseURL         string `json:"base_url"`
+	Token           string `json:"token"`
+	SessionToken    string `json:"session_token"`
+	Account         string `json:"account"`
+	MaxAttempts     int    `json:"max_attempts,omitempty"`
+	RetryTimeout    string `json:"retry_timeout,omitempty"`
+	CacheTTL        string `json:"cache_ttl,omitempty"`
+	CredentialStore string `json:"credential_store,omitempty"`
 }
 
 func DefaultPath() (string, error) {
@@ -22,6 +34,8 @@ func DefaultPath() (string, error) {
 	return filepath.Join(dir, "fizzy", "config.json"), nil
 }
 
+// Load reads the config file. Files written before profiles existed hold a
+// single flat profile; those are migrated into the default profile.
 func Load(path string) (Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -34,6 +48,16 @@ func Load(path string) (Config, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return Config{}, err
 	}
+	if len(cfg.Profiles) == 0 {
+		var legacy Profile
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return Config{}, err
+		}
+		if legacy != (Profile{}) {
+			cfg.Profiles = map[string]Profile{DefaultProfile: legacy}
+			cfg.CurrentProfile = DefaultProfile
+		}
+	}
 	return cfg, nil
 }
 
@@ -53,3 +77,39 @@ func Save(path string, cfg Config) error {
 	}
 	return os.Rename(tmp, path)
 }
+
+// Current returns the name of the active profile.
+func (c Config) Current() string {
+	if c.CurrentProfile == "" {
+		return DefaultProfile
+	}
+	return c.CurrentProfile
+}
+
+func (c Config) Profile(name string) (Profile, bool) {
+	p, ok := c.Profiles[name]
+	return p, ok
+}
+
+func (c *Config) SetProfile(name string, p Profile) {
+	if c.Profiles == nil {
+		c.Profiles = map[string]Profile{}
+	}
+	c.Profiles[name] = p
+}
+
+func (c *Config) RemoveProfile(name string) {
+	delete(c.Profiles, name)
+	if c.CurrentProfile == name {
+		c.CurrentProfile = ""
+	}
+}
+
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
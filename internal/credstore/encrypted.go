@@ -0,0 +1,196 @@
+package credstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	encryptedVersion = 1
+	scryptN          = 1 << 15
+	scryptR          = 8
+	scryptP          = 1
+
+	// Ceilings for the scrypt parameters read from a credentials file, so
+	// that a damaged file cannot make key derivation allocate gigabytes.
+	maxScryptN = 1 << 20
+	maxScryptR = 16
+	maxScryptP = 4
+)
+
+var ErrWrongPassphrase = errors.New("cannot decrypt credentials: wrong passphrase or corrupted file")
+
+// EncryptedStore keeps the credentials of all profiles in one file,
+// encrypted with AES-256-GCM under a key derived from a passphrase with
+// scrypt.
+type EncryptedStore struct {
+	Path       string
+	Passphrase func(confirm bool) (string, error)
+}
+
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func (s *EncryptedStore) Name() string     { return BackendEncrypted }
+func (s *EncryptedStore) Location() string { return s.Path }
+
+func (s *EncryptedStore) Get(key Key) (Credentials, error) {
+	all, _, err := s.load(false)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return all[key.Profile], nil
+}
+
+func (s *EncryptedStore) Store(key Key, creds Credentials) error {
+	all, passphrase, err := s.load(true)
+	if err != nil {
+		return err
+	}
+	all[key.Profile] = creds
+	return s.save(all, passphrase)
+}
+
+func (s *EncryptedStore) Erase(key Key) error {
+	if _, err := os.Stat(s.Path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	all, passphrase, err := s.load(false)
+	if err != nil {
+		return err
+	}
+	delete(all, key.Profile)
+	return s.save(all, passphrase)
+}
+
+// load decrypts the store. When the file does not exist yet and create is
+// set, a new passphrase is requested with confirmation.
+func (s *EncryptedStore) load(create bool) (map[string]Credentials, string, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		if !create {
+			return map[string]Credentials{}, "", nil
+		}
+		passphrase, err := s.passphrase(true)
+		if err != nil {
+			return nil, "", err
+		}
+		return map[string]Credentials{}, passphrase, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, "", fmt.Errorf("invalid credentials file %s: %w", s.Path, err)
+	}
+	if file.Version != encryptedVersion || file.KDF != "scrypt" {
+		return nil, "", fmt.Errorf("unsupported credentials file version %d (%s)", file.Version, file.KDF)
+	}
+	if file.N > maxScryptN || file.R > maxScryptR || file.P > maxScryptP {
+		return nil, "", fmt.Errorf("invalid credentials file %s: scrypt parameters N=%d, r=%d, p=%d exceed N=%d, r=%d, p=%d", s.Path, file.N, file.R, file.P, maxScryptN, maxScryptR, maxScryptP)
+	}
+	passphrase, err := s.passphrase(false)
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := scryptKey([]byte(passphrase), file.Salt, file.N, file.R, file.P, 32)
+	if err != nil {
+		return nil, "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, "", ErrWrongPassphrase
+	}
+	all := map[string]Credentials{}
+	if err := json.Unmarshal(plaintext, &all); err != nil {
+		return nil, "", err
+	}
+	return all, passphrase, nil
+}
+
+func (s *EncryptedStore) save(all map[string]Credentials, passphrase string) error {
+	plaintext, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	key, err := scryptKey([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	file := encryptedFile{
+		Version:    encryptedVersion,
+		KDF:        "scrypt",
+		N:          scryptN,
+		R:          scryptR,
+		P:          scryptP,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+func (s *EncryptedStore) passphrase(confirm bool) (string, error) {
+	if s.Passphrase == nil {
+		return "", errors.New("a passphrase is required to use the encrypted credential store")
+	}
+	passphrase, err := s.Passphrase(confirm)
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("passphrase must not be empty")
+	}
+	return passphrase, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
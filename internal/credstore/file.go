@@ -0,0 +1,43 @@
+package credstore
+
+import (
+	"fizzy-cli/internal/config"
+)
+
+// FileStore keeps credentials in plaintext inside the profile in the
+// config file.
+type FileStore struct {
+	Path string
+}
+
+func (s *FileStore) Name() string     { return BackendFile }
+func (s *FileStore) Location() string { return s.Path }
+
+func (s *FileStore) Get(key Key) (Credentials, error) {
+	cfg, err := config.Load(s.Path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	profile, _ := cfg.Profile(key.Profile)
+	return Credentials{Token: profile.Token, SessionToken: profile.SessionToken}, nil
+}
+
+func (s *FileStore) Store(key Key, creds Credentials) error {
+	return s.update(key, creds)
+}
+
+func (s *FileStore) Erase(key Key) error {
+	return s.update(key, Credentials{})
+}
+
+func (s *FileStore) update(key Key, creds Credentials) error {
+	cfg, err := config.Load(s.Path)
+	if err != nil {
+		return err
+	}
+	profile, _ := cfg.Profile(key.Profile)
+	profile.Token = creds.Token
+	profile.SessionToken = creds.SessionToken
+	cfg.SetProfile(key.Profile, profile)
+	return config.Save(s.Path, cfg)
+}
@@ -0,0 +1,109 @@
+package credstore
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const helperPrefix = "fizzy-cli-credential-"
+
+// HelperStore delegates to an external program in the style of git
+// credential helpers. The program fizzy-cli-credential-NAME is run with
+// "get", "store" or "erase" and receives key=value lines on stdin,
+// terminated by a blank line:
+//
+//	profile=default
+//	base_url=https://app.fizzy.do
+//	token=...            (store only)
+//	session_token=...    (store only)
+//
+// For "get" it prints token= and/or session_token= lines on stdout.
+type HelperStore struct {
+	Helper   string
+	LookPath func(string) (string, error)
+}
+
+func (s *HelperStore) Name() string     { return BackendHelper + ":" + s.Helper }
+func (s *HelperStore) Location() string { return s.program() }
+
+func (s *HelperStore) Get(key Key) (Credentials, error) {
+	out, err := s.run("get", key, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	var creds Credentials
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "token":
+			creds.Token = v
+		case "session_token":
+			creds.SessionToken = v
+		}
+	}
+	return creds, scanner.Err()
+}
+
+func (s *HelperStore) Store(key Key, creds Credentials) error {
+	_, err := s.run("store", key, &creds)
+	return err
+}
+
+func (s *HelperStore) Erase(key Key) error {
+	_, err := s.run("erase", key, nil)
+	return err
+}
+
+func (s *HelperStore) run(action string, key Key, creds *Credentials) ([]byte, error) {
+	lookPath := s.LookPath
+	if lookPath == nil {
+		lookPath = exec.LookPath
+	}
+	path, err := lookPath(s.program())
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %s not found in PATH", s.program())
+	}
+
+	input := &bytes.Buffer{}
+	fmt.Fprintf(input, "profile=%s\n", key.Profile)
+	fmt.Fprintf(input, "base_url=%s\n", key.BaseURL)
+	if creds != nil {
+		if creds.Token != "" {
+			fmt.Fprintf(input, "token=%s\n", creds.Token)
+		}
+		if creds.SessionToken != "" {
+			fmt.Fprintf(input, "session_token=%s\n", creds.SessionToken)
+		}
+	}
+	input.WriteString("\n")
+
+	cmd := exec.Command(path, action)
+	cmd.Stdin = input
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("credential helper %s %s failed: %s", s.program(), action, msg)
+		}
+		return nil, fmt.Errorf("credential helper %s %s failed: %w", s.program(), action, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+func (s *HelperStore) program() string {
+	return helperPrefix + s.Helper
+}
@@ -0,0 +1,133 @@
+package credstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// scryptKey derives a key as specified in RFC 7914. N must be a power of
+// two greater than one.
+func scryptKey(password, salt []byte, n, r, p, keyLen int) ([]byte, error) {
+	if n <= 1 || n&(n-1) != 0 {
+		return nil, errors.New("scrypt: N must be a power of two greater than 1")
+	}
+	if r <= 0 || p <= 0 || uint64(r)*uint64(p) >= 1<<30 || r > (1<<31-1)/128/p || n > (1<<31-1)/128/r {
+		return nil, errors.New("scrypt: parameters are too large")
+	}
+
+	xy := make([]uint32, 64*r)
+	v := make([]uint32, 32*n*r)
+	b := pbkdf2SHA256(password, salt, 1, p*128*r)
+	for i := 0; i < p; i++ {
+		smix(b[i*128*r:], r, n, v, xy)
+	}
+	return pbkdf2SHA256(password, b, 1, keyLen), nil
+}
+
+func pbkdf2SHA256(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+	dk := make([]byte, 0, blocks*hashLen)
+	u := make([]byte, hashLen)
+	var counter [4]byte
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Write(counter[:])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+		for i := 2; i <= iter; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range u {
+				t[j] ^= u[j]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
+
+func smix(b []byte, r, n int, v, xy []uint32) {
+	var tmp [16]uint32
+	blockLen := 32 * r
+	x := xy
+	y := xy[blockLen:]
+
+	for i := 0; i < blockLen; i++ {
+		x[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+	for i := 0; i < n; i += 2 {
+		copy(v[i*blockLen:], x[:blockLen])
+		blockMix(&tmp, x, y, r)
+		copy(v[(i+1)*blockLen:], y[:blockLen])
+		blockMix(&tmp, y, x, r)
+	}
+	for i := 0; i < n; i += 2 {
+		j := int(integerify(x, r) & uint64(n-1))
+		blockXOR(x, v[j*blockLen:], blockLen)
+		blockMix(&tmp, x, y, r)
+		j = int(integerify(y, r) & uint64(n-1))
+		blockXOR(y, v[j*blockLen:], blockLen)
+		blockMix(&tmp, y, x, r)
+	}
+	for i, w := range x[:blockLen] {
+		binary.LittleEndian.PutUint32(b[i*4:], w)
+	}
+}
+
+func blockXOR(dst, src []uint32, n int) {
+	for i, w := range src[:n] {
+		dst[i] ^= w
+	}
+}
+
+func integerify(b []uint32, r int) uint64 {
+	j := (2*r - 1) * 16
+	return uint64(b[j]) | uint64(b[j+1])<<32
+}
+
+func blockMix(tmp *[16]uint32, in, out []uint32, r int) {
+	copy(tmp[:], in[(2*r-1)*16:])
+	for i := 0; i < 2*r; i += 2 {
+		salsaXOR(tmp, in[i*16:], out[i*8:])
+		salsaXOR(tmp, in[i*16+16:], out[i*8+r*16:])
+	}
+}
+
+// salsaXOR applies Salsa20/8 to tmp XOR in, writing the result to both out
+// and tmp.
+func salsaXOR(tmp *[16]uint32, in, out []uint32) {
+	var w, x [16]uint32
+	for i := range w {
+		w[i] = tmp[i] ^ in[i]
+	}
+	x = w
+	quarter := func(a, b, c, d int) {
+		x[b] ^= bits.RotateLeft32(x[a]+x[d], 7)
+		x[c] ^= bits.RotateLeft32(x[b]+x[a], 9)
+		x[d] ^= bits.RotateLeft32(x[c]+x[b], 13)
+		x[a] ^= bits.RotateLeft32(x[d]+x[c], 18)
+	}
+	for i := 0; i < 8; i += 2 {
+		quarter(0, 4, 8, 12)
+		quarter(5, 9, 13, 1)
+		quarter(10, 14, 2, 6)
+		quarter(15, 3, 7, 11)
+		quarter(0, 1, 2, 3)
+		quarter(5, 6, 7, 4)
+		quarter(10, 11, 8, 9)
+		quarter(15, 12, 13, 14)
+	}
+	for i := range x {
+		x[i] += w[i]
+		out[i] = x[i]
+		tmp[i] = x[i]
+	}
+}
@@ -0,0 +1,74 @@
+package credstore
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test vectors from RFC 7914, sections 11 and 12.
+
+func TestPBKDF2SHA256(t *testing.T) {
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got := hex.EncodeToString(pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)); got != want {
+		t.Errorf("PBKDF2-HMAC-SHA256 = %s, want %s", got, want)
+	}
+}
+
+func TestScryptVectors(t *testing.T) {
+	tests := []struct {
+		password, salt string
+		n, r, p        int
+		want           string
+		slow           bool
+	}{
+		{"", "", 16, 1, 1, "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442" +
+			"fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906", false},
+		{"password", "NaCl", 1024, 8, 16, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162" +
+			"2eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640", false},
+		{"pleaseletmein", "SodiumChloride", 16384, 8, 1, "7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2" +
+			"d5432955613f0fcf62d49705242a9af9e61e85dc0d651e40dfcf017b45575887", true},
+	}
+	for _, tt := range tests {
+		if tt.slow && testing.Short() {
+			continue
+		}
+		key, err := scryptKey([]byte(tt.password), []byte(tt.salt), tt.n, tt.r, tt.p, 64)
+		if err != nil {
+			t.Fatalf("scrypt(%q, %q, N=%d): %v", tt.password, tt.salt, tt.n, err)
+		}
+		if got := hex.EncodeToString(key); got != tt.want {
+			t.Errorf("scrypt(%q, %q, N=%d, r=%d, p=%d) = %s, want %s", tt.password, tt.salt, tt.n, tt.r, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestEncryptedFileScryptCeiling(t *testing.T) {
+	for _, tt := range []struct{ n, r, p int }{{1 << 24, 8, 1}, {1 << 15, 32, 1}, {1 << 15, 8, 5}} {
+		path := filepath.Join(t.TempDir(), "credentials.enc")
+		data := fmt.Sprintf(`{"version": 1, "kdf": "scrypt", "n": %d, "r": %d, "p": %d, "salt": "AAAA", "nonce": "AAAA", "ciphertext": "AAAA"}`, tt.n, tt.r, tt.p)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		store := &EncryptedStore{Path: path, Passphrase: func(bool) (string, error) {
+			t.Fatal("asked for the passphrase")
+			return "", nil
+		}}
+		_, err := store.Get(Key{Profile: "default"})
+		if err == nil || !strings.Contains(err.Error(), "scrypt parameters") {
+			t.Errorf("N=%d, r=%d, p=%d: got %v, want a parameter error", tt.n, tt.r, tt.p, err)
+		}
+	}
+}
+
+func TestScryptParameters(t *testing.T) {
+	for _, tt := range []struct{ n, r, p int }{{0, 1, 1}, {1, 1, 1}, {15, 1, 1}, {16, 0, 1}, {16, 1, 0}, {16, 1 << 16, 1 << 14}} {
+		if _, err := scryptKey([]byte("x"), []byte("y"), tt.n, tt.r, tt.p, 32); err == nil || !strings.HasPrefix(err.Error(), "scrypt: ") {
+			t.Errorf("scrypt(N=%d, r=%d, p=%d): got %v, want a parameter error", tt.n, tt.r, tt.p, err)
+		}
+	}
+}
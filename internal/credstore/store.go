@@ -0,0 +1,81 @@
+// Package credstore keeps API credentials outside of, or encrypted within,
+// the CLI's config directory.
+package credstore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Credentials struct {
+	Token        string `json:"token,omitempty"`
+	SessionToken string `json:"session_token,omitempty"`
+}
+
+func (c Credentials) Empty() bool {
+	return c.Token == "" && c.SessionToken == ""
+}
+
+// Key identifies the credentials of one config profile.
+type Key struct {
+	Profile string
+	BaseURL string
+}
+
+type Store interface {
+	// Name is the backend spec as written in the config file.
+	Name() string
+	// Location tells the user where the secrets end up.
+	Location() string
+	Get(key Key) (Credentials, error)
+	Store(key Key, creds Credentials) error
+	Erase(key Key) error
+}
+
+const (
+	BackendFile      = "file"
+	BackendEncrypted = "encrypted"
+	BackendHelper    = "helper"
+)
+
+// Options carries what the individual backends need to locate secrets.
+type Options struct {
+	ConfigPath    string
+	EncryptedPath string
+	Passphrase    func(confirm bool) (string, error)
+}
+
+// ValidateSpec checks a backend spec: "file", "encrypted" or "helper:NAME".
+func ValidateSpec(spec string) error {
+	name, arg, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	switch name {
+	case "", BackendFile, BackendEncrypted:
+		if arg != "" {
+			return fmt.Errorf("credential store %q takes no argument", name)
+		}
+		return nil
+	case BackendHelper:
+		if strings.TrimSpace(arg) == "" || strings.ContainsAny(arg, "/\\ ") {
+			return errors.New("credential helper name is required, e.g. helper:pass")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown credential store %q (use file, encrypted or helper:NAME)", spec)
+	}
+}
+
+func Open(spec string, opts Options) (Store, error) {
+	if err := ValidateSpec(spec); err != nil {
+		return nil, err
+	}
+	name, arg, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	switch name {
+	case BackendEncrypted:
+		return &EncryptedStore{Path: opts.EncryptedPath, Passphrase: opts.Passphrase}, nil
+	case BackendHelper:
+		return &HelperStore{Helper: arg}, nil
+	default:
+		return &FileStore{Path: opts.ConfigPath}, nil
+	}
+}
@@ -0,0 +1,166 @@
+// Package journal keeps a local log of the changes the CLI makes, each with
+// a snapshot of the resource as it was before, so that they can be undone.
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxEntries is how many entries are kept; older ones are pruned on Add.
+const MaxEntries = 500
+
+// ErrNotFound is returned by Get for an unknown entry ID.
+var ErrNotFound = errors.New("journal entry not found")
+
+// Entry is one recorded change.
+type Entry struct {
+	ID      int       `json:"id"`
+	Time    time.Time `json:"time"`
+	BaseURL string    `json:"base_url"`
+	Account string    `json:"account"`
+	// Action is the command that made the change, e.g. "card update".
+	Action string `json:"action"`
+	Card   int    `json:"card"`
+	// Target names what the action applied to besides the card: a comment
+	// ID, a tag title or an assignee ID.
+	Target string `json:"target,omitempty"`
+	// Fields lists what an update changed, e.g. title and description.
+	Fields []string `json:"fields,omitempty"`
+	// Before is the API's JSON for the card or comment before the change.
+	Before json.RawMessage `json:"before,omitempty"`
+	// Comments holds the comments of a deleted card.
+	Comments json.RawMessage `json:"comments,omitempty"`
+
+	UndoneAt *time.Time `json:"undone_at,omitempty"`
+	// UndoNotes records what undo restored and what it could not.
+	UndoNotes []string `json:"undo_notes,omitempty"`
+}
+
+// Store keeps one JSON file per entry in Dir.
+type Store struct {
+	Dir string
+	Now func() time.Time
+}
+
+// DefaultDir returns the journal directory next to the config file.
+func DefaultDir(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "journal")
+}
+
+func New(dir string) *Store {
+	return &Store{Dir: dir, Now: time.Now}
+}
+
+// Add assigns e the next ID and saves it, pruning the oldest entries
+// beyond MaxEntries.
+func (s *Store) Add(e *Entry) error {
+	ids, err := s.ids()
+	if err != nil {
+		return err
+	}
+	e.ID = 1
+	if len(ids) > 0 {
+		e.ID = ids[len(ids)-1] + 1
+	}
+	if e.Time.IsZero() {
+		e.Time = s.now()
+	}
+	if err := s.Save(e); err != nil {
+		return err
+	}
+	for len(ids) >= MaxEntries {
+		os.Remove(s.path(ids[0]))
+		ids = ids[1:]
+	}
+	return nil
+}
+
+// Save writes e, replacing any entry with the same ID.
+func (s *Store) Save(e *Entry) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+	path := s.path(e.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Store) Get(id int) (*Entry, error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("journal entry %d: %w", id, err)
+	}
+	return &e, nil
+}
+
+// List returns every entry, oldest first.
+func (s *Store) List() ([]Entry, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(ids))
+	for _, id := range ids {
+		e, err := s.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, nil
+}
+
+func (s *Store) ids() ([]int, error) {
+	files, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		if id, err := strconv.Atoi(strings.TrimSuffix(name, ".json")); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func (s *Store) path(id int) string {
+	return filepath.Join(s.Dir, fmt.Sprintf("%06d.json", id))
+}
+
+func (s *Store) now() time.Time {
+	if s.Now == nil {
+		return time.Now()
+	}
+	return s.Now()
+}
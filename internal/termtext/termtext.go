@@ -0,0 +1,144 @@
+// Package termtext measures and fits text for display in a terminal, where
+// ANSI escape sequences take no room and wide characters take two cells.
+package termtext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Width returns the number of terminal cells s occupies.
+func Width(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		width += RuneWidth(r)
+	}
+	return width
+}
+
+// RuneWidth returns the number of cells r occupies: 0 for control and
+// combining characters, 2 for East Asian wide characters and emoji.
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0, unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), r == 0x200b:
+		return 0
+	case unicode.IsControl(r):
+		return 0
+	case r >= 0x1100 && (r <= 0x115f ||
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f ||
+		r >= 0xac00 && r <= 0xd7a3 ||
+		r >= 0xf900 && r <= 0xfaff ||
+		r >= 0xfe30 && r <= 0xfe4f ||
+		r >= 0xff00 && r <= 0xff60 ||
+		r >= 0xffe0 && r <= 0xffe6 ||
+		r >= 0x1f300 && r <= 0x1f64f ||
+		r >= 0x1f900 && r <= 0x1f9ff ||
+		r >= 0x20000 && r <= 0x3fffd):
+		return 2
+	}
+	return 1
+}
+
+// Fit truncates s to width cells, ending with "…" when shortened, and pads
+// it with spaces to exactly width cells. Escape sequences are kept.
+func Fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if w := Width(s); w <= width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	b := &strings.Builder{}
+	used := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			b.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w := RuneWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+		i += size
+	}
+	b.WriteString("…")
+	used++
+	if strings.Contains(s, "\x1b[") {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String() + strings.Repeat(" ", width-used)
+}
+
+// Wrap breaks plain text into lines of at most width cells, splitting on
+// spaces where possible. Newlines in s start new lines.
+func Wrap(s string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line, lineWidth := "", 0
+		for _, word := range strings.Fields(para) {
+			w := Width(word)
+			for w > width {
+				// Hard-break words longer than a line.
+				if line != "" {
+					lines = append(lines, line)
+					line, lineWidth = "", 0
+				}
+				head, rest := splitAt(word, width)
+				lines = append(lines, head)
+				word, w = rest, Width(rest)
+			}
+			switch {
+			case line == "":
+				line, lineWidth = word, w
+			case lineWidth+1+w <= width:
+				line += " " + word
+				lineWidth += 1 + w
+			default:
+				lines = append(lines, line)
+				line, lineWidth = word, w
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func splitAt(s string, width int) (string, string) {
+	used := 0
+	for i, r := range s {
+		w := RuneWidth(r)
+		if used+w > width {
+			return s[:i], s[i:]
+		}
+		used += w
+	}
+	return s, ""
+}
+
+// escapeLen returns the length of the CSI escape sequence at the start of
+// s, or 0 if there is none.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != 0x1b || s[1] != '[' {
+		return 0
+	}
+	for j := 2; j < len(s); j++ {
+		if s[j] >= 0x40 && s[j] <= 0x7e {
+			return j + 1
+		}
+	}
+	return len(s)
+}
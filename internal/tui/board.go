@@ -0,0 +1,411 @@
+// Package tui draws an interactive kanban board in the terminal.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fizzy-cli/pkg/fizzy"
+)
+
+// LaneKind says what putting a card into a lane means.
+type LaneKind int
+
+const (
+	LaneNotNow LaneKind = iota
+	LaneTriage
+	LaneColumn
+	LaneDone
+)
+
+// Lane is one vertical list of cards: a board column, or one of the
+// built-in Not Now, triage and Done lanes.
+type Lane struct {
+	Kind     LaneKind
+	ColumnID string
+	Name     string
+	// Color holds SGR attributes for the lane header, e.g. "34".
+	Color string
+	Cards []fizzy.Card
+}
+
+type Board struct {
+	Name  string
+	Lanes []Lane
+}
+
+// Detail is what the detail pane shows for a card.
+type Detail struct {
+	Card     *fizzy.Card
+	Comments []fizzy.Comment
+}
+
+// Backend loads the board and changes cards. The CLI implements it with the
+// API client; tests can use an in-memory one.
+type Backend interface {
+	Load(ctx context.Context) (*Board, error)
+	Detail(ctx context.Context, number int) (*Detail, error)
+	Triage(ctx context.Context, number int, columnID string) error
+	Untriage(ctx context.Context, number int) error
+	Close(ctx context.Context, number int) error
+	Reopen(ctx context.Context, number int) error
+	NotNow(ctx context.Context, number int) error
+}
+
+type Options struct {
+	// Refresh reloads the board at this interval. Zero disables it.
+	Refresh time.Duration
+	// Now returns the time shown as the last update; defaults to time.Now.
+	Now func() time.Time
+}
+
+// Run shows the board until the user quits or the terminal's input ends.
+func Run(ctx context.Context, term Terminal, backend Backend, opts Options) error {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	a := &app{ctx: ctx, term: term, backend: backend, opts: opts}
+	if err := a.reload(); err != nil {
+		return err
+	}
+	restore, err := term.Start()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	keys := make(chan KeyEvent, 16)
+	go readKeys(term, keys)
+
+	var refresh <-chan time.Time
+	if opts.Refresh > 0 {
+		ticker := time.NewTicker(opts.Refresh)
+		defer ticker.Stop()
+		refresh = ticker.C
+	}
+	resize := time.NewTicker(250 * time.Millisecond)
+	defer resize.Stop()
+
+	a.draw()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			if a.handle(ev) {
+				return nil
+			}
+		case <-refresh:
+			a.refresh()
+		case <-resize.C:
+			if w, h, err := term.Size(); err != nil || (w == a.width && h == a.height) {
+				continue
+			}
+		}
+		a.draw()
+	}
+}
+
+type app struct {
+	ctx     context.Context
+	term    Terminal
+	backend Backend
+	opts    Options
+
+	board   *Board
+	lane    int
+	card    int
+	first   int
+	offsets []int
+	updated time.Time
+
+	detail       *Detail
+	detailScroll int
+
+	message string
+	isError bool
+
+	width  int
+	height int
+}
+
+func (a *app) reload() error {
+	board, err := a.backend.Load(a.ctx)
+	if err != nil {
+		return err
+	}
+	selected := a.selected()
+	a.board = board
+	a.updated = a.opts.Now()
+	if len(a.offsets) != len(board.Lanes) {
+		a.offsets = make([]int, len(board.Lanes))
+	}
+	if selected != nil && a.selectCard(selected.Number) {
+		return nil
+	}
+	a.clampSelection()
+	return nil
+}
+
+func (a *app) refresh() {
+	if err := a.reload(); err != nil {
+		a.fail(err)
+	}
+}
+
+func (a *app) selected() *fizzy.Card {
+	if a.board == nil || a.lane >= len(a.board.Lanes) {
+		return nil
+	}
+	cards := a.board.Lanes[a.lane].Cards
+	if a.card < 0 || a.card >= len(cards) {
+		return nil
+	}
+	return &cards[a.card]
+}
+
+func (a *app) selectCard(number int) bool {
+	for i, lane := range a.board.Lanes {
+		for j, c := range lane.Cards {
+			if c.Number == number {
+				a.lane, a.card = i, j
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *app) clampSelection() {
+	if a.lane >= len(a.board.Lanes) {
+		a.lane = len(a.board.Lanes) - 1
+	}
+	if a.lane < 0 {
+		a.lane = 0
+	}
+	if len(a.board.Lanes) == 0 {
+		a.card = 0
+		return
+	}
+	if n := len(a.board.Lanes[a.lane].Cards); a.card >= n {
+		a.card = n - 1
+	}
+	if a.card < 0 {
+		a.card = 0
+	}
+}
+
+func (a *app) info(format string, args ...any) {
+	a.message, a.isError = fmt.Sprintf(format, args...), false
+}
+
+func (a *app) fail(err error) {
+	a.message, a.isError = "Error: "+err.Error(), true
+}
+
+// handle applies one key press and reports whether to quit.
+func (a *app) handle(ev KeyEvent) bool {
+	if ev.Key == KeyCtrlC {
+		return true
+	}
+	if a.detail != nil {
+		return a.handleDetail(ev)
+	}
+	switch {
+	case ev.Key == KeyLeft || ev.Rune == 'h':
+		a.moveSelection(-1, 0)
+	case ev.Key == KeyRight || ev.Rune == 'l' || ev.Key == KeyTab:
+		a.moveSelection(1, 0)
+	case ev.Key == KeyUp || ev.Rune == 'k':
+		a.moveSelection(0, -1)
+	case ev.Key == KeyDown || ev.Rune == 'j':
+		a.moveSelection(0, 1)
+	case ev.Key == KeyPageUp:
+		a.moveSelection(0, -a.cardRows())
+	case ev.Key == KeyPageDown:
+		a.moveSelection(0, a.cardRows())
+	case ev.Rune == 'H' || ev.Rune == '<':
+		a.shiftCard(-1)
+	case ev.Rune == 'L' || ev.Rune == '>':
+		a.shiftCard(1)
+	case ev.Key == KeyEnter || ev.Rune == ' ':
+		a.openDetail()
+	case ev.Rune == 'x':
+		a.sendTo(LaneDone)
+	case ev.Rune == 'n':
+		a.sendTo(LaneNotNow)
+	case ev.Rune == 'o':
+		a.reopen()
+	case ev.Rune == 'r':
+		a.refresh()
+		if !a.isError {
+			a.info("Refreshed.")
+		}
+	case ev.Rune == 'q' || ev.Key == KeyEscape:
+		return true
+	}
+	return false
+}
+
+func (a *app) handleDetail(ev KeyEvent) bool {
+	switch {
+	case ev.Key == KeyEscape || ev.Key == KeyEnter || ev.Rune == 'q':
+		a.detail = nil
+	case ev.Key == KeyUp || ev.Rune == 'k':
+		a.detailScroll--
+	case ev.Key == KeyDown || ev.Rune == 'j':
+		a.detailScroll++
+	case ev.Key == KeyPageUp:
+		a.detailScroll -= a.height - 3
+	case ev.Key == KeyPageDown:
+		a.detailScroll += a.height - 3
+	case ev.Rune == 'x':
+		a.detail = nil
+		a.sendTo(LaneDone)
+	case ev.Rune == 'n':
+		a.detail = nil
+		a.sendTo(LaneNotNow)
+	case ev.Rune == 'o':
+		a.detail = nil
+		a.reopen()
+	}
+	if a.detailScroll < 0 {
+		a.detailScroll = 0
+	}
+	return false
+}
+
+func (a *app) moveSelection(dLane, dCard int) {
+	if len(a.board.Lanes) == 0 {
+		return
+	}
+	if dLane != 0 {
+		a.lane += dLane
+		if a.lane < 0 {
+			a.lane = 0
+		}
+		if a.lane >= len(a.board.Lanes) {
+			a.lane = len(a.board.Lanes) - 1
+		}
+	}
+	a.card += dCard
+	a.clampSelection()
+}
+
+// shiftCard moves the selected card to the neighbouring lane.
+func (a *app) shiftCard(dir int) {
+	target := a.lane + dir
+	if a.selected() == nil || target < 0 || target >= len(a.board.Lanes) {
+		return
+	}
+	a.moveTo(target)
+}
+
+// sendTo moves the selected card to the first lane of the given kind.
+func (a *app) sendTo(kind LaneKind) {
+	if a.selected() == nil {
+		return
+	}
+	for i, lane := range a.board.Lanes {
+		if lane.Kind == kind {
+			if i == a.lane {
+				return
+			}
+			a.moveTo(i)
+			return
+		}
+	}
+}
+
+func (a *app) reopen() {
+	card := a.selected()
+	if card == nil {
+		return
+	}
+	if a.board.Lanes[a.lane].Kind != LaneDone {
+		a.info("#%d is not closed.", card.Number)
+		return
+	}
+	if err := a.backend.Reopen(a.ctx, card.Number); err != nil {
+		a.fail(err)
+		return
+	}
+	a.afterChange(card.Number, fmt.Sprintf("Reopened #%d.", card.Number))
+}
+
+func (a *app) moveTo(target int) {
+	card := *a.selected()
+	from, to := a.board.Lanes[a.lane], a.board.Lanes[target]
+	if err := moveCard(a.ctx, a.backend, card.Number, from, to); err != nil {
+		a.fail(err)
+		return
+	}
+	a.afterChange(card.Number, fmt.Sprintf("Moved #%d to %s.", card.Number, to.Name))
+}
+
+func (a *app) afterChange(number int, message string) {
+	if err := a.reload(); err != nil {
+		a.fail(err)
+		return
+	}
+	a.selectCard(number)
+	a.info("%s", message)
+}
+
+// moveCard translates moving a card between lanes into API calls. Closed
+// cards are reopened first.
+func moveCard(ctx context.Context, backend Backend, number int, from, to Lane) error {
+	if from.Kind == LaneDone && to.Kind != LaneDone {
+		if err := backend.Reopen(ctx, number); err != nil {
+			return err
+		}
+	}
+	switch to.Kind {
+	case LaneColumn:
+		return backend.Triage(ctx, number, to.ColumnID)
+	case LaneTriage:
+		if from.Kind == LaneDone {
+			return nil
+		}
+		return backend.Untriage(ctx, number)
+	case LaneNotNow:
+		return backend.NotNow(ctx, number)
+	case LaneDone:
+		return backend.Close(ctx, number)
+	}
+	return nil
+}
+
+func (a *app) openDetail() {
+	card := a.selected()
+	if card == nil {
+		return
+	}
+	detail, err := a.backend.Detail(a.ctx, card.Number)
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	a.detail, a.detailScroll = detail, 0
+}
+
+func (a *app) draw() {
+	w, h, err := a.term.Size()
+	if err != nil || w <= 0 || h <= 0 {
+		w, h = 80, 24
+	}
+	a.width, a.height = w, h
+	var lines []string
+	if a.detail != nil {
+		lines = a.renderDetail()
+	} else {
+		lines = a.renderBoard()
+	}
+	io.WriteString(a.term, "\x1b[H"+strings.Join(lines, "\x1b[0m\r\n")+"\x1b[0m")
+}
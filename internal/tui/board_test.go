@@ -0,0 +1,268 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fizzy-cli/pkg/fizzy"
+)
+
+// fakeBackend keeps a board in memory and records the calls made to it.
+type fakeBackend struct {
+	mu       sync.Mutex
+	lanes    []Lane
+	calls    []string
+	closeErr error
+}
+
+func newFakeBackend() *fakeBackend {
+	card := func(number int, title string) fizzy.Card {
+		return fizzy.Card{Number: number, Title: title, Status: "published"}
+	}
+	return &fakeBackend{lanes: []Lane{
+		{Kind: LaneNotNow, Name: "Not Now", Cards: []fizzy.Card{card(4, "Export boards as PDF")}},
+		{Kind: LaneTriage, Name: "Maybe?", Cards: []fizzy.Card{card(3, "Keyboard shortcuts")}},
+		{Kind: LaneColumn, ColumnID: "col-progress", Name: "In Progress", Cards: []fizzy.Card{card(1, "Add dark mode")}},
+		{Kind: LaneColumn, ColumnID: "col-review", Name: "Review", Cards: []fizzy.Card{card(2, "Crash on delete")}},
+		{Kind: LaneDone, Name: "Done", Cards: []fizzy.Card{card(5, "Set up the project")}},
+	}}
+}
+
+func (b *fakeBackend) Load(ctx context.Context) (*Board, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	board := &Board{Name: "Roadmap", Lanes: make([]Lane, len(b.lanes))}
+	for i, lane := range b.lanes {
+		lane.Cards = append([]fizzy.Card(nil), lane.Cards...)
+		board.Lanes[i] = lane
+	}
+	return board, nil
+}
+
+func (b *fakeBackend) Detail(ctx context.Context, number int) (*Detail, error) {
+	b.record("detail %d", number)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, lane := range b.lanes {
+		for _, c := range lane.Cards {
+			if c.Number == number {
+				c.Description = "Follow the system theme."
+				comment := fizzy.Comment{Body: fizzy.CommentBody{Plain: "Looks good."}, Creator: fizzy.User{Name: "Sam Lee"}, CreatedAt: "2026-10-17T12:00:00Z"}
+				return &Detail{Card: &c, Comments: []fizzy.Comment{comment}}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("card %d not found", number)
+}
+
+func (b *fakeBackend) Triage(ctx context.Context, number int, columnID string) error {
+	b.record("triage %d %s", number, columnID)
+	return b.move(number, func(l Lane) bool { return l.ColumnID == columnID })
+}
+
+func (b *fakeBackend) Untriage(ctx context.Context, number int) error {
+	b.record("untriage %d", number)
+	return b.move(number, func(l Lane) bool { return l.Kind == LaneTriage })
+}
+
+func (b *fakeBackend) Close(ctx context.Context, number int) error {
+	b.record("close %d", number)
+	if b.closeErr != nil {
+		return b.closeErr
+	}
+	return b.move(number, func(l Lane) bool { return l.Kind == LaneDone })
+}
+
+func (b *fakeBackend) Reopen(ctx context.Context, number int) error {
+	b.record("reopen %d", number)
+	return b.move(number, func(l Lane) bool { return l.Kind == LaneTriage })
+}
+
+func (b *fakeBackend) NotNow(ctx context.Context, number int) error {
+	b.record("not-now %d", number)
+	return b.move(number, func(l Lane) bool { return l.Kind == LaneNotNow })
+}
+
+func (b *fakeBackend) record(format string, args ...any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = append(b.calls, fmt.Sprintf(format, args...))
+}
+
+func (b *fakeBackend) move(number int, to func(Lane) bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var card *fizzy.Card
+	for i, lane := range b.lanes {
+		for j, c := range lane.Cards {
+			if c.Number == number {
+				card = &c
+				b.lanes[i].Cards = append(lane.Cards[:j:j], lane.Cards[j+1:]...)
+				break
+			}
+		}
+	}
+	if card == nil {
+		return fmt.Errorf("card %d not found", number)
+	}
+	for i, lane := range b.lanes {
+		if to(lane) {
+			b.lanes[i].Cards = append(b.lanes[i].Cards, *card)
+			return nil
+		}
+	}
+	return errors.New("no such lane")
+}
+
+// keyReader hands the board one key per read, each once the frame drawn
+// after the previous key is on screen, and keeps a copy of those frames.
+type keyReader struct {
+	term   *VirtualTerminal
+	keys   []string
+	frames []string
+}
+
+func (r *keyReader) Read(p []byte) (int, error) {
+	i := len(r.frames)
+	if i >= len(r.keys) {
+		return 0, io.EOF
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for r.term.Frames() <= i {
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("frame %d was not drawn", i+1)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	r.frames = append(r.frames, r.term.Screen())
+	return copy(p, r.keys[i]), nil
+}
+
+// runBoard runs the board on a virtual terminal wide enough for every lane
+// and returns the frame seen before each key and the final screen.
+func runBoard(t *testing.T, backend Backend, keys ...string) ([]string, string) {
+	t.Helper()
+	term := NewVirtualTerminal(130, 12, nil)
+	input := &keyReader{term: term, keys: keys}
+	term.Input = input
+	now := func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	done := make(chan error, 1)
+	go func() { done <- Run(context.Background(), term, backend, Options{Now: now}) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run did not return")
+	}
+	return input.frames, term.Screen()
+}
+
+func assertCalls(t *testing.T, b *fakeBackend, want ...string) {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if got := strings.Join(b.calls, ", "); got != strings.Join(want, ", ") {
+		t.Errorf("backend calls = [%s], want [%s]", got, strings.Join(want, ", "))
+	}
+}
+
+func assertScreen(t *testing.T, name, screen string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(screen, w) {
+			t.Errorf("%s does not show %q:\n%s", name, w, screen)
+		}
+	}
+}
+
+func TestBoardFirstFrame(t *testing.T) {
+	frames, _ := runBoard(t, newFakeBackend(), "q")
+	assertScreen(t, "first frame", frames[0],
+		"Roadmap · 5 cards · updated 12:00:00",
+		"Not Now (1)", "Maybe? (1)", "In Progress (1)", "Review (1)", "Done (1)",
+		"#4 Export boards as PDF", "#1 Add dark mode", "q quit")
+}
+
+func TestBoardMoveCards(t *testing.T) {
+	b := newFakeBackend()
+	// Select #3 in Maybe?, move it right into In Progress, then close it.
+	frames, last := runBoard(t, b, "l", "L", "x", "q")
+	assertCalls(t, b, "triage 3 col-progress", "close 3")
+	assertScreen(t, "frame after L", frames[2], "Moved #3 to In Progress.", "Maybe? (0)", "In Progress (2)")
+	assertScreen(t, "frame after x", frames[3], "Moved #3 to Done.", "In Progress (1)", "Done (2)")
+	if last != frames[3] {
+		t.Errorf("q drew another frame:\n%s", last)
+	}
+}
+
+func TestBoardMoveOutOfDone(t *testing.T) {
+	b := newFakeBackend()
+	// Select #5 in Done and move it left into Review: it is reopened first.
+	frames, _ := runBoard(t, b, "\x1b[C", "\x1b[C", "\x1b[C", "\x1b[C", "H", "q")
+	assertCalls(t, b, "reopen 5", "triage 5 col-review")
+	assertScreen(t, "frame after H", frames[5], "Moved #5 to Review.", "Review (2)", "Done (0)")
+}
+
+func TestBoardNotNowAndReopen(t *testing.T) {
+	b := newFakeBackend()
+	frames, _ := runBoard(t, b, "l", "l", "n", "o", "q")
+	assertCalls(t, b, "not-now 1")
+	assertScreen(t, "frame after n", frames[3], "Moved #1 to Not Now.", "Not Now (2)")
+	assertScreen(t, "frame after o", frames[4], "#1 is not closed.")
+}
+
+func TestBoardDetail(t *testing.T) {
+	b := newFakeBackend()
+	frames, last := runBoard(t, b, "l", "l", "\r", "\x1b", "q")
+	assertCalls(t, b, "detail 1")
+	assertScreen(t, "detail", frames[3], "#1 Add dark mode", "Column: In Progress", "Follow the system theme.", "Comments (1)", "Sam Lee · 2026-10-17", "Looks good.", "esc back")
+	assertScreen(t, "frame after esc", frames[4], "Roadmap · 5 cards", "q quit")
+	if strings.Contains(last, "Looks good.") {
+		t.Errorf("detail still shown after esc:\n%s", last)
+	}
+}
+
+func TestBoardBackendError(t *testing.T) {
+	b := newFakeBackend()
+	b.closeErr = errors.New("forbidden")
+	frames, _ := runBoard(t, b, "l", "x", "q")
+	assertCalls(t, b, "close 3")
+	assertScreen(t, "frame after x", frames[2], "Error: forbidden", "Maybe? (1)", "Done (1)")
+}
+
+func TestBoardEndsAtEOF(t *testing.T) {
+	b := newFakeBackend()
+	_, last := runBoard(t, b, "j", "l")
+	assertCalls(t, b)
+	assertScreen(t, "last frame", last, "#3 Keyboard shortcuts")
+}
+
+func TestMarkdownEscapesText(t *testing.T) {
+	board := &Board{Name: "Q4 *launch*", Lanes: []Lane{
+		{Kind: LaneColumn, Name: "[Doing]", Cards: []fizzy.Card{
+			{Number: 1, Title: "[x](javascript:alert(1))", URL: "https://app.fizzy.do/1/cards/1"},
+			{Number: 2, Title: "# Not a heading"},
+			{Number: 3, Title: "* not a list, _not_ emphasis,\n`not code` | not a cell <b>"},
+			{Number: 4, Title: `C:\new #4`},
+		}},
+		{Kind: LaneDone, Name: "Done"},
+	}}
+	want := "# Q4 \\*launch\\*\n" +
+		"\n## \\[Doing\\] (4)\n\n" +
+		"- [#1](https://app.fizzy.do/1/cards/1) \\[x\\](javascript:alert(1))\n" +
+		"- #2 \\# Not a heading\n" +
+		"- #3 \\* not a list, \\_not\\_ emphasis, \\`not code\\` \\| not a cell \\<b>\n" +
+		"- #4 C:\\\\new \\#4\n" +
+		"\n## Done (0)\n\n_No cards._\n"
+	if got := Markdown(board); got != want {
+		t.Errorf("Markdown =\n%s\nwant\n%s", got, want)
+	}
+}
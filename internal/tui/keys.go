@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeyEscape
+	KeyTab
+	KeyPageUp
+	KeyPageDown
+	KeyCtrlC
+)
+
+// KeyEvent is one key press. Rune is set for KeyRune.
+type KeyEvent struct {
+	Key  Key
+	Rune rune
+}
+
+// readKeys decodes key presses from r until it fails or reaches EOF, then
+// closes out.
+func readKeys(r io.Reader, out chan<- KeyEvent) {
+	defer close(out)
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		for _, ev := range parseKeys(buf[:n]) {
+			out <- ev
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseKeys decodes one read from the terminal. A lone ESC is the Escape
+// key; ESC followed by more bytes in the same read is a sequence.
+func parseKeys(b []byte) []KeyEvent {
+	var events []KeyEvent
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) >= 3 && (b[1] == '[' || b[1] == 'O') {
+				n, ev := parseSequence(b)
+				if ev.Key != KeyRune || ev.Rune != 0 {
+					events = append(events, ev)
+				}
+				b = b[n:]
+				continue
+			}
+			events = append(events, KeyEvent{Key: KeyEscape})
+			b = b[1:]
+		case c == '\r' || c == '\n':
+			events = append(events, KeyEvent{Key: KeyEnter})
+			b = b[1:]
+		case c == '\t':
+			events = append(events, KeyEvent{Key: KeyTab})
+			b = b[1:]
+		case c == 0x03:
+			events = append(events, KeyEvent{Key: KeyCtrlC})
+			b = b[1:]
+		case c < 0x20 || c == 0x7f:
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			events = append(events, KeyEvent{Key: KeyRune, Rune: r})
+			b = b[size:]
+		}
+	}
+	return events
+}
+
+// parseSequence decodes a CSI or SS3 sequence such as ESC [ A. Unknown
+// sequences are consumed and return the zero event.
+func parseSequence(b []byte) (int, KeyEvent) {
+	n := 2
+	for n < len(b) && (b[n] < 0x40 || b[n] > 0x7e) {
+		n++
+	}
+	if n == len(b) {
+		return n, KeyEvent{}
+	}
+	params, final := string(b[2:n]), b[n]
+	n++
+	switch final {
+	case 'A':
+		return n, KeyEvent{Key: KeyUp}
+	case 'B':
+		return n, KeyEvent{Key: KeyDown}
+	case 'C':
+		return n, KeyEvent{Key: KeyRight}
+	case 'D':
+		return n, KeyEvent{Key: KeyLeft}
+	case '~':
+		switch params {
+		case "5":
+			return n, KeyEvent{Key: KeyPageUp}
+		case "6":
+			return n, KeyEvent{Key: KeyPageDown}
+		}
+	}
+	return n, KeyEvent{}
+}
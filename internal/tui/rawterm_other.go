@@ -0,0 +1,19 @@
+//go:build !linux && !darwin
+
+package tui
+
+func isTerminal(fd int) bool {
+	return false
+}
+
+func makeRaw(fd int) (func(), error) {
+	return nil, ErrNotTerminal
+}
+
+func disableEcho(fd int) (func(), error) {
+	return nil, ErrNotTerminal
+}
+
+func terminalSize(fd int) (int, int, error) {
+	return 0, 0, ErrNotTerminal
+}
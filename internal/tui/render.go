@@ -0,0 +1,235 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"fizzy-cli/internal/termtext"
+	"fizzy-cli/pkg/fizzy"
+)
+
+const (
+	minLaneWidth = 24
+	boardHelp    = "←→ lane  ↑↓ card  H/L move  enter open  x close  n not now  o reopen  r refresh  q quit"
+	detailHelp   = "↑↓ scroll  esc back  x close  n not now  o reopen"
+)
+
+func sgr(s string, attrs string) string {
+	if attrs == "" {
+		return s
+	}
+	return "\x1b[" + attrs + "m" + s + "\x1b[0m"
+}
+
+// cardRows is how many cards fit in a lane below its header.
+func (a *app) cardRows() int {
+	if rows := a.height - 4; rows > 1 {
+		return rows
+	}
+	return 1
+}
+
+func (a *app) titleBar(title string) string {
+	return sgr(termtext.Fit(" "+title, a.width), "7")
+}
+
+func (a *app) footer(help string) string {
+	if a.message == "" {
+		return sgr(termtext.Fit(" "+help, a.width), "2")
+	}
+	attrs := ""
+	if a.isError {
+		attrs = "31"
+	}
+	return sgr(termtext.Fit(" "+a.message, a.width), attrs)
+}
+
+func (a *app) renderBoard() []string {
+	lanes := a.board.Lanes
+	total := 0
+	for _, lane := range lanes {
+		total += len(lane.Cards)
+	}
+	lines := []string{a.titleBar(fmt.Sprintf("%s · %d cards · updated %s", a.board.Name, total, a.updated.Format("15:04:05")))}
+
+	rows := a.cardRows()
+	visible := (a.width + 1) / (minLaneWidth + 1)
+	if visible > len(lanes) {
+		visible = len(lanes)
+	}
+	if visible < 1 {
+		visible = 1
+	}
+	if a.lane < a.first {
+		a.first = a.lane
+	}
+	if a.lane >= a.first+visible {
+		a.first = a.lane - visible + 1
+	}
+	if a.first > len(lanes)-visible {
+		a.first = len(lanes) - visible
+	}
+	if a.first < 0 {
+		a.first = 0
+	}
+	for i := range lanes {
+		a.offsets[i] = scrollOffset(a.offsets[i], len(lanes[i].Cards), rows, i == a.lane, a.card)
+	}
+
+	// Lanes share the width; the last visible one takes the remainder.
+	widths := make([]int, visible)
+	base := (a.width - (visible - 1)) / visible
+	for i := range widths {
+		widths[i] = base
+	}
+	if visible > 0 {
+		widths[visible-1] += a.width - (visible - 1) - base*visible
+	}
+
+	body := make([][]string, rows+2)
+	for v := 0; v < visible && a.first+v < len(lanes); v++ {
+		i := a.first + v
+		lane, width := lanes[i], widths[v]
+		header := termtext.Fit(fmt.Sprintf(" %s (%d)", lane.Name, len(lane.Cards)), width)
+		body[0] = append(body[0], sgr(header, joinAttrs("1", lane.Color)))
+		body[1] = append(body[1], sgr(strings.Repeat("─", width), "90"))
+		for r := 0; r < rows; r++ {
+			j := a.offsets[i] + r
+			if j >= len(lane.Cards) {
+				body[r+2] = append(body[r+2], strings.Repeat(" ", width))
+				continue
+			}
+			body[r+2] = append(body[r+2], cardCell(lane, lane.Cards[j], width, i == a.lane && j == a.card))
+		}
+	}
+	for _, cells := range body {
+		lines = append(lines, strings.Join(cells, sgr("│", "90")))
+	}
+	return append(lines, a.footer(boardHelp))
+}
+
+func cardCell(lane Lane, card fizzy.Card, width int, selected bool) string {
+	text := termtext.Fit(fmt.Sprintf(" #%d %s", card.Number, card.Title), width)
+	switch {
+	case selected:
+		return sgr(text, "7")
+	case lane.Kind == LaneDone:
+		return sgr(text, "2")
+	case card.Golden:
+		return sgr(text, "33")
+	}
+	return text
+}
+
+// scrollOffset keeps the selected card of the current lane on screen and
+// every other lane's offset within its cards.
+func scrollOffset(offset, count, rows int, current bool, selected int) int {
+	if current {
+		if selected < offset {
+			offset = selected
+		}
+		if selected >= offset+rows {
+			offset = selected - rows + 1
+		}
+	}
+	if offset > count-rows {
+		offset = count - rows
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset
+}
+
+func joinAttrs(attrs ...string) string {
+	var codes []string
+	for _, a := range attrs {
+		if a != "" {
+			codes = append(codes, a)
+		}
+	}
+	return strings.Join(codes, ";")
+}
+
+func (a *app) renderDetail() []string {
+	card := a.detail.Card
+	body := a.detailLines(a.width - 2)
+	rows := a.height - 2
+	if rows < 1 {
+		rows = 1
+	}
+	if max := len(body) - rows; a.detailScroll > max {
+		a.detailScroll = max
+	}
+	if a.detailScroll < 0 {
+		a.detailScroll = 0
+	}
+	lines := []string{a.titleBar(fmt.Sprintf("#%d %s", card.Number, card.Title))}
+	for r := 0; r < rows; r++ {
+		line := ""
+		if j := a.detailScroll + r; j < len(body) {
+			line = body[j]
+		}
+		lines = append(lines, termtext.Fit(" "+line, a.width))
+	}
+	return append(lines, a.footer(detailHelp))
+}
+
+func (a *app) detailLines(width int) []string {
+	card := a.detail.Card
+	column := a.board.Lanes[a.lane].Name
+	if card.Column != nil && card.Column.Name != "" {
+		column = card.Column.Name
+	}
+	header := fmt.Sprintf("Status: %s · Column: %s", card.Status, column)
+	if card.Board.Name != "" {
+		header += " · Board: " + card.Board.Name
+	}
+	lines := []string{header}
+	if len(card.Tags) > 0 {
+		lines = append(lines, "Tags: #"+strings.Join(card.Tags, " #"))
+	}
+	if card.Creator.Name != "" {
+		lines = append(lines, fmt.Sprintf("Created by %s on %s", card.Creator.Name, day(card.CreatedAt)))
+	}
+	if desc := strings.TrimSpace(card.Description); desc != "" {
+		lines = append(lines, "")
+		lines = append(lines, termtext.Wrap(desc, width)...)
+	}
+	if len(card.Steps) > 0 {
+		done := 0
+		for _, s := range card.Steps {
+			if s.Completed {
+				done++
+			}
+		}
+		lines = append(lines, "", sgr(fmt.Sprintf("Steps (%d/%d)", done, len(card.Steps)), "1"))
+		for _, s := range card.Steps {
+			box := "[ ] "
+			if s.Completed {
+				box = "[x] "
+			}
+			for i, l := range termtext.Wrap(s.Content, width-4) {
+				if i > 0 {
+					box = "    "
+				}
+				lines = append(lines, box+l)
+			}
+		}
+	}
+	lines = append(lines, "", sgr(fmt.Sprintf("Comments (%d)", len(a.detail.Comments)), "1"))
+	for _, c := range a.detail.Comments {
+		lines = append(lines, sgr(fmt.Sprintf("%s · %s", c.Creator.Name, day(c.CreatedAt)), "36"))
+		for _, l := range termtext.Wrap(strings.TrimSpace(c.Body.Plain), width-2) {
+			lines = append(lines, "  "+l)
+		}
+	}
+	return lines
+}
+
+func day(timestamp string) string {
+	if len(timestamp) >= 10 {
+		return timestamp[:10]
+	}
+	return timestamp
+}
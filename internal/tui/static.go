@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"fizzy-cli/internal/termtext"
+)
+
+// Text lays the board out as side-by-side lanes with wrapped card titles,
+// width cells wide. Lanes that don't fit continue in another band below.
+func Text(b *Board, width int, color bool) string {
+	style := func(s, attrs string) string {
+		if !color {
+			return s
+		}
+		return sgr(s, attrs)
+	}
+	perBand := min((width+3)/(minLaneWidth+3), len(b.Lanes))
+	if perBand < 1 {
+		perBand = 1
+	}
+	// Every band uses the same lane width so lanes line up.
+	laneWidth := max((width-3*(perBand-1))/perBand, 1)
+	out := &strings.Builder{}
+	fmt.Fprintln(out, style(b.Name, "1"))
+	for start := 0; start < len(b.Lanes); start += perBand {
+		band := b.Lanes[start:min(start+perBand, len(b.Lanes))]
+		columns := make([][]string, len(band))
+		height := 0
+		for i, lane := range band {
+			header := termtext.Fit(fmt.Sprintf("%s (%d)", lane.Name, len(lane.Cards)), laneWidth)
+			columns[i] = []string{style(header, joinAttrs("1", lane.Color)), style(strings.Repeat("─", laneWidth), "90")}
+			for _, card := range lane.Cards {
+				columns[i] = append(columns[i], cardLines(card.Number, card.Title, laneWidth)...)
+			}
+			height = max(height, len(columns[i]))
+		}
+		fmt.Fprintln(out)
+		for r := 0; r < height; r++ {
+			cells := make([]string, len(band))
+			for i := range band {
+				cells[i] = strings.Repeat(" ", laneWidth)
+				if r < len(columns[i]) {
+					cells[i] = columns[i][r]
+				}
+			}
+			fmt.Fprintln(out, strings.TrimRight(strings.Join(cells, "   "), " "))
+		}
+	}
+	return out.String()
+}
+
+// cardLines wraps "#N title" to width, indenting continuation lines under
+// the title.
+func cardLines(number int, title string, width int) []string {
+	prefix := fmt.Sprintf("#%d ", number)
+	indent := termtext.Width(prefix)
+	if width-indent < 8 {
+		indent = 0
+	}
+	wrapped := termtext.Wrap(title, width-indent)
+	lines := make([]string, 0, len(wrapped))
+	for i, line := range wrapped {
+		if i == 0 {
+			line = termtext.Fit(prefix+line, width)
+		} else {
+			line = termtext.Fit(strings.Repeat(" ", indent)+line, width)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Markdown renders the board with a heading per lane and its cards as a
+// list, for pasting into pull requests and notes.
+func Markdown(b *Board) string {
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "# %s\n", markdownText(b.Name))
+	for _, lane := range b.Lanes {
+		fmt.Fprintf(out, "\n## %s (%d)\n\n", markdownText(lane.Name), len(lane.Cards))
+		if len(lane.Cards) == 0 {
+			fmt.Fprintln(out, "_No cards._")
+			continue
+		}
+		for _, card := range lane.Cards {
+			title := markdownText(card.Title)
+			if card.URL != "" {
+				fmt.Fprintf(out, "- [#%d](%s) %s\n", card.Number, card.URL, title)
+			} else {
+				fmt.Fprintf(out, "- #%d %s\n", card.Number, title)
+			}
+		}
+	}
+	return out.String()
+}
+
+// markdownEscaper escapes the characters that would turn text into links,
+// emphasis, code, raw HTML, table cells or headings. # is escaped everywhere, so a
+// title can neither start a heading nor close one.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`, "`", "\\`", "#", `\#`, "|", `\|`, "<", `\<`,
+)
+
+// markdownText puts s on one line with its Markdown metacharacters escaped.
+func markdownText(s string) string {
+	return markdownEscaper.Replace(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// Terminal is the screen and keyboard the board is drawn on.
+type Terminal interface {
+	io.Reader
+	io.Writer
+	// Size returns the number of columns and rows.
+	Size() (int, int, error)
+	// Start prepares the terminal for full-screen drawing and returns a
+	// function that restores it.
+	Start() (func(), error)
+}
+
+var ErrNotTerminal = errors.New("the board view needs an interactive terminal")
+
+// DisableEcho turns off echo on the terminal f, to read a password, and
+// returns a function that turns it back on.
+func DisableEcho(f *os.File) (func(), error) {
+	if !isTerminal(int(f.Fd())) {
+		return nil, ErrNotTerminal
+	}
+	return disableEcho(int(f.Fd()))
+}
+
+// TTY is the process's controlling terminal.
+type TTY struct {
+	In  *os.File
+	Out *os.File
+}
+
+func NewTTY() *TTY {
+	return &TTY{In: os.Stdin, Out: os.Stdout}
+}
+
+func (t *TTY) Read(p []byte) (int, error) {
+	return t.In.Read(p)
+}
+
+func (t *TTY) Write(p []byte) (int, error) {
+	return t.Out.Write(p)
+}
+
+func (t *TTY) Size() (int, int, error) {
+	return terminalSize(int(t.Out.Fd()))
+}
+
+func (t *TTY) Start() (func(), error) {
+	if !isTerminal(int(t.In.Fd())) || !isTerminal(int(t.Out.Fd())) {
+		return nil, ErrNotTerminal
+	}
+	restore, err := makeRaw(int(t.In.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	// Switch to the alternate screen and hide the cursor.
+	io.WriteString(t.Out, "\x1b[?1049h\x1b[?25l")
+	return func() {
+		io.WriteString(t.Out, "\x1b[0m\x1b[?25h\x1b[?1049l")
+		restore()
+	}, nil
+}
@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"fizzy-cli/internal/termtext"
+)
+
+// VirtualTerminal is an in-memory Terminal for running the board headless,
+// in tests or scripts. Keys are read from Input and drawing is applied to a
+// grid of cells that Screen returns as text, with colors stripped.
+type VirtualTerminal struct {
+	Input  io.Reader
+	Width  int
+	Height int
+
+	mu      sync.Mutex
+	cells   [][]rune
+	row     int
+	col     int
+	pending []byte
+	frames  int
+}
+
+func NewVirtualTerminal(width, height int, input io.Reader) *VirtualTerminal {
+	t := &VirtualTerminal{Input: input, Width: width, Height: height}
+	t.clear()
+	return t
+}
+
+func (t *VirtualTerminal) Read(p []byte) (int, error) {
+	if t.Input == nil {
+		return 0, io.EOF
+	}
+	return t.Input.Read(p)
+}
+
+func (t *VirtualTerminal) Size() (int, int, error) {
+	return t.Width, t.Height, nil
+}
+
+func (t *VirtualTerminal) Start() (func(), error) {
+	return func() {}, nil
+}
+
+// Write interprets the subset of ANSI the board uses: printable text, \r,
+// \n, cursor home, clear screen and erase line. Other escape sequences,
+// including colors, are ignored.
+func (t *VirtualTerminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data := append(t.pending, p...)
+	t.pending = nil
+	for i := 0; i < len(data); {
+		switch c := data[i]; {
+		case c == 0x1b:
+			n := escapeLength(data[i:])
+			if n == 0 {
+				t.pending = append([]byte(nil), data[i:]...)
+				return len(p), nil
+			}
+			t.escape(string(data[i : i+n]))
+			i += n
+		case c == '\r':
+			t.col = 0
+			i++
+		case c == '\n':
+			t.row++
+			i++
+		case c < 0x20:
+			i++
+		default:
+			if !utf8.FullRune(data[i:]) {
+				t.pending = append([]byte(nil), data[i:]...)
+				return len(p), nil
+			}
+			r, size := utf8.DecodeRune(data[i:])
+			t.put(r)
+			i += size
+		}
+	}
+	return len(p), nil
+}
+
+// Screen returns the visible text, one line per row, without trailing
+// spaces.
+func (t *VirtualTerminal) Screen() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := make([]string, len(t.cells))
+	for i, row := range t.cells {
+		b := &strings.Builder{}
+		for _, r := range row {
+			if r != 0 {
+				b.WriteRune(r)
+			}
+		}
+		lines[i] = strings.TrimRight(b.String(), " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// Frames returns how many times the screen was redrawn.
+func (t *VirtualTerminal) Frames() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.frames
+}
+
+func (t *VirtualTerminal) clear() {
+	t.cells = make([][]rune, t.Height)
+	for i := range t.cells {
+		t.cells[i] = []rune(strings.Repeat(" ", t.Width))
+	}
+}
+
+func (t *VirtualTerminal) put(r rune) {
+	w := termtext.RuneWidth(r)
+	if t.row < 0 || t.row >= t.Height || t.col+w > t.Width {
+		return
+	}
+	t.cells[t.row][t.col] = r
+	// The second cell of a wide character holds no rune of its own.
+	for i := 1; i < w; i++ {
+		t.cells[t.row][t.col+i] = 0
+	}
+	t.col += w
+}
+
+func (t *VirtualTerminal) escape(seq string) {
+	switch {
+	case seq == "\x1b[H":
+		t.row, t.col = 0, 0
+		t.frames++
+	case seq == "\x1b[2J":
+		t.clear()
+	case seq == "\x1b[K" && t.row >= 0 && t.row < t.Height:
+		for i := t.col; i < t.Width; i++ {
+			t.cells[t.row][i] = ' '
+		}
+	}
+}
+
+// escapeLength returns the length of the escape sequence at the start of
+// b, or 0 if it is incomplete.
+func escapeLength(b []byte) int {
+	if len(b) < 2 {
+		return 0
+	}
+	if b[1] != '[' {
+		return 2
+	}
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package fizzy
+
+import "context"
+
+type BoardsService struct {
+	client *Client
+}
+
+type BoardCreateRequest struct {
+	Name               string `json:"name"`
+	AllAccess          bool   `json:"all_access"`
+	AutoPostponePeriod int    `json:"auto_postpone_period,omitempty"`
+	PublicDescription  string `json:"public_description,omitempty"`
+}
+
+type BoardUpdateRequest struct {
+	Name               string   `json:"name,omitempty"`
+	AllAccess          *bool    `json:"all_access,omitempty"`
+	AutoPostponePeriod int      `json:"auto_postpone_period,omitempty"`
+	PublicDescription  string   `json:"public_description,omitempty"`
+	UserIDs            []string `json:"user_ids,omitempty"`
+}
+
+func (s *BoardsService) List(ctx context.Context) ([]Board, *Response, error) {
+	path, err := s.client.accountPath("/boards")
+	if err != nil {
+		return nil, nil, err
+	}
+	var boards []Board
+	resp, err := s.client.get(ctx, path, nil, &boards)
+	if err != nil {
+		return nil, resp, err
+	}
+	return boards, resp, nil
+}
+
+func (s *BoardsService) Iter(opts *ListOptions) *Iterator[Board] {
+	path, err := s.client.accountPath("/boards")
+	if err != nil {
+		return errIterator[Board](err)
+	}
+	return newIterator[Board](s.client, path, nil, opts)
+}
+
+func (s *BoardsService) Get(ctx context.Context, id string) (*Board, *Response, error) {
+	path, err := s.client.accountPath("/boards/" + escape(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	var board Board
+	resp, err := s.client.get(ctx, path, nil, &board)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &board, resp, nil
+}
+
+func (s *BoardsService) Create(ctx context.Context, req *BoardCreateRequest) (*Response, error) {
+	path, err := s.client.accountPath("/boards")
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "POST", path, map[string]any{"board": req})
+}
+
+func (s *BoardsService) Update(ctx context.Context, id string, req *BoardUpdateRequest) (*Response, error) {
+	path, err := s.client.accountPath("/boards/" + escape(id))
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "PUT", path, map[string]any{"board": req})
+}
+
+func (s *BoardsService) Delete(ctx context.Context, id string) (*Response, error) {
+	path, err := s.client.accountPath("/boards/" + escape(id))
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "DELETE", path, nil)
+}
@@ -0,0 +1,216 @@
+package fizzy
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"fizzy-cli/internal/api"
+)
+
+type CardsService struct {
+	client *Client
+}
+
+type CardListOptions struct {
+	ListOptions
+	BoardIDs         []string
+	TagIDs           []string
+	AssigneeIDs      []string
+	CreatorIDs       []string
+	CloserIDs        []string
+	CardIDs          []string
+	Terms            []string
+	IndexedBy        string
+	SortedBy         string
+	AssignmentStatus string
+	Creation         string
+	Closure          string
+}
+
+func (o *CardListOptions) values() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+	addListParam(query, "board_ids[]", o.BoardIDs)
+	addListParam(query, "tag_ids[]", o.TagIDs)
+	addListParam(query, "assignee_ids[]", o.AssigneeIDs)
+	addListParam(query, "creator_ids[]", o.CreatorIDs)
+	addListParam(query, "closer_ids[]", o.CloserIDs)
+	addListParam(query, "card_ids[]", o.CardIDs)
+	addListParam(query, "terms[]", o.Terms)
+	setStringParam(query, "indexed_by", o.IndexedBy)
+	setStringParam(query, "sorted_by", o.SortedBy)
+	setStringParam(query, "assignment_status", o.AssignmentStatus)
+	setStringParam(query, "creation", o.Creation)
+	setStringParam(query, "closure", o.Closure)
+	return o.ListOptions.apply(query)
+}
+
+// CardRequest is used to create and update cards. Empty fields are left
+// unchanged on update, except Description: nil leaves it unchanged and an
+// empty string clears it. ImagePath, when set, uploads the file as the
+// card image using a multipart request.
+type CardRequest struct {
+	Title       string   `json:"title,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	TagIDs      []string `json:"tag_ids,omitempty"`
+	ImagePath   string   `json:"-"`
+}
+
+func (r *CardRequest) fields() map[string][]string {
+	fields := map[string][]string{}
+	if r.Title != "" {
+		fields["title"] = []string{r.Title}
+	}
+	if r.Description != nil {
+		fields["description"] = []string{*r.Description}
+	}
+	if r.Status != "" {
+		fields["status"] = []string{r.Status}
+	}
+	if len(r.TagIDs) > 0 {
+		fields["tag_ids[]"] = r.TagIDs
+	}
+	return fields
+}
+
+func (s *CardsService) List(ctx context.Context, opts *CardListOptions) ([]Card, *Response, error) {
+	path, err := s.client.accountPath("/cards")
+	if err != nil {
+		return nil, nil, err
+	}
+	var cards []Card
+	resp, err := s.client.get(ctx, path, opts.values(), &cards)
+	if err != nil {
+		return nil, resp, err
+	}
+	return cards, resp, nil
+}
+
+func (s *CardsService) Iter(opts *CardListOptions) *Iterator[Card] {
+	path, err := s.client.accountPath("/cards")
+	if err != nil {
+		return errIterator[Card](err)
+	}
+	var list *ListOptions
+	if opts != nil {
+		list = &opts.ListOptions
+	}
+	return newIterator[Card](s.client, path, opts.values(), list)
+}
+
+func (s *CardsService) Get(ctx context.Context, number int) (*Card, *Response, error) {
+	path, err := cardPath(s.client, number, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var card Card
+	resp, err := s.client.get(ctx, path, nil, &card)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &card, resp, nil
+}
+
+func (s *CardsService) Create(ctx context.Context, boardID string, req *CardRequest) (*Response, error) {
+	path, err := s.client.accountPath("/boards/" + escape(boardID) + "/cards")
+	if err != nil {
+		return nil, err
+	}
+	if req.ImagePath != "" {
+		return s.client.sendMultipart(ctx, "POST", path, "card", req.fields(), "image", req.ImagePath)
+	}
+	return s.client.send(ctx, "POST", path, map[string]any{"card": req})
+}
+
+func (s *CardsService) Update(ctx context.Context, number int, req *CardRequest) (*Response, error) {
+	path, err := cardPath(s.client, number, "")
+	if err != nil {
+		return nil, err
+	}
+	if req.ImagePath != "" {
+		return s.client.sendMultipart(ctx, "PUT", path, "card", req.fields(), "image", req.ImagePath)
+	}
+	return s.client.send(ctx, "PUT", path, map[string]any{"card": req})
+}
+
+func (s *CardsService) Delete(ctx context.Context, number int) (*Response, error) {
+	return s.action(ctx, number, "DELETE", "", nil)
+}
+
+func (s *CardsService) Close(ctx context.Context, number int) (*Response, error) {
+	return s.action(ctx, number, "POST", "/closure", nil)
+}
+
+func (s *CardsService) Reopen(ctx context.Context, number int) (*Response, error) {
+	return s.action(ctx, number, "DELETE", "/closure", nil)
+}
+
+func (s *CardsService) NotNow(ctx context.Context, number int) (*Response, error) {
+	return s.action(ctx, number, "POST", "/not_now", nil)
+}
+
+func (s *CardsService) Triage(ctx context.Context, number int, columnID string) (*Response, error) {
+	return s.action(ctx, number, "POST", "/triage", map[string]any{"column_id": strings.TrimSpace(columnID)})
+}
+
+func (s *CardsService) Untriage(ctx context.Context, number int) (*Response, error) {
+	return s.action(ctx, number, "DELETE", "/triage", nil)
+}
+
+func (s *CardsService) Watch(ctx context.Context, number int) (*Response, error) {
+	return s.action(ctx, number, "POST", "/watch", nil)
+}
+
+func (s *CardsService) Unwatch(ctx context.Context, number int) (*Response, error) {
+	return s.action(ctx, number, "DELETE", "/watch", nil)
+}
+
+// ToggleTag adds the tag to the card, or removes it if already present.
+func (s *CardsService) ToggleTag(ctx context.Context, number int, title string) (*Response, error) {
+	path, err := cardPath(s.client, number, "/taggings")
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]any{"tag_title": strings.TrimPrefix(strings.TrimSpace(title), "#")}
+	return s.client.send(ctx, "POST", path, payload)
+}
+
+// ToggleAssignment assigns the user to the card, or unassigns them if
+// already assigned.
+func (s *CardsService) ToggleAssignment(ctx context.Context, number int, assigneeID string) (*Response, error) {
+	path, err := cardPath(s.client, number, "/assignments")
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]any{"assignee_id": strings.TrimSpace(assigneeID)}
+	return s.client.send(ctx, "POST", path, payload)
+}
+
+// action sends a state-setting request. These are safe to retry even when
+// sent as POST.
+func (s *CardsService) action(ctx context.Context, number int, method, suffix string, payload any) (*Response, error) {
+	path, err := cardPath(s.client, number, suffix)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(api.WithIdempotent(ctx), method, path, payload)
+}
+
+func addListParam(values url.Values, key string, list []string) {
+	for _, v := range list {
+		if strings.TrimSpace(v) != "" {
+			values.Add(key, strings.TrimSpace(v))
+		}
+	}
+}
+
+func setStringParam(values url.Values, key, value string) {
+	value = strings.TrimSpace(value)
+	if value != "" {
+		values.Set(key, value)
+	}
+}
@@ -0,0 +1,70 @@
+package fizzy
+
+import "context"
+
+type ColumnsService struct {
+	client *Client
+}
+
+type ColumnRequest struct {
+	Name  string `json:"name,omitempty"`
+	Color string `json:"color,omitempty"`
+}
+
+func (s *ColumnsService) List(ctx context.Context, boardID string) ([]Column, *Response, error) {
+	path, err := s.client.accountPath("/boards/" + escape(boardID) + "/columns")
+	if err != nil {
+		return nil, nil, err
+	}
+	var columns []Column
+	resp, err := s.client.get(ctx, path, nil, &columns)
+	if err != nil {
+		return nil, resp, err
+	}
+	return columns, resp, nil
+}
+
+func (s *ColumnsService) Iter(boardID string, opts *ListOptions) *Iterator[Column] {
+	path, err := s.client.accountPath("/boards/" + escape(boardID) + "/columns")
+	if err != nil {
+		return errIterator[Column](err)
+	}
+	return newIterator[Column](s.client, path, nil, opts)
+}
+
+func (s *ColumnsService) Get(ctx context.Context, boardID, id string) (*Column, *Response, error) {
+	path, err := s.client.accountPath("/boards/" + escape(boardID) + "/columns/" + escape(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	var column Column
+	resp, err := s.client.get(ctx, path, nil, &column)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &column, resp, nil
+}
+
+func (s *ColumnsService) Create(ctx context.Context, boardID string, req *ColumnRequest) (*Response, error) {
+	path, err := s.client.accountPath("/boards/" + escape(boardID) + "/columns")
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "POST", path, map[string]any{"column": req})
+}
+
+func (s *ColumnsService) Update(ctx context.Context, boardID, id string, req *ColumnRequest) (*Response, error) {
+	path, err := s.client.accountPath("/boards/" + escape(boardID) + "/columns/" + escape(id))
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "PUT", path, map[string]any{"column": req})
+}
+
+func (s *ColumnsService) Delete(ctx context.Context, boardID, id string) (*Response, error) {
+	path, err := s.client.accountPath("/boards/" + escape(boardID) + "/columns/" + escape(id))
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "DELETE", path, nil)
+}
@@ -0,0 +1,69 @@
+package fizzy
+
+import "context"
+
+type CommentsService struct {
+	client *Client
+}
+
+type CommentRequest struct {
+	Body string `json:"body"`
+}
+
+func (s *CommentsService) List(ctx context.Context, cardNumber int) ([]Comment, *Response, error) {
+	path, err := cardPath(s.client, cardNumber, "/comments")
+	if err != nil {
+		return nil, nil, err
+	}
+	var comments []Comment
+	resp, err := s.client.get(ctx, path, nil, &comments)
+	if err != nil {
+		return nil, resp, err
+	}
+	return comments, resp, nil
+}
+
+func (s *CommentsService) Iter(cardNumber int, opts *ListOptions) *Iterator[Comment] {
+	path, err := cardPath(s.client, cardNumber, "/comments")
+	if err != nil {
+		return errIterator[Comment](err)
+	}
+	return newIterator[Comment](s.client, path, nil, opts)
+}
+
+func (s *CommentsService) Get(ctx context.Context, cardNumber int, id string) (*Comment, *Response, error) {
+	path, err := cardPath(s.client, cardNumber, "/comments/"+escape(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	var comment Comment
+	resp, err := s.client.get(ctx, path, nil, &comment)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &comment, resp, nil
+}
+
+func (s *CommentsService) Create(ctx context.Context, cardNumber int, req *CommentRequest) (*Response, error) {
+	path, err := cardPath(s.client, cardNumber, "/comments")
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "POST", path, map[string]any{"comment": req})
+}
+
+func (s *CommentsService) Update(ctx context.Context, cardNumber int, id string, req *CommentRequest) (*Response, error) {
+	path, err := cardPath(s.client, cardNumber, "/comments/"+escape(id))
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "PUT", path, map[string]any{"comment": req})
+}
+
+func (s *CommentsService) Delete(ctx context.Context, cardNumber int, id string) (*Response, error) {
+	path, err := cardPath(s.client, cardNumber, "/comments/"+escape(id))
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "DELETE", path, nil)
+}
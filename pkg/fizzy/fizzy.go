@@ -0,0 +1,154 @@
+// Package fizzy is a typed client for the Fizzy HTTP API.
+package fizzy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"fizzy-cli/internal/api"
+)
+
+type (
+	Transport   = api.Client
+	Response    = api.Response
+	APIError    = api.APIError
+	FieldError  = api.FieldError
+	RetryPolicy = api.RetryPolicy
+
+	Recorder        = api.Recorder
+	Replayer        = api.Replayer
+	ReplayMissError = api.ReplayMissError
+	DryRunError     = api.DryRunError
+)
+
+var ErrNoAccount = errors.New("fizzy: account slug is not set")
+
+func NewTransport(baseURL, token, sessionToken, agent string) *Transport {
+	return api.NewClient(baseURL, token, sessionToken, agent)
+}
+
+// NewRecorder returns an http.RoundTripper that records each exchange to
+// dir; install it as a Transport's HTTP.Transport.
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	return api.NewRecorder(dir, next)
+}
+
+// NewReplayer returns an http.RoundTripper that answers requests from a
+// directory written by a Recorder.
+func NewReplayer(dir string) (*Replayer, error) {
+	return api.NewReplayer(dir)
+}
+
+type Client struct {
+	transport *api.Client
+	Account   string
+
+	Identity      *IdentityService
+	Sessions      *SessionsService
+	Boards        *BoardsService
+	Cards         *CardsService
+	Comments      *CommentsService
+	Steps         *StepsService
+	Columns       *ColumnsService
+	Tags          *TagsService
+	Users         *UsersService
+	Notifications *NotificationsService
+}
+
+func NewClient(transport *Transport, account string) *Client {
+	c := &Client{transport: transport, Account: strings.Trim(account, "/")}
+	c.Identity = &IdentityService{client: c}
+	c.Sessions = &SessionsService{client: c}
+	c.Boards = &BoardsService{client: c}
+	c.Cards = &CardsService{client: c}
+	c.Comments = &CommentsService{client: c}
+	c.Steps = &StepsService{client: c}
+	c.Columns = &ColumnsService{client: c}
+	c.Tags = &TagsService{client: c}
+	c.Users = &UsersService{client: c}
+	c.Notifications = &NotificationsService{client: c}
+	return c
+}
+
+func (c *Client) Transport() *Transport {
+	return c.transport
+}
+
+// NextPage fetches the page linked as rel="next" from resp and decodes it
+// into v. It returns a nil Response when there is no next page.
+func (c *Client) NextPage(ctx context.Context, resp *Response, v any) (*Response, error) {
+	next := api.NextLink(resp.Headers)
+	if next == "" {
+		return nil, nil
+	}
+	return c.get(ctx, next, nil, v)
+}
+
+// Download fetches a URL the API links to, such as a card's ImageURL, with
+// the client's credentials. The response body holds the raw bytes.
+func (c *Client) Download(ctx context.Context, url string) (*Response, error) {
+	return c.transport.Do(ctx, "GET", url, nil, nil, "", map[string]string{"Accept": "*/*"})
+}
+
+func (c *Client) accountPath(path string) (string, error) {
+	if c.Account == "" {
+		return "", ErrNoAccount
+	}
+	return "/" + c.Account + path, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) (*Response, error) {
+	resp, err := c.transport.Do(ctx, "GET", path, query, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := decode(resp, v); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (c *Client) send(ctx context.Context, method, path string, payload any) (*Response, error) {
+	if payload == nil {
+		return c.transport.Do(ctx, method, path, nil, nil, "", nil)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return c.transport.Do(ctx, method, path, nil, bytes.NewReader(data), "application/json", nil)
+}
+
+func (c *Client) sendMultipart(ctx context.Context, method, path, rootKey string, fields map[string][]string, fileField, filePath string) (*Response, error) {
+	body, contentType, err := multipartBody(rootKey, fields, fileField, filePath)
+	if err != nil {
+		return nil, err
+	}
+	return c.transport.Do(ctx, method, path, nil, body, contentType, nil)
+}
+
+func mustJSON(v any) *bytes.Reader {
+	data, _ := json.Marshal(v)
+	return bytes.NewReader(data)
+}
+
+func decode(resp *Response, v any) error {
+	if v == nil || len(resp.Body) == 0 {
+		return nil
+	}
+	return json.NewDecoder(bytes.NewReader(resp.Body)).Decode(v)
+}
+
+func cardPath(c *Client, number int, suffix string) (string, error) {
+	return c.accountPath("/cards/" + strconv.Itoa(number) + suffix)
+}
+
+func escape(id string) string {
+	return url.PathEscape(strings.TrimSpace(id))
+}
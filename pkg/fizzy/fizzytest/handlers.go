@@ -0,0 +1,841 @@
+package fizzytest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// route dispatches a request below /{account}. parts holds the remaining
+// path segments.
+func (s *Server) route(r *request) {
+	p := r.parts
+	switch p[0] {
+	case "boards":
+		switch {
+		case len(p) == 1:
+			s.boardsCollection(r)
+		case len(p) == 2:
+			s.boardMember(r, p[1])
+		case len(p) == 3 && p[2] == "columns":
+			s.columnsCollection(r, p[1])
+		case len(p) == 4 && p[2] == "columns":
+			s.columnMember(r, p[1], p[3])
+		case len(p) == 3 && p[2] == "cards":
+			if r.Method != http.MethodPost {
+				r.methodNotAllowed()
+				return
+			}
+			s.createCard(r, p[1])
+		default:
+			r.notFound()
+		}
+	case "cards":
+		if len(p) == 1 {
+			if r.Method != http.MethodGet {
+				r.methodNotAllowed()
+				return
+			}
+			s.listCards(r)
+			return
+		}
+		number, err := strconv.Atoi(p[1])
+		c := s.card(number)
+		if err != nil || c == nil {
+			r.notFound()
+			return
+		}
+		s.cardRoute(r, c, p[2:])
+	case "tags":
+		if len(p) != 1 || r.Method != http.MethodGet {
+			r.notFound()
+			return
+		}
+		tags := make([]tagJSON, 0, len(s.tags))
+		for _, t := range s.tags {
+			tags = append(tags, tagJSON{ID: t.id, Title: t.title})
+		}
+		page(r, tags)
+	case "users":
+		switch len(p) {
+		case 1:
+			if r.Method != http.MethodGet {
+				r.methodNotAllowed()
+				return
+			}
+			var users []userJSON
+			for _, u := range s.users {
+				if u.active {
+					users = append(users, s.userJSON(r, u))
+				}
+			}
+			page(r, users)
+		case 2:
+			s.userMember(r, p[1])
+		default:
+			r.notFound()
+		}
+	case "notifications":
+		s.notificationsRoute(r, p[1:])
+	default:
+		r.notFound()
+	}
+}
+
+func (s *Server) identity(r *request) {
+	r.json(http.StatusOK, map[string]any{
+		"accounts": []map[string]any{{
+			"id":   "03f5account000000000000001",
+			"name": "Example Co",
+			"slug": "/" + Account,
+			"user": s.userJSON(r, s.me),
+		}},
+	})
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (s *Server) createSession(r *request) {
+	var body struct {
+		Email string `json:"email_address"`
+	}
+	if !r.decode(&body) {
+		return
+	}
+	if strings.TrimSpace(body.Email) == "" {
+		r.invalid("email_address", "can't be blank")
+		return
+	}
+	token := randomToken()
+	s.pending[token] = body.Email
+	r.json(http.StatusCreated, map[string]string{"pending_authentication_token": token})
+}
+
+func (s *Server) verifySession(r *request) {
+	var body struct {
+		Code string `json:"code"`
+	}
+	if !r.decode(&body) {
+		return
+	}
+	cookie, err := r.Cookie("pending_authentication_token")
+	if err != nil || s.pending[cookie.Value] == "" {
+		r.error(http.StatusUnauthorized, "no pending authentication")
+		return
+	}
+	code := s.MagicCode
+	if code == "" {
+		code = DefaultMagicCode
+	}
+	if body.Code != code {
+		r.error(http.StatusUnauthorized, "invalid code")
+		return
+	}
+	delete(s.pending, cookie.Value)
+	token := randomToken()
+	s.sessions[token] = true
+	r.json(http.StatusCreated, map[string]string{"session_token": token})
+}
+
+// Boards
+
+type boardParams struct {
+	Board struct {
+		Name               *string  `json:"name"`
+		AllAccess          *bool    `json:"all_access"`
+		AutoPostponePeriod *int     `json:"auto_postpone_period"`
+		PublicDescription  *string  `json:"public_description"`
+		UserIDs            []string `json:"user_ids"`
+	} `json:"board"`
+}
+
+func (s *Server) boardsCollection(r *request) {
+	switch r.Method {
+	case http.MethodGet:
+		boards := make([]boardJSON, 0, len(s.boards))
+		for _, b := range s.boards {
+			boards = append(boards, s.boardJSON(r, b))
+		}
+		page(r, boards)
+	case http.MethodPost:
+		var params boardParams
+		if !r.decode(&params) {
+			return
+		}
+		if params.Board.Name == nil || strings.TrimSpace(*params.Board.Name) == "" {
+			r.invalid("name", "can't be blank")
+			return
+		}
+		b := s.addBoard(*params.Board.Name)
+		applyBoard(b, params)
+		r.created("/boards/"+b.id, s.boardJSON(r, b))
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func applyBoard(b *board, params boardParams) {
+	p := params.Board
+	if p.Name != nil && strings.TrimSpace(*p.Name) != "" {
+		b.name = *p.Name
+	}
+	if p.AllAccess != nil {
+		b.allAccess = *p.AllAccess
+	}
+	if p.AutoPostponePeriod != nil {
+		b.autoPostponeDays = *p.AutoPostponePeriod
+	}
+	if p.PublicDescription != nil {
+		b.publicDescription = *p.PublicDescription
+	}
+	if p.UserIDs != nil {
+		b.userIDs = p.UserIDs
+	}
+}
+
+func (s *Server) boardMember(r *request, id string) {
+	b := s.board(id)
+	if b == nil {
+		r.notFound()
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		r.json(http.StatusOK, s.boardJSON(r, b))
+	case http.MethodPut, http.MethodPatch:
+		var params boardParams
+		if !r.decode(&params) {
+			return
+		}
+		applyBoard(b, params)
+		r.noContent()
+	case http.MethodDelete:
+		for i, existing := range s.boards {
+			if existing == b {
+				s.boards = append(s.boards[:i], s.boards[i+1:]...)
+				break
+			}
+		}
+		var cards []*card
+		for _, c := range s.cards {
+			if c.boardID != id {
+				cards = append(cards, c)
+			}
+		}
+		s.cards = cards
+		r.noContent()
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+// Columns
+
+type columnParams struct {
+	Column struct {
+		Name  *string `json:"name"`
+		Color *string `json:"color"`
+	} `json:"column"`
+}
+
+func (s *Server) columnsCollection(r *request, boardID string) {
+	if s.board(boardID) == nil {
+		r.notFound()
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		var columns []columnJSON
+		for _, c := range s.columns {
+			if c.boardID == boardID {
+				columns = append(columns, columnToJSON(c))
+			}
+		}
+		page(r, columns)
+	case http.MethodPost:
+		var params columnParams
+		if !r.decode(&params) {
+			return
+		}
+		if params.Column.Name == nil || strings.TrimSpace(*params.Column.Name) == "" {
+			r.invalid("name", "can't be blank")
+			return
+		}
+		color := "var(--color-card-default)"
+		if params.Column.Color != nil && *params.Column.Color != "" {
+			color = *params.Column.Color
+		}
+		c := s.addColumn(boardID, *params.Column.Name, color)
+		r.created("/boards/"+boardID+"/columns/"+c.id, columnToJSON(c))
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (s *Server) columnMember(r *request, boardID, id string) {
+	c := s.column(boardID, id)
+	if c == nil {
+		r.notFound()
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		r.json(http.StatusOK, columnToJSON(c))
+	case http.MethodPut, http.MethodPatch:
+		var params columnParams
+		if !r.decode(&params) {
+			return
+		}
+		if params.Column.Name != nil && *params.Column.Name != "" {
+			c.name = *params.Column.Name
+		}
+		if params.Column.Color != nil && *params.Column.Color != "" {
+			c.color = *params.Column.Color
+		}
+		r.noContent()
+	case http.MethodDelete:
+		for i, existing := range s.columns {
+			if existing == c {
+				s.columns = append(s.columns[:i], s.columns[i+1:]...)
+				break
+			}
+		}
+		// Cards in a deleted column go back to triage.
+		for _, card := range s.cards {
+			if card.columnID == id {
+				card.columnID = ""
+			}
+		}
+		r.noContent()
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+// Cards
+
+type cardParams struct {
+	Card struct {
+		Title       *string  `json:"title"`
+		Description *string  `json:"description"`
+		Status      *string  `json:"status"`
+		TagIDs      []string `json:"tag_ids"`
+		Image       *string  `json:"image"`
+	} `json:"card"`
+}
+
+func (s *Server) applyCard(r *request, c *card, params cardParams) bool {
+	p := params.Card
+	if p.Status != nil && *p.Status != "" {
+		if *p.Status != "drafted" && *p.Status != "published" {
+			r.invalid("status", "is not included in the list")
+			return false
+		}
+		c.status = *p.Status
+	}
+	for _, id := range p.TagIDs {
+		if s.tag(id) == nil {
+			r.invalid("tag_ids", "contains an unknown tag")
+			return false
+		}
+	}
+	if p.Title != nil && *p.Title != "" {
+		c.title = *p.Title
+	}
+	if p.Description != nil {
+		c.description = *p.Description
+	}
+	if p.TagIDs != nil {
+		c.tagIDs = p.TagIDs
+	}
+	if p.Image != nil {
+		c.image = *p.Image
+		c.imageData, c.imageType = nil, ""
+		if r.MultipartForm != nil {
+			if files := r.MultipartForm.File["card[image]"]; len(files) > 0 {
+				c.imageData, c.imageType = readUpload(files[0])
+			}
+		}
+	}
+	s.touch(c)
+	return true
+}
+
+func (s *Server) createCard(r *request, boardID string) {
+	if s.board(boardID) == nil {
+		r.notFound()
+		return
+	}
+	var params cardParams
+	if !r.decode(&params) {
+		return
+	}
+	if params.Card.Title == nil || strings.TrimSpace(*params.Card.Title) == "" {
+		r.invalid("title", "can't be blank")
+		return
+	}
+	c := s.addCard(boardID, *params.Card.Title)
+	if !s.applyCard(r, c, params) {
+		s.cards = s.cards[:len(s.cards)-1]
+		return
+	}
+	c.watcherIDs = []string{s.me.id}
+	r.created("/cards/"+strconv.Itoa(c.number), s.cardJSON(r, c))
+}
+
+func (s *Server) listCards(r *request) {
+	boards := query(r, "board_ids[]")
+	tags := query(r, "tag_ids[]")
+	assignees := query(r, "assignee_ids[]")
+	creators := query(r, "creator_ids[]")
+	closers := query(r, "closer_ids[]")
+	ids := query(r, "card_ids[]")
+	terms := query(r, "terms[]")
+	indexedBy := r.URL.Query().Get("indexed_by")
+	unassigned := r.URL.Query().Get("assignment_status") == "unassigned"
+
+	var cards []*card
+	for _, c := range s.cards {
+		switch indexedBy {
+		case "closed":
+			if !c.closed {
+				continue
+			}
+		case "not_now":
+			if !c.notNow || c.closed {
+				continue
+			}
+		case "golden":
+			if !c.golden || c.closed {
+				continue
+			}
+		case "stalled":
+			if c.closed || c.notNow || c.lastActiveAt.After(s.now().Add(-30*24*time.Hour)) {
+				continue
+			}
+		default:
+			if c.closed || c.notNow {
+				continue
+			}
+		}
+		if len(boards) > 0 && !contains(boards, c.boardID) ||
+			len(ids) > 0 && !contains(ids, c.id) ||
+			len(creators) > 0 && !contains(creators, c.creatorID) ||
+			len(closers) > 0 && !contains(closers, c.closerID) ||
+			unassigned && len(c.assigneeIDs) > 0 ||
+			!anyOf(tags, c.tagIDs) || !anyOf(assignees, c.assigneeIDs) ||
+			!matchesTerms(c, terms) {
+			continue
+		}
+		cards = append(cards, c)
+	}
+	sortCards(cards, r.URL.Query().Get("sorted_by"))
+	out := make([]cardJSON, 0, len(cards))
+	for _, c := range cards {
+		out = append(out, s.cardJSON(r, c))
+	}
+	page(r, out)
+}
+
+// anyOf reports whether have contains one of want, or want is empty.
+func anyOf(want, have []string) bool {
+	if len(want) == 0 {
+		return true
+	}
+	for _, w := range want {
+		if contains(have, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesTerms(c *card, terms []string) bool {
+	text := strings.ToLower(c.title + " " + c.description)
+	for _, t := range terms {
+		if !strings.Contains(text, strings.ToLower(t)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) cardRoute(r *request, c *card, rest []string) {
+	if len(rest) == 0 {
+		s.cardMember(r, c)
+		return
+	}
+	switch rest[0] {
+	case "comments":
+		s.commentsRoute(r, c, rest[1:])
+		return
+	case "steps":
+		s.stepsRoute(r, c, rest[1:])
+		return
+	case "image":
+		s.cardImage(r, c, rest[1:])
+		return
+	}
+	if len(rest) != 1 {
+		r.notFound()
+		return
+	}
+	switch rest[0] + " " + r.Method {
+	case "closure POST":
+		c.closed, c.closerID = true, s.me.id
+	case "closure DELETE":
+		c.closed, c.closerID = false, ""
+	case "not_now POST":
+		c.notNow, c.closed, c.columnID = true, false, ""
+	case "triage POST":
+		var body struct {
+			ColumnID string `json:"column_id"`
+		}
+		if !r.decode(&body) {
+			return
+		}
+		if s.column(c.boardID, body.ColumnID) == nil {
+			r.invalid("column_id", "is not a column of this board")
+			return
+		}
+		c.columnID, c.notNow = body.ColumnID, false
+	case "triage DELETE":
+		c.columnID, c.notNow = "", false
+	case "watch POST":
+		if !contains(c.watcherIDs, s.me.id) {
+			c.watcherIDs = append(c.watcherIDs, s.me.id)
+		}
+	case "watch DELETE":
+		c.watcherIDs = remove(c.watcherIDs, s.me.id)
+	case "taggings POST":
+		var body struct {
+			Title string `json:"tag_title"`
+		}
+		if !r.decode(&body) {
+			return
+		}
+		title := strings.TrimPrefix(strings.TrimSpace(body.Title), "#")
+		if title == "" {
+			r.invalid("tag_title", "can't be blank")
+			return
+		}
+		t := s.tagByTitle(title)
+		if t == nil {
+			t = s.addTag(title)
+		}
+		if contains(c.tagIDs, t.id) {
+			c.tagIDs = remove(c.tagIDs, t.id)
+		} else {
+			c.tagIDs = append(c.tagIDs, t.id)
+		}
+	case "assignments POST":
+		var body struct {
+			AssigneeID string `json:"assignee_id"`
+		}
+		if !r.decode(&body) {
+			return
+		}
+		if u := s.user(body.AssigneeID); u == nil || !u.active {
+			r.invalid("assignee_id", "is not a user of this account")
+			return
+		}
+		if contains(c.assigneeIDs, body.AssigneeID) {
+			c.assigneeIDs = remove(c.assigneeIDs, body.AssigneeID)
+		} else {
+			c.assigneeIDs = append(c.assigneeIDs, body.AssigneeID)
+		}
+	default:
+		switch rest[0] {
+		case "closure", "not_now", "triage", "watch", "taggings", "assignments":
+			r.methodNotAllowed()
+		default:
+			r.notFound()
+		}
+		return
+	}
+	s.touch(c)
+	r.noContent()
+}
+
+func (s *Server) cardMember(r *request, c *card) {
+	switch r.Method {
+	case http.MethodGet:
+		r.json(http.StatusOK, s.cardJSON(r, c))
+	case http.MethodPut, http.MethodPatch:
+		var params cardParams
+		if !r.decode(&params) {
+			return
+		}
+		if !s.applyCard(r, c, params) {
+			return
+		}
+		r.json(http.StatusOK, s.cardJSON(r, c))
+	case http.MethodDelete:
+		for i, existing := range s.cards {
+			if existing == c {
+				s.cards = append(s.cards[:i], s.cards[i+1:]...)
+				break
+			}
+		}
+		r.noContent()
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+// cardImage serves the bytes uploaded as the card's image.
+func (s *Server) cardImage(r *request, c *card, rest []string) {
+	if len(rest) != 0 || c.image == "" {
+		r.notFound()
+		return
+	}
+	if r.Method != http.MethodGet {
+		r.methodNotAllowed()
+		return
+	}
+	r.w.Header().Set("Content-Type", c.imageType)
+	r.w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", c.image))
+	r.w.WriteHeader(http.StatusOK)
+	r.w.Write(c.imageData)
+}
+
+// Comments
+
+type commentParams struct {
+	Comment struct {
+		Body string `json:"body"`
+	} `json:"comment"`
+}
+
+func (s *Server) commentsRoute(r *request, c *card, rest []string) {
+	switch len(rest) {
+	case 0:
+		switch r.Method {
+		case http.MethodGet:
+			var comments []commentJSON
+			for _, cm := range s.comments {
+				if cm.cardNumber == c.number {
+					comments = append(comments, s.commentJSON(r, cm))
+				}
+			}
+			page(r, comments)
+		case http.MethodPost:
+			var params commentParams
+			if !r.decode(&params) {
+				return
+			}
+			if strings.TrimSpace(params.Comment.Body) == "" {
+				r.invalid("body", "can't be blank")
+				return
+			}
+			cm := s.addComment(c.number, params.Comment.Body)
+			s.touch(c)
+			r.created("/cards/"+strconv.Itoa(c.number)+"/comments/"+cm.id, s.commentJSON(r, cm))
+		default:
+			r.methodNotAllowed()
+		}
+	case 1:
+		cm := s.comment(c.number, rest[0])
+		if cm == nil {
+			r.notFound()
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			r.json(http.StatusOK, s.commentJSON(r, cm))
+		case http.MethodPut, http.MethodPatch:
+			var params commentParams
+			if !r.decode(&params) {
+				return
+			}
+			if strings.TrimSpace(params.Comment.Body) == "" {
+				r.invalid("body", "can't be blank")
+				return
+			}
+			cm.body = params.Comment.Body
+			r.json(http.StatusOK, s.commentJSON(r, cm))
+		case http.MethodDelete:
+			for i, existing := range s.comments {
+				if existing == cm {
+					s.comments = append(s.comments[:i], s.comments[i+1:]...)
+					break
+				}
+			}
+			r.noContent()
+		default:
+			r.methodNotAllowed()
+		}
+	default:
+		r.notFound()
+	}
+}
+
+// Steps
+
+type stepParams struct {
+	Step struct {
+		Content   *string `json:"content"`
+		Completed *bool   `json:"completed"`
+	} `json:"step"`
+}
+
+func (s *Server) stepsRoute(r *request, c *card, rest []string) {
+	switch len(rest) {
+	case 0:
+		if r.Method != http.MethodPost {
+			r.methodNotAllowed()
+			return
+		}
+		var params stepParams
+		if !r.decode(&params) {
+			return
+		}
+		if params.Step.Content == nil || strings.TrimSpace(*params.Step.Content) == "" {
+			r.invalid("content", "can't be blank")
+			return
+		}
+		st := &step{id: s.newID(), content: *params.Step.Content}
+		if params.Step.Completed != nil {
+			st.completed = *params.Step.Completed
+		}
+		c.steps = append(c.steps, st)
+		s.touch(c)
+		r.created("/cards/"+strconv.Itoa(c.number)+"/steps/"+st.id, stepToJSON(st))
+	case 1:
+		index := -1
+		for i, st := range c.steps {
+			if st.id == rest[0] {
+				index = i
+			}
+		}
+		if index < 0 {
+			r.notFound()
+			return
+		}
+		st := c.steps[index]
+		switch r.Method {
+		case http.MethodGet:
+			r.json(http.StatusOK, stepToJSON(st))
+		case http.MethodPut, http.MethodPatch:
+			var params stepParams
+			if !r.decode(&params) {
+				return
+			}
+			if params.Step.Content != nil && *params.Step.Content != "" {
+				st.content = *params.Step.Content
+			}
+			if params.Step.Completed != nil {
+				st.completed = *params.Step.Completed
+			}
+			s.touch(c)
+			r.json(http.StatusOK, stepToJSON(st))
+		case http.MethodDelete:
+			c.steps = append(c.steps[:index], c.steps[index+1:]...)
+			r.noContent()
+		default:
+			r.methodNotAllowed()
+		}
+	default:
+		r.notFound()
+	}
+}
+
+// Users
+
+func (s *Server) userMember(r *request, id string) {
+	u := s.user(id)
+	if u == nil || !u.active {
+		r.notFound()
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		r.json(http.StatusOK, s.userJSON(r, u))
+	case http.MethodPut, http.MethodPatch:
+		var params struct {
+			User struct {
+				Name   string `json:"name"`
+				Avatar string `json:"avatar"`
+			} `json:"user"`
+		}
+		if !r.decode(&params) {
+			return
+		}
+		if params.User.Name != "" {
+			u.name = params.User.Name
+		}
+		if params.User.Avatar != "" {
+			u.avatar = params.User.Avatar
+		}
+		r.noContent()
+	case http.MethodDelete:
+		if u == s.me {
+			r.error(http.StatusForbidden, "you cannot deactivate yourself")
+			return
+		}
+		u.active = false
+		r.noContent()
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+// Notifications
+
+func (s *Server) notificationsRoute(r *request, rest []string) {
+	switch {
+	case len(rest) == 0:
+		if r.Method != http.MethodGet {
+			r.methodNotAllowed()
+			return
+		}
+		unread := r.URL.Query().Get("unread") == "true"
+		var out []notificationJSON
+		for i := len(s.notifications) - 1; i >= 0; i-- {
+			if n := s.notifications[i]; !unread || !n.read {
+				out = append(out, s.notificationJSON(r, n))
+			}
+		}
+		page(r, out)
+	case len(rest) == 1 && rest[0] == "bulk_reading":
+		if r.Method != http.MethodPost {
+			r.methodNotAllowed()
+			return
+		}
+		for _, n := range s.notifications {
+			n.read = true
+		}
+		r.noContent()
+	case len(rest) == 2 && rest[1] == "reading":
+		var n *notification
+		for _, existing := range s.notifications {
+			if existing.id == rest[0] {
+				n = existing
+			}
+		}
+		if n == nil {
+			r.notFound()
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			n.read = true
+		case http.MethodDelete:
+			n.read = false
+		default:
+			r.methodNotAllowed()
+			return
+		}
+		r.noContent()
+	default:
+		r.notFound()
+	}
+}
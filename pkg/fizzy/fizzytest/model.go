@@ -0,0 +1,443 @@
+package fizzytest
+
+import (
+	"html"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type user struct {
+	id        string
+	name      string
+	email     string
+	role      string
+	active    bool
+	avatar    string
+	createdAt time.Time
+}
+
+type board struct {
+	id                string
+	name              string
+	allAccess         bool
+	autoPostponeDays  int
+	publicDescription string
+	creatorID         string
+	userIDs           []string
+	createdAt         time.Time
+}
+
+type column struct {
+	id        string
+	boardID   string
+	name      string
+	color     string
+	createdAt time.Time
+}
+
+type card struct {
+	id           string
+	number       int
+	boardID      string
+	columnID     string
+	title        string
+	description  string
+	status       string
+	image        string
+	imageData    []byte
+	imageType    string
+	tagIDs       []string
+	assigneeIDs  []string
+	watcherIDs   []string
+	steps        []*step
+	golden       bool
+	closed       bool
+	notNow       bool
+	closerID     string
+	creatorID    string
+	createdAt    time.Time
+	lastActiveAt time.Time
+}
+
+type step struct {
+	id        string
+	content   string
+	completed bool
+}
+
+type comment struct {
+	id         string
+	cardNumber int
+	body       string
+	creatorID  string
+	createdAt  time.Time
+}
+
+type tag struct {
+	id    string
+	title string
+}
+
+type notification struct {
+	id         string
+	read       bool
+	title      string
+	body       string
+	cardNumber int
+	createdAt  time.Time
+}
+
+// JSON shapes, in the field order the API uses.
+
+type userJSON struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Active    bool   `json:"active"`
+	Email     string `json:"email_address"`
+	CreatedAt string `json:"created_at"`
+	URL       string `json:"url"`
+}
+
+type boardJSON struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	AllAccess bool     `json:"all_access"`
+	CreatedAt string   `json:"created_at"`
+	Creator   userJSON `json:"creator"`
+	URL       string   `json:"url"`
+}
+
+type columnJSON struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	CreatedAt string `json:"created_at"`
+}
+
+type stepJSON struct {
+	ID        string `json:"id"`
+	Content   string `json:"content"`
+	Completed bool   `json:"completed"`
+}
+
+type cardJSON struct {
+	ID           string      `json:"id"`
+	Number       int         `json:"number"`
+	Title        string      `json:"title"`
+	Status       string      `json:"status"`
+	Description  string      `json:"description"`
+	ImageURL     *string     `json:"image_url"`
+	Tags         []string    `json:"tags"`
+	Golden       bool        `json:"golden"`
+	Closed       bool        `json:"closed"`
+	LastActiveAt string      `json:"last_active_at"`
+	CreatedAt    string      `json:"created_at"`
+	URL          string      `json:"url"`
+	Board        boardJSON   `json:"board"`
+	Column       *columnJSON `json:"column,omitempty"`
+	Creator      userJSON    `json:"creator"`
+	Assignees    []userJSON  `json:"assignees"`
+	Steps        []stepJSON  `json:"steps"`
+}
+
+type commentJSON struct {
+	ID        string          `json:"id"`
+	CreatedAt string          `json:"created_at"`
+	Body      commentBodyJSON `json:"body"`
+	Creator   userJSON        `json:"creator"`
+	URL       string          `json:"url"`
+}
+
+type commentBodyJSON struct {
+	Plain string `json:"plain_text"`
+	HTML  string `json:"html"`
+}
+
+type tagJSON struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type notificationJSON struct {
+	ID        string               `json:"id"`
+	Read      bool                 `json:"read"`
+	CreatedAt string               `json:"created_at"`
+	Title     string               `json:"title"`
+	Body      string               `json:"body"`
+	Card      notificationCardJSON `json:"card"`
+}
+
+type notificationCardJSON struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (s *Server) userJSON(r *request, u *user) userJSON {
+	if u == nil {
+		return userJSON{}
+	}
+	return userJSON{ID: u.id, Name: u.name, Role: u.role, Active: u.active, Email: u.email, CreatedAt: timestamp(u.createdAt), URL: r.accountURL("/users/" + u.id)}
+}
+
+func (s *Server) boardJSON(r *request, b *board) boardJSON {
+	return boardJSON{ID: b.id, Name: b.name, AllAccess: b.allAccess, CreatedAt: timestamp(b.createdAt), Creator: s.userJSON(r, s.user(b.creatorID)), URL: r.accountURL("/boards/" + b.id)}
+}
+
+func columnToJSON(c *column) columnJSON {
+	return columnJSON{ID: c.id, Name: c.name, Color: c.color, CreatedAt: timestamp(c.createdAt)}
+}
+
+func stepToJSON(st *step) stepJSON {
+	return stepJSON{ID: st.id, Content: st.content, Completed: st.completed}
+}
+
+func (s *Server) cardJSON(r *request, c *card) cardJSON {
+	out := cardJSON{
+		ID:           c.id,
+		Number:       c.number,
+		Title:        c.title,
+		Status:       c.status,
+		Description:  c.description,
+		Tags:         []string{},
+		Golden:       c.golden,
+		Closed:       c.closed,
+		LastActiveAt: timestamp(c.lastActiveAt),
+		CreatedAt:    timestamp(c.createdAt),
+		URL:          r.accountURL("/cards/" + strconv.Itoa(c.number)),
+		Creator:      s.userJSON(r, s.user(c.creatorID)),
+		Assignees:    []userJSON{},
+		Steps:        []stepJSON{},
+	}
+	if b := s.board(c.boardID); b != nil {
+		out.Board = s.boardJSON(r, b)
+	}
+	if c.image != "" {
+		u := r.accountURL("/cards/" + strconv.Itoa(c.number) + "/image")
+		out.ImageURL = &u
+	}
+	if col := s.column(c.boardID, c.columnID); col != nil {
+		j := columnToJSON(col)
+		out.Column = &j
+	}
+	for _, id := range c.tagIDs {
+		if t := s.tag(id); t != nil {
+			out.Tags = append(out.Tags, t.title)
+		}
+	}
+	for _, id := range c.assigneeIDs {
+		out.Assignees = append(out.Assignees, s.userJSON(r, s.user(id)))
+	}
+	for _, st := range c.steps {
+		out.Steps = append(out.Steps, stepToJSON(st))
+	}
+	return out
+}
+
+func (s *Server) commentJSON(r *request, c *comment) commentJSON {
+	return commentJSON{
+		ID:        c.id,
+		CreatedAt: timestamp(c.createdAt),
+		Body:      commentBodyJSON{Plain: c.body, HTML: "<p>" + html.EscapeString(c.body) + "</p>"},
+		Creator:   s.userJSON(r, s.user(c.creatorID)),
+		URL:       r.accountURL("/cards/" + strconv.Itoa(c.cardNumber) + "/comments/" + c.id),
+	}
+}
+
+func (s *Server) notificationJSON(r *request, n *notification) notificationJSON {
+	out := notificationJSON{ID: n.id, Read: n.read, CreatedAt: timestamp(n.createdAt), Title: n.title, Body: n.body}
+	if c := s.card(n.cardNumber); c != nil {
+		out.Card = notificationCardJSON{ID: c.id, Title: c.title, URL: r.accountURL("/cards/" + strconv.Itoa(c.number))}
+	}
+	return out
+}
+
+// Lookups. They return nil when nothing matches.
+
+func (s *Server) user(id string) *user {
+	for _, u := range s.users {
+		if u.id == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) board(id string) *board {
+	for _, b := range s.boards {
+		if b.id == id {
+			return b
+		}
+	}
+	return nil
+}
+
+func (s *Server) column(boardID, id string) *column {
+	for _, c := range s.columns {
+		if c.id == id && c.boardID == boardID {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) card(number int) *card {
+	for _, c := range s.cards {
+		if c.number == number {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) tag(id string) *tag {
+	for _, t := range s.tags {
+		if t.id == id {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *Server) tagByTitle(title string) *tag {
+	for _, t := range s.tags {
+		if t.title == title {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *Server) comment(cardNumber int, id string) *comment {
+	for _, c := range s.comments {
+		if c.id == id && c.cardNumber == cardNumber {
+			return c
+		}
+	}
+	return nil
+}
+
+// Adders, used by the handlers and to set up state in tests.
+
+func (s *Server) addUser(name, email, role string) *user {
+	u := &user{id: s.newID(), name: name, email: email, role: role, active: true, createdAt: s.now()}
+	s.users = append(s.users, u)
+	return u
+}
+
+// AddUser adds an active member to the account and returns their ID.
+func (s *Server) AddUser(name, email string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addUser(name, email, "member").id
+}
+
+func (s *Server) addBoard(name string) *board {
+	b := &board{id: s.newID(), name: name, allAccess: true, creatorID: s.me.id, createdAt: s.now()}
+	s.boards = append(s.boards, b)
+	return b
+}
+
+// AddBoard adds a board and returns its ID.
+func (s *Server) AddBoard(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addBoard(name).id
+}
+
+func (s *Server) addColumn(boardID, name, color string) *column {
+	c := &column{id: s.newID(), boardID: boardID, name: name, color: color, createdAt: s.now()}
+	s.columns = append(s.columns, c)
+	return c
+}
+
+// AddColumn adds a column to a board and returns its ID.
+func (s *Server) AddColumn(boardID, name, color string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addColumn(boardID, name, color).id
+}
+
+func (s *Server) addCard(boardID, title string) *card {
+	s.cardSeq++
+	now := s.now()
+	c := &card{id: s.newID(), number: s.cardSeq, boardID: boardID, title: title, status: "published", creatorID: s.me.id, createdAt: now, lastActiveAt: now}
+	s.cards = append(s.cards, c)
+	return c
+}
+
+// AddCard adds a published, untriaged card to a board and returns its
+// number.
+func (s *Server) AddCard(boardID, title string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addCard(boardID, title).number
+}
+
+func (s *Server) addComment(cardNumber int, body string) *comment {
+	c := &comment{id: s.newID(), cardNumber: cardNumber, body: body, creatorID: s.me.id, createdAt: s.now()}
+	s.comments = append(s.comments, c)
+	return c
+}
+
+// AddComment adds a comment to a card and returns its ID.
+func (s *Server) AddComment(cardNumber int, body string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addComment(cardNumber, body).id
+}
+
+func (s *Server) addTag(title string) *tag {
+	t := &tag{id: s.newID(), title: title}
+	s.tags = append(s.tags, t)
+	return t
+}
+
+// AddTag adds a tag and returns its ID.
+func (s *Server) AddTag(title string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addTag(title).id
+}
+
+func (s *Server) addNotification(cardNumber int, title, body string) *notification {
+	n := &notification{id: s.newID(), cardNumber: cardNumber, title: title, body: body, createdAt: s.now()}
+	s.notifications = append(s.notifications, n)
+	return n
+}
+
+// AddNotification adds an unread notification about a card and returns its
+// ID.
+func (s *Server) AddNotification(cardNumber int, title, body string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addNotification(cardNumber, title, body).id
+}
+
+func (s *Server) touch(c *card) {
+	c.lastActiveAt = s.now()
+}
+
+// sortCards orders cards like the sorted_by parameter: latest activity
+// first by default, or by creation for newest and oldest.
+func sortCards(cards []*card, by string) {
+	switch by {
+	case "newest":
+		sort.SliceStable(cards, func(i, j int) bool { return cards[i].number > cards[j].number })
+	case "oldest":
+		sort.SliceStable(cards, func(i, j int) bool { return cards[i].number < cards[j].number })
+	default:
+		sort.SliceStable(cards, func(i, j int) bool {
+			if cards[i].lastActiveAt.Equal(cards[j].lastActiveAt) {
+				return cards[i].number > cards[j].number
+			}
+			return cards[i].lastActiveAt.After(cards[j].lastActiveAt)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package fizzytest
+
+import "time"
+
+// Seed fills the fake with a small demo account: a second user, a Roadmap
+// board with three columns, tagged cards in every state, steps, comments
+// and notifications.
+func (s *Server) Seed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sam := s.addUser("Sam Lee", "sam@example.com", "member")
+	bug := s.addTag("bug")
+	feature := s.addTag("feature")
+	design := s.addTag("design")
+
+	roadmap := s.addBoard("Roadmap")
+	doing := s.addColumn(roadmap.id, "In Progress", "var(--color-card-1)")
+	review := s.addColumn(roadmap.id, "Review", "var(--color-card-3)")
+	s.addColumn(roadmap.id, "Blocked", "var(--color-card-4)")
+
+	login := s.addCard(roadmap.id, "Add dark mode")
+	login.description = "Follow the system setting and add a toggle in preferences."
+	login.columnID = doing.id
+	login.tagIDs = []string{feature.id, design.id}
+	login.assigneeIDs = []string{s.me.id}
+	login.steps = []*step{
+		{id: s.newID(), content: "Pick the palette", completed: true},
+		{id: s.newID(), content: "Theme the board view"},
+		{id: s.newID(), content: "Add the toggle"},
+	}
+	s.addComment(login.number, "The palette is in the design doc.")
+
+	crash := s.addCard(roadmap.id, "Crash when a column is deleted")
+	crash.columnID = review.id
+	crash.tagIDs = []string{bug.id}
+	crash.assigneeIDs = []string{sam.id}
+	crash.golden = true
+	s.addComment(crash.number, "Reproduced on the latest build.")
+	s.addComment(crash.number, "Fix is up for review.")
+
+	idea := s.addCard(roadmap.id, "Keyboard shortcuts for triage")
+	idea.status = "drafted"
+
+	later := s.addCard(roadmap.id, "Export boards as PDF")
+	later.notNow = true
+
+	done := s.addCard(roadmap.id, "Set up the project")
+	done.closed, done.closerID = true, s.me.id
+	done.columnID = doing.id
+
+	ops := s.addBoard("Operations")
+	s.addColumn(ops.id, "Doing", "var(--color-card-2)")
+	s.addCard(ops.id, "Rotate the API keys")
+
+	// Spread activity over the past days so sorting has something to do.
+	for i, c := range s.cards {
+		c.createdAt = c.createdAt.Add(-time.Duration(len(s.cards)-i) * 24 * time.Hour)
+		c.lastActiveAt = c.createdAt.Add(time.Duration(i%3) * 6 * time.Hour)
+	}
+
+	s.addNotification(crash.number, "Sam Lee commented", "Fix is up for review.")
+	read := s.addNotification(login.number, "You were assigned", "Add dark mode")
+	read.read = true
+}
@@ -0,0 +1,324 @@
+// Package fizzytest is an in-memory fake of the Fizzy API, for tests and
+// offline demos. It implements every endpoint the fizzy package calls,
+// including Link pagination and Location headers on create.
+//
+//	fake := fizzytest.New()
+//	fake.Seed()
+//	srv := httptest.NewServer(fake)
+//	client := fizzy.NewClient(fizzy.NewTransport(srv.URL, "token", "", ""), fizzytest.Account)
+package fizzytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Account is the slug of the fake's only account.
+const Account = "897362094"
+
+// DefaultMagicCode is the code that completes a magic-link login.
+const DefaultMagicCode = "123456"
+
+// Server serves the fake API. Its fields may be changed before it starts
+// serving requests.
+type Server struct {
+	// Token is the only bearer token accepted. Empty accepts any token.
+	Token string
+	// MagicCode completes a magic-link login; defaults to DefaultMagicCode.
+	MagicCode string
+	// PageSize is the default page length of list endpoints.
+	PageSize int
+	// Now is the clock used for timestamps; defaults to time.Now.
+	Now func() time.Time
+
+	mu            sync.Mutex
+	seq           int
+	cardSeq       int
+	me            *user
+	users         []*user
+	boards        []*board
+	columns       []*column
+	cards         []*card
+	comments      []*comment
+	tags          []*tag
+	notifications []*notification
+	pending       map[string]string
+	sessions      map[string]bool
+}
+
+// New returns a fake with one account and its owner, and nothing else.
+func New() *Server {
+	s := &Server{
+		MagicCode: DefaultMagicCode,
+		PageSize:  25,
+		Now:       time.Now,
+		pending:   map[string]string{},
+		sessions:  map[string]bool{},
+	}
+	s.me = s.addUser("Jane Doe", "jane@example.com", "owner")
+	return s
+}
+
+// Me returns the ID of the account owner, who makes every request.
+func (s *Server) Me() string {
+	return s.me.id
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if last := len(parts) - 1; last >= 0 {
+		parts[last] = strings.TrimSuffix(parts[last], ".json")
+	}
+	req := &request{Request: r, w: w, s: s, parts: parts}
+
+	switch {
+	case r.URL.Path == "/session" && r.Method == http.MethodPost:
+		s.createSession(req)
+		return
+	case r.URL.Path == "/session/magic_link" && r.Method == http.MethodPost:
+		s.verifySession(req)
+		return
+	}
+	if !s.authorized(r) {
+		req.error(http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if r.URL.Path == "/my/identity" {
+		if r.Method != http.MethodGet {
+			req.error(http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		s.identity(req)
+		return
+	}
+	if len(parts) < 2 || parts[0] != Account {
+		req.error(http.StatusNotFound, "not found")
+		return
+	}
+	req.parts = parts[1:]
+	s.route(req)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token := strings.TrimPrefix(auth, "Bearer ")
+		return token != "" && (s.Token == "" || token == s.Token)
+	}
+	if c, err := r.Cookie("session_token"); err == nil {
+		return s.sessions[c.Value]
+	}
+	return false
+}
+
+func (s *Server) now() time.Time {
+	if s.Now == nil {
+		return time.Now().UTC()
+	}
+	return s.Now().UTC()
+}
+
+// newID returns a 25-character ID like the ones Fizzy uses.
+func (s *Server) newID() string {
+	s.seq++
+	n := strconv.FormatInt(int64(s.seq), 36)
+	return "03f5" + strings.Repeat("0", 21-len(n)) + n
+}
+
+type request struct {
+	*http.Request
+	w     http.ResponseWriter
+	s     *Server
+	parts []string
+}
+
+func (r *request) url(path string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + path
+}
+
+func (r *request) accountURL(path string) string {
+	return r.url("/" + Account + path)
+}
+
+func (r *request) json(status int, v any) {
+	r.w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	r.w.WriteHeader(status)
+	json.NewEncoder(r.w).Encode(v)
+}
+
+func (r *request) error(status int, message string) {
+	r.json(status, map[string]string{"error": message})
+}
+
+// invalid answers 422 with Rails-style field errors.
+func (r *request) invalid(field, message string) {
+	r.json(http.StatusUnprocessableEntity, map[string][]string{field: {message}})
+}
+
+func (r *request) created(path string, v any) {
+	r.w.Header().Set("Location", r.accountURL(path))
+	r.json(http.StatusCreated, v)
+}
+
+func (r *request) noContent() {
+	r.w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *request) notFound() {
+	r.error(http.StatusNotFound, "not found")
+}
+
+func (r *request) methodNotAllowed() {
+	r.error(http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// page writes one page of items, with a rel="next" Link header when more
+// follow. The page is chosen by the page and per_page parameters.
+func page[T any](r *request, items []T) {
+	size := r.s.PageSize
+	if n, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil && n > 0 {
+		size = n
+	}
+	if size <= 0 {
+		size = len(items)
+	}
+	number := 1
+	if n, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && n > 0 {
+		number = n
+	}
+	start := (number - 1) * size
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + size
+	if end > len(items) {
+		end = len(items)
+	}
+	if end < len(items) {
+		query := r.URL.Query()
+		query.Set("page", strconv.Itoa(number+1))
+		next := r.url(r.URL.Path + "?" + query.Encode())
+		r.w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next))
+	}
+	out := items[start:end]
+	if out == nil {
+		out = []T{}
+	}
+	r.json(http.StatusOK, out)
+}
+
+// decode reads the request body into v. JSON bodies are decoded as is;
+// multipart forms with fields like card[title] and card[tag_ids][] are
+// converted to the same shape first.
+func (r *request) decode(v any) bool {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			r.error(http.StatusBadRequest, err.Error())
+			return false
+		}
+		data, _ := json.Marshal(formDocument(r.MultipartForm.Value, r.MultipartForm.File))
+		if err := json.Unmarshal(data, v); err != nil {
+			r.error(http.StatusBadRequest, err.Error())
+			return false
+		}
+		return true
+	}
+	if r.ContentLength == 0 {
+		return true
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		r.error(http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// formDocument turns root[key] and root[key][] form fields into
+// {"root": {"key": value}}. Uploaded files become their file names.
+func formDocument(values map[string][]string, files map[string][]*multipart.FileHeader) map[string]map[string]any {
+	doc := map[string]map[string]any{}
+	set := func(name string, vals []string) {
+		open := strings.Index(name, "[")
+		if open < 0 || !strings.HasSuffix(name, "]") {
+			return
+		}
+		root := name[:open]
+		key := strings.TrimSuffix(name[open+1:], "]")
+		list := strings.HasSuffix(key, "][")
+		key = strings.TrimSuffix(key, "][")
+		if doc[root] == nil {
+			doc[root] = map[string]any{}
+		}
+		if list {
+			doc[root][key] = vals
+		} else if len(vals) > 0 {
+			doc[root][key] = vals[0]
+		}
+	}
+	for name, vals := range values {
+		set(name, vals)
+	}
+	for name, fs := range files {
+		if len(fs) > 0 {
+			set(name, []string{fs[0].Filename})
+		}
+	}
+	return doc
+}
+
+// readUpload returns an uploaded file's bytes and content type.
+func readUpload(fh *multipart.FileHeader) ([]byte, string) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, ""
+	}
+	defer f.Close()
+	data, _ := io.ReadAll(f)
+	contentType := fh.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType
+}
+
+func query(r *request, key string) []string {
+	var out []string
+	for _, v := range r.URL.Query()[key] {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(list []string, v string) []string {
+	out := list[:0]
+	for _, item := range list {
+		if item != v {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package fizzy
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type IdentityService struct {
+	client *Client
+}
+
+func (s *IdentityService) Get(ctx context.Context) (*Identity, *Response, error) {
+	var id Identity
+	resp, err := s.client.get(ctx, "/my/identity", nil, &id)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &id, resp, nil
+}
+
+type SessionsService struct {
+	client *Client
+}
+
+type PendingAuthentication struct {
+	Token string `json:"pending_authentication_token"`
+}
+
+type Session struct {
+	Token string `json:"session_token"`
+}
+
+// Create starts a magic-link login; Fizzy emails a code to the address.
+func (s *SessionsService) Create(ctx context.Context, email string) (*PendingAuthentication, *Response, error) {
+	resp, err := s.client.send(ctx, "POST", "/session", map[string]any{"email_address": email})
+	if err != nil {
+		return nil, resp, err
+	}
+	var pending PendingAuthentication
+	if err := decode(resp, &pending); err != nil {
+		return nil, resp, err
+	}
+	if strings.TrimSpace(pending.Token) == "" {
+		return nil, resp, errors.New("missing pending_authentication_token in response")
+	}
+	return &pending, resp, nil
+}
+
+// Verify exchanges a magic-link code for a session token.
+func (s *SessionsService) Verify(ctx context.Context, pending *PendingAuthentication, code string) (*Session, *Response, error) {
+	data := mustJSON(map[string]any{"code": strings.TrimSpace(code)})
+	headers := map[string]string{"Cookie": "pending_authentication_token=" + pending.Token}
+	resp, err := s.client.transport.Do(ctx, "POST", "/session/magic_link", nil, data, "application/json", headers)
+	if err != nil {
+		return nil, resp, err
+	}
+	var session Session
+	if err := decode(resp, &session); err != nil {
+		return nil, resp, err
+	}
+	if strings.TrimSpace(session.Token) == "" {
+		return nil, resp, errors.New("missing session_token in response")
+	}
+	return &session, resp, nil
+}
@@ -0,0 +1,123 @@
+package fizzy
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"fizzy-cli/internal/api"
+)
+
+// ListOptions controls how list endpoints are paged. PageSize is sent as
+// the per_page parameter, Limit stops iteration once that many items have
+// been read, and MaxPages stops after that many pages. Zero means no limit.
+type ListOptions struct {
+	PageSize int
+	Limit    int
+	MaxPages int
+}
+
+func (o *ListOptions) apply(query url.Values) url.Values {
+	if query == nil {
+		query = url.Values{}
+	}
+	if o != nil && o.PageSize > 0 {
+		query.Set("per_page", strconv.Itoa(o.PageSize))
+	}
+	return query
+}
+
+// Iterator yields the items of a paginated collection one at a time,
+// fetching further pages as needed.
+type Iterator[T any] struct {
+	pager *api.Pager
+	opts  ListOptions
+	resp  *Response
+	page  []json.RawMessage
+	pages int
+	count int
+	item  T
+	raw   json.RawMessage
+	err   error
+}
+
+func newIterator[T any](c *Client, path string, query url.Values, opts *ListOptions) *Iterator[T] {
+	it := &Iterator[T]{}
+	if opts != nil {
+		it.opts = *opts
+	}
+	it.pager = c.transport.Pager(path, it.opts.apply(query))
+	return it
+}
+
+func errIterator[T any](err error) *Iterator[T] {
+	return &Iterator[T]{err: err}
+}
+
+// Next advances to the next item. It returns false when the collection is
+// exhausted, a limit is reached, or an error occurs; check Err afterwards.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil || it.pager == nil {
+		return false
+	}
+	if it.opts.Limit > 0 && it.count >= it.opts.Limit {
+		return false
+	}
+	for len(it.page) == 0 {
+		if it.pager.Done() || (it.opts.MaxPages > 0 && it.pages >= it.opts.MaxPages) {
+			return false
+		}
+		resp, err := it.pager.Next(ctx)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.resp = resp
+		it.pages++
+		var page []json.RawMessage
+		if err := decode(resp, &page); err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page
+	}
+	raw := it.page[0]
+	it.page = it.page[1:]
+	var item T
+	if err := json.Unmarshal(raw, &item); err != nil {
+		it.err = err
+		return false
+	}
+	it.item = item
+	it.raw = raw
+	it.count++
+	return true
+}
+
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Raw returns the undecoded JSON of the current item.
+func (it *Iterator[T]) Raw() json.RawMessage {
+	return it.raw
+}
+
+// Response returns the response of the most recently fetched page.
+func (it *Iterator[T]) Response() *Response {
+	return it.resp
+}
+
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All drains the iterator into a slice.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	var items []T
+	for it.Next(ctx) {
+		items = append(items, it.Item())
+	}
+	return items, it.Err()
+}
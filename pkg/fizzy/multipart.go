@@ -0,0 +1,58 @@
+package fizzy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func multipartBody(rootKey string, fields map[string][]string, fileField, filePath string) (io.Reader, string, error) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	for key, values := range fields {
+		fieldName := buildFieldName(rootKey, key)
+		for _, value := range values {
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			if err := writer.WriteField(fieldName, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if fileField != "" && strings.TrimSpace(filePath) != "" {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+
+		part, err := writer.CreateFormFile(fmt.Sprintf("%s[%s]", rootKey, fileField), filepath.Base(filePath))
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := io.Copy(part, file); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf, writer.FormDataContentType(), nil
+}
+
+func buildFieldName(rootKey, key string) string {
+	suffix := ""
+	if strings.HasSuffix(key, "[]") {
+		key = strings.TrimSuffix(key, "[]")
+		suffix = "[]"
+	}
+	if rootKey == "" {
+		return key + suffix
+	}
+	return fmt.Sprintf("%s[%s]%s", rootKey, key, suffix)
+}
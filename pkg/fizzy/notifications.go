@@ -0,0 +1,77 @@
+package fizzy
+
+import (
+	"context"
+	"net/url"
+
+	"fizzy-cli/internal/api"
+)
+
+type NotificationsService struct {
+	client *Client
+}
+
+type NotificationListOptions struct {
+	ListOptions
+	Unread bool
+}
+
+func (o *NotificationListOptions) values() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+	if o.Unread {
+		query.Set("unread", "true")
+	}
+	return o.ListOptions.apply(query)
+}
+
+func (s *NotificationsService) List(ctx context.Context, opts *NotificationListOptions) ([]Notification, *Response, error) {
+	path, err := s.client.accountPath("/notifications")
+	if err != nil {
+		return nil, nil, err
+	}
+	var notifications []Notification
+	resp, err := s.client.get(ctx, path, opts.values(), &notifications)
+	if err != nil {
+		return nil, resp, err
+	}
+	return notifications, resp, nil
+}
+
+func (s *NotificationsService) Iter(opts *NotificationListOptions) *Iterator[Notification] {
+	path, err := s.client.accountPath("/notifications")
+	if err != nil {
+		return errIterator[Notification](err)
+	}
+	var list *ListOptions
+	if opts != nil {
+		list = &opts.ListOptions
+	}
+	return newIterator[Notification](s.client, path, opts.values(), list)
+}
+
+func (s *NotificationsService) Read(ctx context.Context, id string) (*Response, error) {
+	return s.reading(ctx, "POST", id)
+}
+
+func (s *NotificationsService) Unread(ctx context.Context, id string) (*Response, error) {
+	return s.reading(ctx, "DELETE", id)
+}
+
+func (s *NotificationsService) ReadAll(ctx context.Context) (*Response, error) {
+	path, err := s.client.accountPath("/notifications/bulk_reading")
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(api.WithIdempotent(ctx), "POST", path, nil)
+}
+
+func (s *NotificationsService) reading(ctx context.Context, method, id string) (*Response, error) {
+	path, err := s.client.accountPath("/notifications/" + escape(id) + "/reading")
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(api.WithIdempotent(ctx), method, path, nil)
+}
@@ -0,0 +1,61 @@
+package fizzy
+
+import "context"
+
+type StepsService struct {
+	client *Client
+}
+
+// StepRequest is used to create and update card steps. A nil Completed
+// leaves the state unchanged on update.
+type StepRequest struct {
+	Content   string `json:"content,omitempty"`
+	Completed *bool  `json:"completed,omitempty"`
+}
+
+// List returns the steps of a card. The API has no step index, so they are
+// read from the card itself.
+func (s *StepsService) List(ctx context.Context, cardNumber int) ([]Step, *Response, error) {
+	card, resp, err := s.client.Cards.Get(ctx, cardNumber)
+	if err != nil {
+		return nil, resp, err
+	}
+	return card.Steps, resp, nil
+}
+
+func (s *StepsService) Get(ctx context.Context, cardNumber int, id string) (*Step, *Response, error) {
+	path, err := cardPath(s.client, cardNumber, "/steps/"+escape(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	var step Step
+	resp, err := s.client.get(ctx, path, nil, &step)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &step, resp, nil
+}
+
+func (s *StepsService) Create(ctx context.Context, cardNumber int, req *StepRequest) (*Response, error) {
+	path, err := cardPath(s.client, cardNumber, "/steps")
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "POST", path, map[string]any{"step": req})
+}
+
+func (s *StepsService) Update(ctx context.Context, cardNumber int, id string, req *StepRequest) (*Response, error) {
+	path, err := cardPath(s.client, cardNumber, "/steps/"+escape(id))
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "PUT", path, map[string]any{"step": req})
+}
+
+func (s *StepsService) Delete(ctx context.Context, cardNumber int, id string) (*Response, error) {
+	path, err := cardPath(s.client, cardNumber, "/steps/"+escape(id))
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "DELETE", path, nil)
+}
@@ -0,0 +1,28 @@
+package fizzy
+
+import "context"
+
+type TagsService struct {
+	client *Client
+}
+
+func (s *TagsService) List(ctx context.Context) ([]Tag, *Response, error) {
+	path, err := s.client.accountPath("/tags")
+	if err != nil {
+		return nil, nil, err
+	}
+	var tags []Tag
+	resp, err := s.client.get(ctx, path, nil, &tags)
+	if err != nil {
+		return nil, resp, err
+	}
+	return tags, resp, nil
+}
+
+func (s *TagsService) Iter(opts *ListOptions) *Iterator[Tag] {
+	path, err := s.client.accountPath("/tags")
+	if err != nil {
+		return errIterator[Tag](err)
+	}
+	return newIterator[Tag](s.client, path, nil, opts)
+}
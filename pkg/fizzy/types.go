@@ -0,0 +1,93 @@
+package fizzy
+
+type Board struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	AllAccess bool   `json:"all_access"`
+	CreatedAt string `json:"created_at"`
+	Creator   User   `json:"creator"`
+	URL       string `json:"url"`
+}
+
+type Card struct {
+	ID           string   `json:"id"`
+	Number       int      `json:"number"`
+	Title        string   `json:"title"`
+	Status       string   `json:"status"`
+	Description  string   `json:"description"`
+	ImageURL     string   `json:"image_url"`
+	Tags         []string `json:"tags"`
+	Golden       bool     `json:"golden"`
+	Closed       bool     `json:"closed"`
+	LastActiveAt string   `json:"last_active_at"`
+	CreatedAt    string   `json:"created_at"`
+	URL          string   `json:"url"`
+	Board        Board    `json:"board"`
+	Column       *Column  `json:"column,omitempty"`
+	Creator      User     `json:"creator"`
+	Assignees    []User   `json:"assignees"`
+	Steps        []Step   `json:"steps"`
+}
+
+type Step struct {
+	ID        string `json:"id"`
+	Content   string `json:"content"`
+	Completed bool   `json:"completed"`
+}
+
+type Comment struct {
+	ID        string      `json:"id"`
+	CreatedAt string      `json:"created_at"`
+	Body      CommentBody `json:"body"`
+	Creator   User        `json:"creator"`
+}
+
+type CommentBody struct {
+	Plain string `json:"plain_text"`
+	HTML  string `json:"html"`
+}
+
+type Tag struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type Column struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	CreatedAt string `json:"created_at"`
+}
+
+type User struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+	Email string `json:"email_address"`
+}
+
+type Notification struct {
+	ID        string           `json:"id"`
+	Read      bool             `json:"read"`
+	CreatedAt string           `json:"created_at"`
+	Title     string           `json:"title"`
+	Body      string           `json:"body"`
+	Card      NotificationCard `json:"card"`
+}
+
+type NotificationCard struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type Identity struct {
+	Accounts []Account `json:"accounts"`
+}
+
+type Account struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	User User   `json:"user"`
+}
@@ -0,0 +1,67 @@
+package fizzy
+
+import "context"
+
+type UsersService struct {
+	client *Client
+}
+
+// UserUpdateRequest updates a user's profile. AvatarPath, when set, uploads
+// the file as the avatar using a multipart request.
+type UserUpdateRequest struct {
+	Name       string `json:"name"`
+	AvatarPath string `json:"-"`
+}
+
+func (s *UsersService) List(ctx context.Context) ([]User, *Response, error) {
+	path, err := s.client.accountPath("/users")
+	if err != nil {
+		return nil, nil, err
+	}
+	var users []User
+	resp, err := s.client.get(ctx, path, nil, &users)
+	if err != nil {
+		return nil, resp, err
+	}
+	return users, resp, nil
+}
+
+func (s *UsersService) Iter(opts *ListOptions) *Iterator[User] {
+	path, err := s.client.accountPath("/users")
+	if err != nil {
+		return errIterator[User](err)
+	}
+	return newIterator[User](s.client, path, nil, opts)
+}
+
+func (s *UsersService) Get(ctx context.Context, id string) (*User, *Response, error) {
+	path, err := s.client.accountPath("/users/" + escape(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	var user User
+	resp, err := s.client.get(ctx, path, nil, &user)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &user, resp, nil
+}
+
+func (s *UsersService) Update(ctx context.Context, id string, req *UserUpdateRequest) (*Response, error) {
+	path, err := s.client.accountPath("/users/" + escape(id))
+	if err != nil {
+		return nil, err
+	}
+	if req.AvatarPath != "" {
+		return s.client.sendMultipart(ctx, "PUT", path, "user", map[string][]string{"name": {req.Name}}, "avatar", req.AvatarPath)
+	}
+	return s.client.send(ctx, "PUT", path, map[string]any{"user": req})
+}
+
+func (s *UsersService) Deactivate(ctx context.Context, id string) (*Response, error) {
+	path, err := s.client.accountPath("/users/" + escape(id))
+	if err != nil {
+		return nil, err
+	}
+	return s.client.send(ctx, "DELETE", path, nil)
+}